@@ -3,8 +3,12 @@ package baseapp
 import (
 	"fmt"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
 	abci "github.com/tendermint/abci/types"
@@ -24,6 +28,12 @@ import (
 // and to avoid affecting the Merkle root.
 var dbHeaderKey = []byte("header")
 
+// Key to store the consensus params in the main store.
+var mainConsensusParamsKey = []byte("consensus_params")
+
+// Maximum size of the chunks of state snapshots.
+const snapshotChunkSize = 10 << 20
+
 // Enum mode for app.runTx
 type runTxMode uint8
 
@@ -39,24 +49,31 @@ const (
 // The ABCI application
 type BaseApp struct {
 	// initialized on creation
-	Logger     log.Logger
-	name       string               // application name from abci.Info
-	cdc        *wire.Codec          // Amino codec
-	db         dbm.DB               // common DB backend
-	cms        sdk.CommitMultiStore // Main (uncached) state
-	router     Router               // handle any kind of message
-	codespacer *sdk.Codespacer      // handle module codespacing
+	Logger      log.Logger
+	name        string               // application name from abci.Info
+	cdc         *wire.Codec          // Amino codec
+	db          dbm.DB               // common DB backend
+	cms         sdk.CommitMultiStore // Main (uncached) state
+	router      Router               // handle any kind of message
+	queryRouter QueryRouter          // router for redirecting custom query calls
+	codespacer  *sdk.Codespacer      // handle module codespacing
+	baseKey     sdk.StoreKey         // main KVStore in cms, set on load
 
 	// must be set
 	txDecoder   sdk.TxDecoder   // unmarshal []byte into sdk.Tx
 	anteHandler sdk.AnteHandler // ante handler for fee and auth
 
 	// may be nil
+	minimumGasPrices sdk.GasPrices    // node-local minimum gas prices for CheckTx
 	initChainer      sdk.InitChainer  // initialize state with validators and state blob
 	beginBlocker     sdk.BeginBlocker // logic to run before any txs
 	endBlocker       sdk.EndBlocker   // logic to run after all txs, and to determine valset changes
 	addrPeerFilter   sdk.PeerFilter   // filter peers by address and port
 	pubkeyPeerFilter sdk.PeerFilter   // filter peers by public key
+	snapshotDir      string           // directory of state snapshots
+	snapshotInterval int64            // snapshot the state every snapshotInterval heights, never if 0
+	snapshotRunning  int32            // 1 while a snapshot is written in the background
+	snapshotWG       sync.WaitGroup   // the background snapshots
 
 	//--------------------
 	// Volatile
@@ -65,6 +82,7 @@ type BaseApp struct {
 	// See methods setCheckState and setDeliverState.
 	// .valUpdates accumulate in DeliverTx and are reset in BeginBlock.
 	// QUESTION: should we put valUpdates in the deliverState.ctx?
+	consensusParams  *abci.ConsensusParams   // set in InitChain and reloaded from the main store
 	checkState       *state                  // for CheckTx
 	deliverState     *state                  // for DeliverTx
 	valUpdates       []abci.Validator        // cached validator changes from DeliverTx
@@ -77,14 +95,15 @@ var _ abci.Application = (*BaseApp)(nil)
 // NOTE: The db is used to store the version number for now.
 func NewBaseApp(name string, cdc *wire.Codec, logger log.Logger, db dbm.DB) *BaseApp {
 	app := &BaseApp{
-		Logger:     logger,
-		name:       name,
-		cdc:        cdc,
-		db:         db,
-		cms:        store.NewCommitMultiStore(db),
-		router:     NewRouter(),
-		codespacer: sdk.NewCodespacer(),
-		txDecoder:  defaultTxDecoder(cdc),
+		Logger:      logger,
+		name:        name,
+		cdc:         cdc,
+		db:          db,
+		cms:         store.NewCommitMultiStore(db),
+		router:      NewRouter(),
+		queryRouter: NewQueryRouter(),
+		codespacer:  sdk.NewCodespacer(),
+		txDecoder:   defaultTxDecoder(cdc),
 	}
 	// Register the undefined & root codespaces, which should not be used by any modules
 	app.codespacer.RegisterOrPanic(sdk.CodespaceUndefined)
@@ -109,14 +128,43 @@ func (app *BaseApp) MountStoresIAVL(keys ...*sdk.KVStoreKey) {
 	}
 }
 
+// Mount transient stores, reset on every Commit, to the provided keys in the BaseApp multistore
+func (app *BaseApp) MountStoresTransient(keys ...*sdk.KVStoreKey) {
+	for _, key := range keys {
+		app.MountStore(key, sdk.StoreTypeTransient)
+	}
+}
+
+// Mount a nested multistore to the provided key in the BaseApp multistore,
+// with IAVL stores mounted to the nested keys. Nested stores are accessed
+// with ctx.NestedKVStore.
+func (app *BaseApp) MountMultiStore(key sdk.StoreKey, nestedKeys ...*sdk.KVStoreKey) {
+	app.MountStore(key, sdk.StoreTypeMulti)
+	nested := app.cms.GetCommitStore(key).(sdk.CommitMultiStore)
+	for _, nestedKey := range nestedKeys {
+		nested.MountStoreWithDB(nestedKey, sdk.StoreTypeIAVL, nil)
+	}
+}
+
 // Mount a store to the provided key in the BaseApp multistore, using a specified DB
 func (app *BaseApp) MountStoreWithDB(key sdk.StoreKey, typ sdk.StoreType, db dbm.DB) {
 	app.cms.MountStoreWithDB(key, typ, db)
 }
 
-// Mount a store to the provided key in the BaseApp multistore, using the default DB
+// Mount a store to the provided key in the BaseApp multistore, using the default DB,
+// or the DB of the store if the app DB keeps each store in a DB of its own
 func (app *BaseApp) MountStore(key sdk.StoreKey, typ sdk.StoreType) {
-	app.cms.MountStoreWithDB(key, typ, nil)
+	var db dbm.DB
+	if dbs, ok := app.db.(storeDBs); ok && typ != sdk.StoreTypeTransient {
+		db = dbs.StoreDB(key.Name())
+	}
+	app.cms.MountStoreWithDB(key, typ, db)
+}
+
+// implemented by app DBs which keep each store in a DB of its own, eg. those
+// opened by server.ConstructAppCreator with a DB per store
+type storeDBs interface {
+	StoreDB(name string) dbm.DB
 }
 
 // Set the txDecoder function
@@ -124,25 +172,45 @@ func (app *BaseApp) SetTxDecoder(txDecoder sdk.TxDecoder) {
 	app.txDecoder = txDecoder
 }
 
-// default custom logic for transaction decoding
+// default custom logic for transaction decoding: StdTxs in any of the
+// encodings of auth.DefaultTxCodecRegistry, selected by the version byte
 func defaultTxDecoder(cdc *wire.Codec) sdk.TxDecoder {
-	return func(txBytes []byte) (sdk.Tx, sdk.Error) {
-		var tx = auth.StdTx{}
-
-		if len(txBytes) == 0 {
-			return nil, sdk.ErrTxDecode("txBytes are empty")
-		}
+	return auth.DefaultTxCodecRegistry(cdc).TxDecoder()
+}
 
-		// StdTx.Msg is an interface. The concrete types
-		// are registered by MakeTxCodec
-		err := cdc.UnmarshalBinary(txBytes, &tx)
-		if err != nil {
-			return nil, sdk.ErrTxDecode("").Trace(err.Error())
-		}
-		return tx, nil
+// Set the node-local minimum gas prices, which txs must pay to pass CheckTx
+func (app *BaseApp) SetMinimumGasPrices(prices sdk.GasPrices) {
+	app.minimumGasPrices = prices
+	if app.checkState != nil {
+		app.checkState.ctx = app.checkState.ctx.WithMinimumGasPrices(prices)
 	}
 }
 
+// Set the pruning options of the multistore, which determine the heights
+// whose state is kept
+func (app *BaseApp) SetPruning(opts sdk.PruningOptions) {
+	app.cms.SetPruning(opts)
+}
+
+// Set the gas schedule of the store of key, charged by ctx.KVStore(key)
+func (app *BaseApp) SetGasConfig(key sdk.StoreKey, config sdk.GasConfig) {
+	app.cms.SetGasConfig(key, config)
+}
+
+// Register listeners of the writes to the store of key.  They are notified
+// of the writes of InitChain, BeginBlock, each DeliverTx and EndBlock, in
+// order, then of the Commit of the block.
+func (app *BaseApp) AddListeners(key sdk.StoreKey, listeners ...sdk.WriteListener) {
+	app.cms.AddListeners(key, listeners)
+}
+
+// Snapshot the state to dir on every height which is a multiple of interval.
+// Snapshots are disabled if interval is 0.
+func (app *BaseApp) SetSnapshotOptions(dir string, interval int64) {
+	app.snapshotDir = dir
+	app.snapshotInterval = interval
+}
+
 // nolint - Set functions
 func (app *BaseApp) SetInitChainer(initChainer sdk.InitChainer) {
 	app.initChainer = initChainer
@@ -162,7 +230,8 @@ func (app *BaseApp) SetAddrPeerFilter(pf sdk.PeerFilter) {
 func (app *BaseApp) SetPubKeyPeerFilter(pf sdk.PeerFilter) {
 	app.pubkeyPeerFilter = pf
 }
-func (app *BaseApp) Router() Router { return app.router }
+func (app *BaseApp) Router() Router           { return app.router }
+func (app *BaseApp) QueryRouter() QueryRouter { return app.queryRouter }
 
 // load latest application version
 func (app *BaseApp) LoadLatestVersion(mainKey sdk.StoreKey) error {
@@ -176,6 +245,34 @@ func (app *BaseApp) LoadVersion(version int64, mainKey sdk.StoreKey) error {
 	return app.initFromStore(mainKey)
 }
 
+// restore the state from the snapshot of the given height in the snapshot
+// directory, if it matches the trusted app hash of the height. The app must
+// have loaded its latest version, and be empty.
+func (app *BaseApp) RestoreSnapshot(height int64, appHash []byte) error {
+	if app.baseKey == nil {
+		return errors.New("BaseApp must be loaded before restoring a snapshot")
+	}
+	err := app.cms.Restore(app.snapshotDir, height, appHash)
+	if err != nil {
+		return err
+	}
+	return app.initFromStore(app.baseKey)
+}
+
+// Roll the state back to a previously committed height, deleting the newer
+// heights
+func (app *BaseApp) Rollback(height int64) error {
+	if app.baseKey == nil {
+		return errors.New("BaseApp must be loaded before rolling back")
+	}
+	app.snapshotWG.Wait()
+	err := app.cms.Rollback(height)
+	if err != nil {
+		return err
+	}
+	return app.initFromStore(app.baseKey)
+}
+
 // the last CommitID of the multistore
 func (app *BaseApp) LastCommitID() sdk.CommitID {
 	return app.cms.LastCommitID()
@@ -195,6 +292,18 @@ func (app *BaseApp) initFromStore(mainKey sdk.StoreKey) error {
 	if main == nil {
 		return errors.New("BaseApp expects MultiStore with 'main' KVStore")
 	}
+	app.baseKey = mainKey
+
+	// load the consensus params stored in InitChain
+	consensusParamsBytes := main.Get(mainConsensusParamsKey)
+	if consensusParamsBytes != nil {
+		var consensusParams abci.ConsensusParams
+		err := proto.Unmarshal(consensusParamsBytes, &consensusParams)
+		if err != nil {
+			return errors.Wrap(err, "Failed to parse consensus params")
+		}
+		app.consensusParams = &consensusParams
+	}
 
 	// XXX: Do we really need the header? What does it have that we want
 	// here that's not already in the CommitID ? If an app wants to have it,
@@ -253,12 +362,12 @@ func (app *BaseApp) setCheckState(header abci.Header) {
 	ms := app.cms.CacheMultiStore()
 	app.checkState = &state{
 		ms:  ms,
-		ctx: sdk.NewContext(ms, header, true, nil, app.Logger),
+		ctx: sdk.NewContext(ms, header, true, nil, app.Logger).WithMinimumGasPrices(app.minimumGasPrices),
 	}
 }
 
 func (app *BaseApp) setDeliverState(header abci.Header) {
-	ms := app.cms.CacheMultiStore()
+	ms := app.cms.CacheMultiStoreWithListeners()
 	app.deliverState = &state{
 		ms:  ms,
 		ctx: sdk.NewContext(ms, header, false, nil, app.Logger),
@@ -289,12 +398,22 @@ func (app *BaseApp) SetOption(req abci.RequestSetOption) (res abci.ResponseSetOp
 // Implements ABCI
 // InitChain runs the initialization logic directly on the CommitMultiStore and commits it.
 func (app *BaseApp) InitChain(req abci.RequestInitChain) (res abci.ResponseInitChain) {
-	if app.initChainer == nil {
+	if app.initChainer == nil && req.ConsensusParams == nil {
 		return
 	}
 
-	// Initialize the deliver state and run initChain
+	// Initialize the deliver state
 	app.setDeliverState(abci.Header{})
+
+	// Store the consensus params, so they are committed with the genesis state
+	if req.ConsensusParams != nil {
+		app.setConsensusParams(app.deliverState.ctx, req.ConsensusParams)
+	}
+
+	// Run initChain
+	if app.initChainer == nil {
+		return
+	}
 	app.initChainer(app.deliverState.ctx, req) // no error
 
 	// NOTE: we don't commit, but BeginBlock for block 1
@@ -302,6 +421,29 @@ func (app *BaseApp) InitChain(req abci.RequestInitChain) (res abci.ResponseInitC
 	return
 }
 
+// setConsensusParams sets the consensus params, and writes them to the main
+// store if it is loaded
+func (app *BaseApp) setConsensusParams(ctx sdk.Context, consensusParams *abci.ConsensusParams) {
+	app.consensusParams = consensusParams
+	if app.baseKey == nil {
+		return
+	}
+	consensusParamsBytes, err := proto.Marshal(consensusParams)
+	if err != nil {
+		panic(err)
+	}
+	ctx.KVStore(app.baseKey).Set(mainConsensusParamsKey, consensusParamsBytes)
+}
+
+// maximumBlockGas returns the block gas limit from the consensus params,
+// or 0 if blocks have no gas limit
+func (app *BaseApp) maximumBlockGas() int64 {
+	if app.consensusParams == nil || app.consensusParams.BlockSize == nil {
+		return 0
+	}
+	return app.consensusParams.BlockSize.MaxGas
+}
+
 // Filter peers by address / port
 func (app *BaseApp) FilterPeerByAddrPort(info string) abci.ResponseQuery {
 	if app.addrPeerFilter != nil {
@@ -357,6 +499,10 @@ func (app *BaseApp) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 		req.Path = "/" + strings.Join(path[1:], "/")
 		return queryable.Query(req)
 	}
+	// "/custom" prefix for queries handled by module queriers
+	if len(path) >= 1 && path[0] == "custom" {
+		return app.handleQueryCustom(path, req)
+	}
 	// "/p2p" prefix for p2p queries
 	if len(path) >= 4 && path[0] == "p2p" {
 		if path[1] == "filter" {
@@ -372,6 +518,59 @@ func (app *BaseApp) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 	return sdk.ErrUnknownRequest(msg).QueryResult()
 }
 
+// handleQueryCustom routes "/custom/<route>/<path...>" to the Querier
+// registered for <route>, passing it the remaining path. The querier runs
+// on a cache-wrapped context at the requested height, so it cannot
+// modify committed state.
+func (app *BaseApp) handleQueryCustom(path []string, req abci.RequestQuery) (res abci.ResponseQuery) {
+	if len(path) < 2 || path[1] == "" {
+		return sdk.ErrUnknownRequest("no route for custom query specified").QueryResult()
+	}
+	querier := app.queryRouter.Route(path[1])
+	if querier == nil {
+		msg := fmt.Sprintf("no custom querier found for route %s", path[1])
+		return sdk.ErrUnknownRequest(msg).QueryResult()
+	}
+
+	// Default to the latest committed height.
+	lastHeight := app.LastBlockHeight()
+	height := req.Height
+	if height == 0 {
+		height = lastHeight
+	}
+	if height < 0 || height > lastHeight {
+		msg := fmt.Sprintf("invalid query height %d, latest height is %d", height, lastHeight)
+		return sdk.ErrUnknownRequest(msg).QueryResult()
+	}
+
+	var header abci.Header
+	var cacheMS sdk.CacheMultiStore
+	if height == lastHeight {
+		header = app.checkState.ctx.BlockHeader()
+		cacheMS = app.cms.CacheMultiStore()
+	} else {
+		// NOTE: only the height of the historical header is known
+		header = abci.Header{ChainID: app.checkState.ctx.ChainID(), Height: height}
+		ms, err := app.cms.CacheMultiStoreWithVersion(height)
+		if err != nil {
+			msg := fmt.Sprintf("failed to load state at height %d: %v", height, err)
+			return sdk.ErrUnknownRequest(msg).QueryResult()
+		}
+		cacheMS = ms
+	}
+	ctx := sdk.NewContext(cacheMS, header, true, nil, app.Logger)
+
+	resBytes, err := querier(ctx, path[2:], req)
+	if err != nil {
+		return err.QueryResult()
+	}
+	return abci.ResponseQuery{
+		Code:   uint32(sdk.ABCICodeOK),
+		Value:  resBytes,
+		Height: height,
+	}
+}
+
 // Implements ABCI
 func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeginBlock) {
 	// Initialize the DeliverTx state.
@@ -380,7 +579,23 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 	// if this is a test and InitChain was never called.
 	if app.deliverState == nil {
 		app.setDeliverState(req.Header)
+	} else {
+		// In the first block, set the header on the state from InitChain
+		app.deliverState.ctx = app.deliverState.ctx.
+			WithBlockHeader(req.Header).
+			WithBlockHeight(req.Header.Height).
+			WithChainID(req.Header.ChainID)
+	}
+
+	// Meter the gas of all the txs in the block
+	var blockGasMeter sdk.GasMeter
+	if maxGas := app.maximumBlockGas(); maxGas > 0 {
+		blockGasMeter = sdk.NewGasMeter(maxGas)
+	} else {
+		blockGasMeter = sdk.NewInfiniteGasMeter()
 	}
+	app.deliverState.ctx = app.deliverState.ctx.WithBlockGasMeter(blockGasMeter)
+
 	app.valUpdates = nil
 	if app.beginBlocker != nil {
 		res = app.beginBlocker(app.deliverState.ctx, req)
@@ -401,6 +616,11 @@ func (app *BaseApp) CheckTx(txBytes []byte) (res abci.ResponseCheckTx) {
 		result = app.runTx(runTxModeCheck, txBytes, tx)
 	}
 
+	// Expose the priority of accepted txs to the mempool
+	if result.IsOK() {
+		result.Tags = result.Tags.AppendTag("priority", []byte(strconv.FormatInt(result.Priority, 10)))
+	}
+
 	return abci.ResponseCheckTx{
 		Code:      uint32(result.Code),
 		Data:      result.Data,
@@ -463,6 +683,26 @@ func (app *BaseApp) Deliver(tx sdk.Tx) (result sdk.Result) {
 // txBytes may be nil in some cases, eg. in tests.
 // Also, in the future we may support "internal" transactions.
 func (app *BaseApp) runTx(mode runTxMode, txBytes []byte, tx sdk.Tx) (result sdk.Result) {
+	// Get the context, with a fresh gas meter for the tx
+	var ctx sdk.Context
+	if mode == runTxModeCheck || mode == runTxModeSimulate {
+		ctx = app.checkState.ctx.WithTxBytes(txBytes)
+	} else {
+		ctx = app.deliverState.ctx.WithTxBytes(txBytes)
+		ctx = ctx.WithSigningValidators(app.signedValidators)
+	}
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+
+	// Simulate a DeliverTx for gas calculation
+	if mode == runTxModeSimulate {
+		ctx = ctx.WithIsCheckTx(false)
+	}
+
+	// Reject txs once the block gas limit is exhausted
+	if mode == runTxModeDeliver && ctx.BlockGasMeter().IsOutOfGas() {
+		return sdk.ErrOutOfGas("no block gas left to run tx").Result()
+	}
+
 	// Handle any panics.
 	defer func() {
 		if r := recover(); r != nil {
@@ -474,52 +714,45 @@ func (app *BaseApp) runTx(mode runTxMode, txBytes []byte, tx sdk.Tx) (result sdk
 				log := fmt.Sprintf("Recovered: %v\nstack:\n%v", r, string(debug.Stack()))
 				result = sdk.ErrInternal(log).Result()
 			}
+			// The gas consumed until the panic is still charged to the block
+			result.GasUsed = ctx.GasMeter().GasConsumed()
+			if mode == runTxModeDeliver {
+				consumeBlockGas(ctx, result.GasUsed)
+			}
 		}
 	}()
 
-	// Get the Msg.
-	var msg = tx.GetMsg()
-	if msg == nil {
-		return sdk.ErrInternal("Tx.GetMsg() returned nil").Result()
+	// Get the Msgs.
+	var msgs = tx.GetMsgs()
+	if len(msgs) == 0 {
+		return sdk.ErrInternal("Tx.GetMsgs() returned no messages").Result()
 	}
 
-	// Validate the Msg.
-	err := msg.ValidateBasic()
+	// Validate the Msgs.
+	err := validateBasicTxMsgs(msgs)
 	if err != nil {
-		err = err.WithDefaultCodespace(sdk.CodespaceRoot)
 		return err.Result()
 	}
 
-	// Get the context
-	var ctx sdk.Context
-	if mode == runTxModeCheck || mode == runTxModeSimulate {
-		ctx = app.checkState.ctx.WithTxBytes(txBytes)
-	} else {
-		ctx = app.deliverState.ctx.WithTxBytes(txBytes)
-		ctx = ctx.WithSigningValidators(app.signedValidators)
-	}
-
-	// Simulate a DeliverTx for gas calculation
-	if mode == runTxModeSimulate {
-		ctx = ctx.WithIsCheckTx(false)
-	}
-
 	// Run the ante handler.
+	var anteResult sdk.Result
 	if app.anteHandler != nil {
-		newCtx, result, abort := app.anteHandler(ctx, tx)
+		newCtx, res, abort := app.anteHandler(ctx, tx)
 		if abort {
-			return result
+			// The gas consumed by the ante handler is still charged to the block
+			if !newCtx.IsZero() {
+				ctx = newCtx
+			}
+			res.GasUsed = ctx.GasMeter().GasConsumed()
+			if mode == runTxModeDeliver {
+				consumeBlockGas(ctx, res.GasUsed)
+			}
+			return res
 		}
 		if !newCtx.IsZero() {
 			ctx = newCtx
 		}
-	}
-
-	// Match route.
-	msgType := msg.Type()
-	handler := app.router.Route(msgType)
-	if handler == nil {
-		return sdk.ErrUnknownRequest("Unrecognized Msg type: " + msgType).Result()
+		anteResult = res
 	}
 
 	// Get the correct cache
@@ -534,12 +767,24 @@ func (app *BaseApp) runTx(mode runTxMode, txBytes []byte, tx sdk.Tx) (result sdk
 		ctx = ctx.WithMultiStore(msCache)
 	}
 
-	result = handler(ctx, msg)
+	result = app.runMsgs(ctx, msgs)
 
-	// Set gas utilized
+	// Set gas wanted and utilized, and the fee and priority from the ante handler
+	result.GasWanted = anteResult.GasWanted
 	result.GasUsed = ctx.GasMeter().GasConsumed()
+	result.FeeAmount = anteResult.FeeAmount
+	result.FeeDenom = anteResult.FeeDenom
+	result.Priority = anteResult.Priority
+	result.Tags = append(anteResult.Tags, result.Tags...)
 
-	// If not a simulated run and result was successful, write to app.checkState.ms or app.deliverState.ms
+	// Charge the gas to the block; a tx going over the block gas limit fails
+	if mode == runTxModeDeliver && !consumeBlockGas(ctx, result.GasUsed) {
+		result = sdk.ErrOutOfGas("block gas limit exceeded").Result()
+		result.GasWanted = anteResult.GasWanted
+		result.GasUsed = ctx.GasMeter().GasConsumed()
+	}
+
+	// If not a simulated run and all msgs were successful, write to app.checkState.ms or app.deliverState.ms
 	if mode != runTxModeSimulate && result.IsOK() {
 		msCache.Write()
 	}
@@ -547,6 +792,69 @@ func (app *BaseApp) runTx(mode runTxMode, txBytes []byte, tx sdk.Tx) (result sdk
 	return result
 }
 
+// consumeBlockGas charges gasUsed to the block gas meter of ctx, without
+// going over its limit. It returns false if gasUsed exceeded the gas left.
+func consumeBlockGas(ctx sdk.Context, gasUsed int64) bool {
+	meter := ctx.BlockGasMeter()
+	limit := meter.Limit()
+	if limit > 0 && meter.GasConsumed()+gasUsed > limit {
+		meter.ConsumeGas(limit-meter.GasConsumed(), "block gas meter")
+		return false
+	}
+	meter.ConsumeGas(gasUsed, "block gas meter")
+	return true
+}
+
+// Validate each of the Msgs of a tx.
+func validateBasicTxMsgs(msgs []sdk.Msg) sdk.Error {
+	for _, msg := range msgs {
+		err := msg.ValidateBasic()
+		if err != nil {
+			return err.WithDefaultCodespace(sdk.CodespaceRoot)
+		}
+	}
+	return nil
+}
+
+// Route each of the Msgs to its handler in order, accumulating the results.
+// Execution stops at the first failing Msg; the caller is responsible for
+// discarding the cached writes in that case, so the tx is atomic.
+func (app *BaseApp) runMsgs(ctx sdk.Context, msgs []sdk.Msg) (result sdk.Result) {
+	logs := make([]string, 0, len(msgs))
+	var data []byte
+	var tags sdk.Tags
+	var valUpdates []abci.Validator
+
+	for i, msg := range msgs {
+		// Match route.
+		msgType := msg.Type()
+		handler := app.router.Route(msgType)
+		if handler == nil {
+			return sdk.ErrUnknownRequest("Unrecognized Msg type: " + msgType).Result()
+		}
+
+		msgResult := handler(ctx, msg)
+
+		// Stop on the first failed Msg.
+		if !msgResult.IsOK() {
+			msgResult.Log = fmt.Sprintf("Msg %d failed: %s", i, msgResult.Log)
+			return msgResult
+		}
+
+		data = append(data, msgResult.Data...)
+		tags = append(tags, msgResult.Tags...)
+		valUpdates = append(valUpdates, msgResult.ValidatorUpdates...)
+		logs = append(logs, fmt.Sprintf("Msg %d: %s", i, msgResult.Log))
+	}
+
+	return sdk.Result{
+		Data:             data,
+		Log:              strings.Join(logs, "\n"),
+		ValidatorUpdates: valUpdates,
+		Tags:             tags,
+	}
+}
+
 // Implements ABCI
 func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBlock) {
 	if app.endBlocker != nil {
@@ -576,6 +884,10 @@ func (app *BaseApp) Commit() (res abci.ResponseCommit) {
 		"commit", commitID,
 	)
 
+	if app.snapshotInterval > 0 && commitID.Version%app.snapshotInterval == 0 {
+		app.snapshot(commitID.Version)
+	}
+
 	// Reset the Check state to the latest committed
 	// NOTE: safe because Tendermint holds a lock on the mempool for Commit.
 	// Use the header from this latest block.
@@ -588,3 +900,33 @@ func (app *BaseApp) Commit() (res abci.ResponseCommit) {
 		Data: commitID.Hash,
 	}
 }
+
+// Snapshot the state committed at height in the background, so that
+// consensus doesn't wait for it: the snapshot only reads the data of the
+// height, which is held from pruning until the snapshot is written. A
+// height is skipped if the previous snapshot is still running. Failures
+// are only logged, as they don't affect the state.
+func (app *BaseApp) snapshot(height int64) {
+	if !atomic.CompareAndSwapInt32(&app.snapshotRunning, 0, 1) {
+		app.Logger.Info("Snapshot skipped, the previous one is still running", "height", height)
+		return
+	}
+	// hold the height before the next Commit can prune it
+	unhold := app.cms.HoldVersion(height)
+	app.snapshotWG.Add(1)
+	go func() {
+		defer app.snapshotWG.Done()
+		defer atomic.StoreInt32(&app.snapshotRunning, 0)
+		defer unhold()
+
+		manifest, err := app.cms.Snapshot(app.snapshotDir, height, snapshotChunkSize)
+		if err != nil {
+			app.Logger.Error("Snapshot failed", "height", height, "err", err)
+			return
+		}
+		app.Logger.Info("Snapshot written",
+			"commit", manifest.CommitID,
+			"chunks", len(manifest.ChunkHashes),
+		)
+	}()
+}
@@ -3,7 +3,9 @@ package baseapp
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,6 +117,132 @@ func testLoadVersionHelper(t *testing.T, app *BaseApp, expectedHeight int64, exp
 	assert.Equal(t, expectedID, lastID)
 }
 
+func TestSnapshots(t *testing.T) {
+	logger := defaultLogger()
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := t.Name()
+	capKey := sdk.NewKVStoreKey("main")
+	app := NewBaseApp(name, nil, logger, dbm.NewMemDB())
+	app.MountStoresIAVL(capKey)
+	app.SetSnapshotOptions(dir, 2)
+	err = app.LoadLatestVersion(capKey)
+	require.Nil(t, err)
+
+	// snapshots are written on every second height
+	var commitIDs []sdk.CommitID
+	for height := int64(1); height <= 3; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		app.deliverState.ctx.KVStore(capKey).Set([]byte("height"), []byte(fmt.Sprintf("%d", height)))
+		res := app.Commit()
+		commitIDs = append(commitIDs, sdk.CommitID{height, res.Data})
+	}
+	// snapshots are written in the background
+	app.snapshotWG.Wait()
+	_, err = os.Stat(filepath.Join(dir, "1"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "2"))
+	assert.Nil(t, err)
+
+	// a new node restores the snapshot and continues from there
+	restored := NewBaseApp(name, nil, logger, dbm.NewMemDB())
+	restored.MountStoresIAVL(capKey)
+	restored.SetSnapshotOptions(dir, 2)
+	err = restored.LoadLatestVersion(capKey)
+	require.Nil(t, err)
+	err = restored.RestoreSnapshot(2, commitIDs[0].Hash)
+	require.NotNil(t, err)
+	err = restored.RestoreSnapshot(2, commitIDs[1].Hash)
+	require.Nil(t, err)
+	testLoadVersionHelper(t, restored, int64(2), commitIDs[1])
+
+	restored.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 3}})
+	restored.deliverState.ctx.KVStore(capKey).Set([]byte("height"), []byte("3"))
+	restored.Commit()
+	testLoadVersionHelper(t, restored, int64(3), commitIDs[2])
+}
+
+func TestSnapshotsPruneEverything(t *testing.T) {
+	logger := defaultLogger()
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := t.Name()
+	capKey := sdk.NewKVStoreKey("main")
+	app := NewBaseApp(name, nil, logger, dbm.NewMemDB())
+	app.MountStoresIAVL(capKey)
+	app.SetPruning(sdk.PruneEverything)
+	app.SetSnapshotOptions(dir, 1)
+	err = app.LoadLatestVersion(capKey)
+	require.Nil(t, err)
+
+	// blocks keep committing, and pruning, while heights are snapshotted
+	commitIDs := make(map[int64]sdk.CommitID)
+	for height := int64(1); height <= 50; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		for i := 0; i < 20; i++ {
+			key := []byte(fmt.Sprintf("key-%d", i))
+			app.deliverState.ctx.KVStore(capKey).Set(key, []byte(fmt.Sprintf("%d", height)))
+		}
+		res := app.Commit()
+		commitIDs[height] = sdk.CommitID{height, res.Data}
+	}
+	app.snapshotWG.Wait()
+
+	// every snapshot written restores the state of its height
+	snapshots := 0
+	for height, commitID := range commitIDs {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("%d", height))); err != nil {
+			continue
+		}
+		snapshots++
+		restored := NewBaseApp(name, nil, logger, dbm.NewMemDB())
+		restored.MountStoresIAVL(capKey)
+		restored.SetSnapshotOptions(dir, 1)
+		require.Nil(t, restored.LoadLatestVersion(capKey))
+		require.Nil(t, restored.RestoreSnapshot(height, commitID.Hash), "height %d", height)
+		testLoadVersionHelper(t, restored, height, commitID)
+	}
+	assert.True(t, snapshots > 0)
+}
+
+// countingListener counts the writes and commits it is notified of
+type countingListener struct {
+	writes  int
+	commits int
+}
+
+func (cl *countingListener) OnWrite(storeKey sdk.StoreKey, key, value []byte, deleted bool) {
+	cl.writes++
+}
+
+func (cl *countingListener) OnCommit(commitID sdk.CommitID) {
+	cl.commits++
+}
+
+func TestWriteListeners(t *testing.T) {
+	app := newBaseApp(t.Name())
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	listener := &countingListener{}
+	app.AddListeners(capKey, listener)
+	err := app.LoadLatestVersion(capKey)
+	require.Nil(t, err)
+
+	// the writes of the block are notified, those of CheckTx are not
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	app.deliverState.ctx.KVStore(capKey).Set([]byte("key"), []byte("value"))
+	app.checkState.ctx.KVStore(capKey).Set([]byte("check"), []byte("value"))
+	assert.Equal(t, 1, listener.writes)
+	assert.Equal(t, 0, listener.commits)
+	app.Commit()
+	assert.Equal(t, 1, listener.writes)
+	assert.Equal(t, 1, listener.commits)
+}
+
 // Test that the app hash is static
 // TODO: https://github.com/cosmos/cosmos-sdk/issues/520
 /*func TestStaticAppHash(t *testing.T) {
@@ -262,7 +390,7 @@ type testTx struct {
 const msgType2 = "testTx"
 
 func (tx testTx) Type() string                       { return msgType2 }
-func (tx testTx) GetMsg() sdk.Msg                    { return tx }
+func (tx testTx) GetMsgs() []sdk.Msg                 { return []sdk.Msg{tx} }
 func (tx testTx) GetSignBytes() []byte               { return nil }
 func (tx testTx) GetSigners() []sdk.Address          { return nil }
 func (tx testTx) GetSignatures() []auth.StdSignature { return nil }
@@ -383,7 +511,7 @@ func TestSimulateTx(t *testing.T) {
 		app.BeginBlock(abci.RequestBeginBlock{Header: header})
 		result := app.Simulate(tx)
 		require.Equal(t, result.Code, sdk.ABCICodeOK)
-		require.Equal(t, int64(80), result.GasUsed)
+		require.Equal(t, int64(113), result.GasUsed)
 		counter--
 		encoded, err := json.Marshal(tx)
 		require.Nil(t, err)
@@ -396,7 +524,7 @@ func TestSimulateTx(t *testing.T) {
 		var res sdk.Result
 		app.cdc.MustUnmarshalBinary(queryResult.Value, &res)
 		require.Equal(t, sdk.ABCICodeOK, res.Code)
-		require.Equal(t, int64(160), res.GasUsed)
+		require.Equal(t, int64(113), res.GasUsed)
 		app.EndBlock(abci.RequestEndBlock{})
 		app.Commit()
 	}
@@ -423,6 +551,67 @@ func TestRunInvalidTransaction(t *testing.T) {
 	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnknownRequest), err2.Code)
 }
 
+// A mock transaction carrying several msgs.
+type testMultiMsgTx struct {
+	msgs []sdk.Msg
+}
+
+func (tx testMultiMsgTx) GetMsgs() []sdk.Msg { return tx.msgs }
+
+// Test that all msgs of a tx are routed and that a failing
+// msg rolls back the effects of the whole tx.
+func TestRunMultipleMsgs(t *testing.T) {
+	app := newBaseApp(t.Name())
+
+	// make a cap key and mount the store
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	err := app.LoadLatestVersion(capKey) // needed to make stores non-nil
+	assert.Nil(t, err)
+
+	app.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx) (newCtx sdk.Context, res sdk.Result, abort bool) { return })
+	app.Router().AddRoute(msgType, func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		tx := msg.(testUpdatePowerTx)
+		store := ctx.KVStore(capKey)
+		store.Set(tx.Addr, []byte("value"))
+		return sdk.Result{Tags: sdk.NewTags("addr", tx.Addr)}
+	})
+	app.Router().AddRoute(msgType2, func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		if msg.(testTx).positiveNum == 0 {
+			return sdk.ErrUnauthorized("zero").Result()
+		}
+		return sdk.Result{}
+	})
+
+	key1, key2, key3 := []byte("key1"), []byte("key2"), []byte("key3")
+	app.BeginBlock(abci.RequestBeginBlock{})
+
+	// all msgs succeed and tags are merged
+	tx := testMultiMsgTx{[]sdk.Msg{testUpdatePowerTx{Addr: key1}, testTx{1}, testUpdatePowerTx{Addr: key2}}}
+	res := app.Deliver(tx)
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, 2, len(res.Tags))
+
+	// the last msg fails, so the first is rolled back
+	tx = testMultiMsgTx{[]sdk.Msg{testUpdatePowerTx{Addr: key3}, testTx{0}}}
+	res = app.Deliver(tx)
+	require.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnauthorized), res.Code)
+
+	// an invalid msg fails the whole tx before any handler runs
+	tx = testMultiMsgTx{[]sdk.Msg{testUpdatePowerTx{Addr: key3}, testTx{-1}}}
+	res = app.Deliver(tx)
+	require.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeTxDecode), res.Code)
+
+	// a tx without msgs is rejected
+	res = app.Deliver(testMultiMsgTx{})
+	require.False(t, res.IsOK())
+
+	store := app.deliverState.ctx.KVStore(capKey)
+	assert.NotNil(t, store.Get(key1))
+	assert.NotNil(t, store.Get(key2))
+	assert.Nil(t, store.Get(key3))
+}
+
 // Test that transactions exceeding gas limits fail
 func TestTxGasLimits(t *testing.T) {
 	logger := defaultLogger()
@@ -454,6 +643,156 @@ func TestTxGasLimits(t *testing.T) {
 	app.Commit()
 }
 
+// Test that CheckTx exposes the fee and priority set by the ante handler
+func TestCheckTxPriority(t *testing.T) {
+	app := newBaseApp(t.Name())
+
+	// make a cap key and mount the store
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	err := app.LoadLatestVersion(capKey) // needed to make stores non-nil
+	assert.Nil(t, err)
+
+	app.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx) (newCtx sdk.Context, res sdk.Result, abort bool) {
+		res = sdk.Result{
+			FeeAmount: 10,
+			FeeDenom:  "atom",
+			Priority:  7,
+			Tags:      sdk.NewTags("fee_payer", []byte("me")),
+		}
+		return
+	})
+	app.Router().AddRoute(msgType, func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		return sdk.Result{Tags: sdk.NewTags("action", []byte("test"))}
+	})
+	app.SetTxDecoder(func(txBytes []byte) (sdk.Tx, sdk.Error) {
+		var ttx testUpdatePowerTx
+		fromJSON(txBytes, &ttx)
+		return ttx, nil
+	})
+
+	res := app.CheckTx(toJSON(testUpdatePowerTx{}))
+	require.Equal(t, uint32(sdk.ABCICodeOK), res.Code, res.Log)
+	assert.Equal(t, []byte("atom"), res.Fee.Key)
+	assert.Equal(t, int64(10), res.Fee.Value)
+	expected := sdk.NewTags("fee_payer", []byte("me"), "action", []byte("test"), "priority", []byte("7"))
+	assert.Equal(t, expected.ToKVPairs(), res.Tags)
+}
+
+// Test that the txs of a block cannot use more than the block gas limit
+func TestBlockGasLimits(t *testing.T) {
+	logger := defaultLogger()
+	db := dbm.NewMemDB()
+	app := NewBaseApp(t.Name(), nil, logger, db)
+
+	// make a cap key and mount the store
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	err := app.LoadLatestVersion(capKey) // needed to make stores non-nil
+	assert.Nil(t, err)
+
+	app.InitChain(abci.RequestInitChain{
+		ConsensusParams: &abci.ConsensusParams{
+			BlockSize: &abci.BlockSize{MaxGas: 100},
+		},
+	})
+
+	app.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx) (newCtx sdk.Context, res sdk.Result, abort bool) {
+		newCtx = ctx.WithGasMeter(sdk.NewGasMeter(50))
+		res = sdk.Result{GasWanted: 50}
+		return
+	})
+	app.Router().AddRoute(msgType, func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		ctx.GasMeter().ConsumeGas(40, "counter")
+		return sdk.Result{}
+	})
+
+	tx := testUpdatePowerTx{} // doesn't matter
+	header := abci.Header{AppHash: []byte("apphash"), Height: 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+
+	// two txs fit in the block
+	for i := 0; i < 2; i++ {
+		res := app.Deliver(tx)
+		require.True(t, res.IsOK(), res.Log)
+		assert.Equal(t, int64(50), res.GasWanted)
+		assert.Equal(t, int64(40), res.GasUsed)
+	}
+
+	// the third goes over the limit and fails
+	res := app.Deliver(tx)
+	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeOutOfGas), res.Code)
+	assert.Equal(t, int64(40), res.GasUsed)
+	assert.True(t, app.deliverState.ctx.BlockGasMeter().IsOutOfGas())
+
+	// and the block is exhausted
+	res = app.Deliver(tx)
+	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeOutOfGas), res.Code)
+	assert.Equal(t, int64(0), res.GasUsed)
+
+	// CheckTx is not bound by the block
+	res = app.Check(tx)
+	assert.True(t, res.IsOK(), res.Log)
+
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+
+	// the next block starts with a fresh block gas meter
+	header.Height = 2
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	res = app.Deliver(tx)
+	assert.True(t, res.IsOK(), res.Log)
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+
+	// the block gas limit is reloaded from the main store
+	app = NewBaseApp(t.Name(), nil, logger, db)
+	app.MountStoresIAVL(capKey)
+	err = app.LoadLatestVersion(capKey)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), app.maximumBlockGas())
+}
+
+// Test that the gas of txs failing in the ante handler is charged to the block
+func TestBlockGasAnteAbort(t *testing.T) {
+	app := newBaseApp(t.Name())
+
+	// make a cap key and mount the store
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	err := app.LoadLatestVersion(capKey) // needed to make stores non-nil
+	assert.Nil(t, err)
+
+	app.InitChain(abci.RequestInitChain{
+		ConsensusParams: &abci.ConsensusParams{
+			BlockSize: &abci.BlockSize{MaxGas: 100},
+		},
+	})
+
+	app.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx) (newCtx sdk.Context, res sdk.Result, abort bool) {
+		ctx.GasMeter().ConsumeGas(30, "ante")
+		return ctx, sdk.ErrUnauthorized("signature verification failed").Result(), true
+	})
+
+	tx := testUpdatePowerTx{} // doesn't matter
+	header := abci.Header{AppHash: []byte("apphash"), Height: 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+
+	for i := 0; i < 4; i++ {
+		res := app.Deliver(tx)
+		assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnauthorized), res.Code)
+		assert.Equal(t, int64(30), res.GasUsed)
+	}
+	assert.True(t, app.deliverState.ctx.BlockGasMeter().IsOutOfGas())
+
+	// the block is exhausted
+	res := app.Deliver(tx)
+	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeOutOfGas), res.Code)
+
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+}
+
 // Test that we can only query from the latest committed state.
 func TestQuery(t *testing.T) {
 	app := newBaseApp(t.Name())
@@ -501,6 +840,71 @@ func TestQuery(t *testing.T) {
 	assert.Equal(t, value, res.Value)
 }
 
+// Test that custom queries are routed to the registered querier
+// and run against the state at the requested height.
+func TestQueryCustom(t *testing.T) {
+	app := newBaseApp(t.Name())
+
+	// make a cap key and mount the store
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStoresIAVL(capKey)
+	err := app.LoadLatestVersion(capKey) // needed to make stores non-nil
+	assert.Nil(t, err)
+
+	key := []byte("hello")
+	app.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx) (newCtx sdk.Context, res sdk.Result, abort bool) { return })
+	app.Router().AddRoute(msgType, func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		store := ctx.KVStore(capKey)
+		store.Set(key, msg.(testUpdatePowerTx).Addr)
+		return sdk.Result{}
+	})
+	app.QueryRouter().AddRoute("main", func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, sdk.Error) {
+		if len(path) != 1 || path[0] != "hello" {
+			return nil, sdk.ErrUnknownRequest("unknown path")
+		}
+		store := ctx.KVStore(capKey)
+		// writes are never persisted
+		store.Set(key, []byte("garbage"))
+		return store.Get(req.Data), nil
+	})
+
+	// commit two blocks with different values
+	for _, value := range []string{"goodbye", "farewell"} {
+		app.BeginBlock(abci.RequestBeginBlock{})
+		app.Deliver(testUpdatePowerTx{Addr: []byte(value)})
+		app.EndBlock(abci.RequestEndBlock{})
+		app.Commit()
+	}
+
+	// latest height by default
+	query := abci.RequestQuery{Path: "/custom/main/hello", Data: key}
+	res := app.Query(query)
+	require.Equal(t, uint32(sdk.ABCICodeOK), res.Code, res.Log)
+	assert.Equal(t, []byte("farewell"), res.Value)
+	assert.Equal(t, int64(2), res.Height)
+
+	// historical height
+	query.Height = 1
+	res = app.Query(query)
+	require.Equal(t, uint32(sdk.ABCICodeOK), res.Code, res.Log)
+	assert.Equal(t, []byte("goodbye"), res.Value)
+
+	// future height
+	query.Height = 3
+	res = app.Query(query)
+	assert.Equal(t, uint32(sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnknownRequest)), res.Code)
+
+	// querier errors are returned
+	query = abci.RequestQuery{Path: "/custom/main/bye", Data: key}
+	res = app.Query(query)
+	assert.Equal(t, uint32(sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnknownRequest)), res.Code)
+
+	// unknown route
+	query = abci.RequestQuery{Path: "/custom/other/hello", Data: key}
+	res = app.Query(query)
+	assert.Equal(t, uint32(sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnknownRequest)), res.Code)
+}
+
 // Test p2p filter queries
 func TestP2PQuery(t *testing.T) {
 	app := newBaseApp(t.Name())
@@ -546,7 +950,7 @@ type testUpdatePowerTx struct {
 const msgType = "testUpdatePowerTx"
 
 func (tx testUpdatePowerTx) Type() string                       { return msgType }
-func (tx testUpdatePowerTx) GetMsg() sdk.Msg                    { return tx }
+func (tx testUpdatePowerTx) GetMsgs() []sdk.Msg                 { return []sdk.Msg{tx} }
 func (tx testUpdatePowerTx) GetSignBytes() []byte               { return nil }
 func (tx testUpdatePowerTx) ValidateBasic() sdk.Error           { return nil }
 func (tx testUpdatePowerTx) GetSigners() []sdk.Address          { return nil }
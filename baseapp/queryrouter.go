@@ -0,0 +1,41 @@
+package baseapp
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// QueryRouter provides queriers for each custom query route.
+type QueryRouter interface {
+	AddRoute(r string, q sdk.Querier) (rtr QueryRouter)
+	Route(path string) (q sdk.Querier)
+}
+
+type queryRouter struct {
+	routes map[string]sdk.Querier
+}
+
+// nolint
+// NewQueryRouter - create new query router
+func NewQueryRouter() *queryRouter {
+	return &queryRouter{
+		routes: make(map[string]sdk.Querier),
+	}
+}
+
+// AddRoute - register a querier under the given route
+func (rtr *queryRouter) AddRoute(r string, q sdk.Querier) QueryRouter {
+	if !isAlpha(r) {
+		panic("route expressions can only contain alphanumeric characters")
+	}
+	if _, ok := rtr.routes[r]; ok {
+		panic("query route " + r + " has already been registered")
+	}
+	rtr.routes[r] = q
+
+	return rtr
+}
+
+// Route - return the querier registered for the route, nil if none
+func (rtr *queryRouter) Route(path string) (q sdk.Querier) {
+	return rtr.routes[path]
+}
@@ -2,6 +2,7 @@ package baseapp
 
 import (
 	"regexp"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -9,17 +10,18 @@ import (
 // Router provides handlers for each transaction type.
 type Router interface {
 	AddRoute(r string, h sdk.Handler) (rtr Router)
+	AddMiddleware(r string, m Middleware) (rtr Router)
 	Route(path string) (h sdk.Handler)
 }
 
-// map a transaction type to a handler and an initgenesis function
-type route struct {
-	r string
-	h sdk.Handler
-}
+// Middleware wraps the handler of a route, eg. for logging, metrics or
+// permission checks. It may run logic before and after calling next,
+// or return a failing Result without calling next at all.
+type Middleware func(next sdk.Handler) sdk.Handler
 
 type router struct {
-	routes []route
+	routes      map[string]sdk.Handler
+	middlewares map[string][]Middleware
 }
 
 // nolint
@@ -27,29 +29,71 @@ type router struct {
 // TODO either make Function unexported or make return type (router) Exported
 func NewRouter() *router {
 	return &router{
-		routes: make([]route, 0),
+		routes:      make(map[string]sdk.Handler),
+		middlewares: make(map[string][]Middleware),
 	}
 }
 
 var isAlpha = regexp.MustCompile(`^[a-zA-Z]+$`).MatchString
 
-// AddRoute - TODO add description
+// routes are one or more alphabetic segments separated by "/", eg. "bank/send"
+var isRoute = regexp.MustCompile(`^[a-zA-Z]+(/[a-zA-Z]+)*$`).MatchString
+
+// AddRoute - register a handler for all messages whose type is r or
+// is nested under r, eg. "bank" handles both "bank" and "bank/send"
 func (rtr *router) AddRoute(r string, h sdk.Handler) Router {
-	if !isAlpha(r) {
-		panic("route expressions can only contain alphanumeric characters")
+	if !isRoute(r) {
+		panic("route expressions can only contain alphanumeric characters separated by '/'")
+	}
+	if _, ok := rtr.routes[r]; ok {
+		panic("route " + r + " has already been registered")
+	}
+	rtr.routes[r] = h
+
+	return rtr
+}
+
+// AddMiddleware - register a middleware for all messages whose type is r
+// or is nested under r. Middlewares of parent routes wrap those of nested
+// routes, and middlewares of the same route wrap in registration order.
+func (rtr *router) AddMiddleware(r string, m Middleware) Router {
+	if !isRoute(r) {
+		panic("route expressions can only contain alphanumeric characters separated by '/'")
 	}
-	rtr.routes = append(rtr.routes, route{r, h})
+	rtr.middlewares[r] = append(rtr.middlewares[r], m)
 
 	return rtr
 }
 
-// Route - TODO add description
-// TODO handle expressive matches.
+// Route - return the handler of the longest registered route matching
+// path, wrapped in the middlewares of path and all of its parents
 func (rtr *router) Route(path string) (h sdk.Handler) {
-	for _, route := range rtr.routes {
-		if route.r == path {
-			return route.h
+	for prefix := path; prefix != ""; prefix = parentRoute(prefix) {
+		if handler, ok := rtr.routes[prefix]; ok {
+			h = handler
+			break
 		}
 	}
-	return nil
+	if h == nil {
+		return nil
+	}
+
+	// wrap from the innermost route outwards
+	for prefix := path; prefix != ""; prefix = parentRoute(prefix) {
+		middlewares := rtr.middlewares[prefix]
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+	}
+	return h
+}
+
+// parentRoute returns the route with its last segment removed,
+// or the empty string for a top level route
+func parentRoute(r string) string {
+	i := strings.LastIndex(r, "/")
+	if i < 0 {
+		return ""
+	}
+	return r[:i]
 }
@@ -0,0 +1,99 @@
+package baseapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// handler returning its name in the Result log
+func namedHandler(name string) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		return sdk.Result{Log: name}
+	}
+}
+
+// middleware appending its name to the Result log of the handler it wraps
+func namedMiddleware(name string) Middleware {
+	return func(next sdk.Handler) sdk.Handler {
+		return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+			res := next(ctx, msg)
+			res.Log = name + "(" + res.Log + ")"
+			return res
+		}
+	}
+}
+
+func TestRouterAddRoute(t *testing.T) {
+	rtr := NewRouter()
+
+	assert.Panics(t, func() { rtr.AddRoute("", namedHandler("")) })
+	assert.Panics(t, func() { rtr.AddRoute("bank/", namedHandler("")) })
+	assert.Panics(t, func() { rtr.AddRoute("/bank", namedHandler("")) })
+	assert.Panics(t, func() { rtr.AddRoute("bank//send", namedHandler("")) })
+	assert.Panics(t, func() { rtr.AddRoute("bank-send", namedHandler("")) })
+
+	rtr.AddRoute("bank", namedHandler("bank"))
+	rtr.AddRoute("bank/send", namedHandler("send"))
+	assert.Panics(t, func() { rtr.AddRoute("bank", namedHandler("bank")) })
+}
+
+func TestRouterRoute(t *testing.T) {
+	rtr := NewRouter()
+	rtr.AddRoute("bank", namedHandler("bank")).
+		AddRoute("bank/send/multi", namedHandler("multi")).
+		AddRoute("stake", namedHandler("stake"))
+
+	cases := []struct {
+		path string
+		exp  string
+	}{
+		{"bank", "bank"},
+		{"bank/send", "bank"},
+		{"bank/send/multi", "multi"},
+		{"bank/send/multi/more", "multi"},
+		{"stake/delegate", "stake"},
+		{"banking", ""},
+		{"ibc", ""},
+		{"", ""},
+	}
+
+	for _, tc := range cases {
+		h := rtr.Route(tc.path)
+		if tc.exp == "" {
+			assert.Nil(t, h, tc.path)
+			continue
+		}
+		require.NotNil(t, h, tc.path)
+		assert.Equal(t, tc.exp, h(sdk.Context{}, nil).Log, tc.path)
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	rtr := NewRouter()
+	rtr.AddRoute("bank", namedHandler("bank")).
+		AddMiddleware("bank", namedMiddleware("a")).
+		AddMiddleware("bank", namedMiddleware("b")).
+		AddMiddleware("bank/send", namedMiddleware("c")).
+		AddMiddleware("stake", namedMiddleware("d"))
+
+	// parent middlewares wrap nested ones, in registration order
+	assert.Equal(t, "a(b(c(bank)))", rtr.Route("bank/send")(sdk.Context{}, nil).Log)
+	assert.Equal(t, "a(b(bank))", rtr.Route("bank/issue")(sdk.Context{}, nil).Log)
+
+	// middlewares alone don't make a route
+	assert.Nil(t, rtr.Route("stake"))
+
+	// middlewares can stop a msg from reaching the handler
+	rtr.AddMiddleware("bank/issue", func(next sdk.Handler) sdk.Handler {
+		return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+			return sdk.ErrUnauthorized("issuing is disabled").Result()
+		}
+	})
+	res := rtr.Route("bank/issue")(sdk.Context{}, nil)
+	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnauthorized), res.Code)
+	assert.Equal(t, "a(b(", res.Log[:4])
+}
@@ -2,11 +2,15 @@ package context
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/pkg/errors"
 
+	"github.com/cosmos/cosmos-sdk/store"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	abci "github.com/tendermint/abci/types"
+	tmliteProxy "github.com/tendermint/tendermint/lite/proxy"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	cmn "github.com/tendermint/tmlibs/common"
@@ -57,13 +61,50 @@ func (ctx CoreContext) QuerySubspace(cdc *wire.Codec, subspace []byte, storeName
 	return
 }
 
-// Query from Tendermint with the provided storename and path
+// Query a custom route registered by a module, ie. "/custom/<module>/<path>",
+// passing the provided data along to the module's querier
+func (ctx CoreContext) QueryWithData(path string, data []byte) (res []byte, err error) {
+	return ctx.queryPath(path, data)
+}
+
+// Query from Tendermint with the provided storename and path.
+// Unless the node is trusted, the proofs of key queries are verified.
 func (ctx CoreContext) query(key cmn.HexBytes, storeName, endPath string) (res []byte, err error) {
 	path := fmt.Sprintf("/store/%s/%s", storeName, endPath)
-	node, err := ctx.GetNode()
+	verify := !ctx.TrustNode && endPath == "key"
+	if verify && ctx.Height == 0 {
+		ctx.Height, err = ctx.latestVerifiableHeight()
+		if err != nil {
+			return res, err
+		}
+	}
+	resp, err := ctx.queryABCI(path, key)
+	if err != nil {
+		return res, err
+	}
+	if verify {
+		err = ctx.verifyProof(storeName, key, resp)
+		if err != nil {
+			return res, err
+		}
+	}
+	return resp.Value, nil
+}
+
+// Query from Tendermint with the provided abci query path and data
+func (ctx CoreContext) queryPath(path string, key cmn.HexBytes) (res []byte, err error) {
+	resp, err := ctx.queryABCI(path, key)
 	if err != nil {
 		return res, err
 	}
+	return resp.Value, nil
+}
+
+func (ctx CoreContext) queryABCI(path string, key cmn.HexBytes) (resp abci.ResponseQuery, err error) {
+	node, err := ctx.GetNode()
+	if err != nil {
+		return resp, err
+	}
 
 	opts := rpcclient.ABCIQueryOptions{
 		Height:  ctx.Height,
@@ -71,13 +112,62 @@ func (ctx CoreContext) query(key cmn.HexBytes, storeName, endPath string) (res [
 	}
 	result, err := node.ABCIQueryWithOptions(path, key, opts)
 	if err != nil {
-		return res, err
+		return resp, err
 	}
-	resp := result.Response
+	resp = result.Response
 	if resp.Code != uint32(0) {
-		return res, errors.Errorf("Query failed: (%d) %s", resp.Code, resp.Log)
+		return resp, errors.Errorf("Query failed: (%d) %s", resp.Code, resp.Log)
 	}
-	return resp.Value, nil
+	return resp, nil
+}
+
+// The state of the latest height is only committed to by the app hash of the
+// next block, which doesn't exist yet, so the latest verifiable state is the
+// one of the height before.
+func (ctx CoreContext) latestVerifiableHeight() (int64, error) {
+	node, err := ctx.GetNode()
+	if err != nil {
+		return 0, err
+	}
+	status, err := node.Status()
+	if err != nil {
+		return 0, err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+	if latest < 2 {
+		return 0, errors.Errorf("No verifiable height yet at height %d, use --%s to skip verification",
+			latest, client.FlagTrustNode)
+	}
+	return latest - 1, nil
+}
+
+// Verify the proof of a key query against the app hash of the block
+// following the queried height, which commits the state of that height.
+// The header of the block is only trusted once the certifier of the context
+// has checked the signatures of its commit.
+func (ctx CoreContext) verifyProof(storeName string, key cmn.HexBytes, resp abci.ResponseQuery) error {
+	if ctx.Certifier == nil {
+		return errors.Errorf("A certifier of the validator set is required to verify proofs, use --%s to skip verification",
+			client.FlagTrustNode)
+	}
+	node, err := ctx.GetNode()
+	if err != nil {
+		return err
+	}
+	commit, err := tmliteProxy.GetCertifiedCommit(resp.Height+1, node, ctx.Certifier)
+	if err != nil {
+		return errors.Errorf("Failed to certify the app hash of height %d: %v", resp.Height, err)
+	}
+	value := resp.Value
+	if len(value) == 0 {
+		// absent keys are proven with a nil value
+		value = nil
+	}
+	err = store.VerifyMultiStoreProof(resp.Proof, commit.Header.AppHash, storeName, key, value)
+	if err != nil {
+		return errors.Errorf("Failed to verify query response: %v", err)
+	}
+	return nil
 }
 
 // Get the from address from the name flag
@@ -95,65 +185,92 @@ func (ctx CoreContext) GetFromAddress() (from sdk.Address, err error) {
 
 	info, err := keybase.Get(name)
 	if err != nil {
-		return nil, errors.Errorf("No key for: %s", name)
+		// The address of a multisig key, whose txs its members sign.
+		info, err = keys.GetMultisigKey(name)
+		if err != nil {
+			// Unsigned txs may be generated for keys kept offline.
+			if ctx.GenerateOnly {
+				if addr, err := sdk.GetAccAddressBech32(name); err == nil {
+					return addr, nil
+				}
+			}
+			return nil, errors.Errorf("No key for: %s", name)
+		}
 	}
 
 	return info.PubKey.Address(), nil
 }
 
-// sign and build the transaction from the msg
-func (ctx CoreContext) SignAndBuild(name, passphrase string, msg sdk.Msg, cdc *wire.Codec) ([]byte, error) {
-
-	// build the Sign Messsage from the Standard Message
+// build the unsigned Sign Message of the msgs
+func (ctx CoreContext) BuildSignMsg(msgs []sdk.Msg) (auth.StdSignMsg, error) {
 	chainID := ctx.ChainID
 	if chainID == "" {
-		return nil, errors.Errorf("Chain ID required but not specified")
+		return auth.StdSignMsg{}, errors.Errorf("Chain ID required but not specified")
 	}
-	accnum := ctx.AccountNumber
-	sequence := ctx.Sequence
-
-	signMsg := auth.StdSignMsg{
+	fee := auth.NewStdFee(ctx.Gas, sdk.Coin{}) // TODO run simulate to estimate gas?
+	if ctx.FeePayer != "" {
+		payer, err := sdk.GetAccAddressBech32(ctx.FeePayer)
+		if err != nil {
+			return auth.StdSignMsg{}, errors.Errorf("Invalid fee payer %s: %v", ctx.FeePayer, err)
+		}
+		fee.Payer = payer
+	}
+	return auth.StdSignMsg{
 		ChainID:        chainID,
-		AccountNumbers: []int64{accnum},
-		Sequences:      []int64{sequence},
-		Msg:            msg,
-		Fee:            auth.NewStdFee(ctx.Gas, sdk.Coin{}), // TODO run simulate to estimate gas?
+		AccountNumbers: []int64{ctx.AccountNumber},
+		Sequences:      []int64{ctx.Sequence},
+		Msgs:           msgs,
+		Fee:            fee,
+	}, nil
+}
+
+// sign the Sign Message of a tx with a single signer
+func (ctx CoreContext) SignStdTx(name, passphrase string, signMsg auth.StdSignMsg) (auth.StdTx, error) {
+	if len(signMsg.AccountNumbers) != 1 || len(signMsg.Sequences) != 1 {
+		return auth.StdTx{}, errors.Errorf("Only txs with a single signer can be signed")
 	}
 
 	keybase, err := keys.GetKeyBase()
 	if err != nil {
-		return nil, err
+		return auth.StdTx{}, err
 	}
 
-	// sign and build
-	bz := signMsg.Bytes()
-
-	sig, pubkey, err := keybase.Sign(name, passphrase, bz)
+	sig, pubkey, err := keybase.Sign(name, passphrase, signMsg.Bytes())
 	if err != nil {
-		return nil, err
+		return auth.StdTx{}, err
 	}
 	sigs := []auth.StdSignature{{
 		PubKey:        pubkey,
 		Signature:     sig,
-		AccountNumber: accnum,
-		Sequence:      sequence,
+		AccountNumber: signMsg.AccountNumbers[0],
+		Sequence:      signMsg.Sequences[0],
 	}}
-
-	// marshal bytes
-	tx := auth.NewStdTx(signMsg.Msg, signMsg.Fee, sigs)
-
-	return cdc.MarshalBinary(tx)
+	return auth.NewStdTx(signMsg.Msgs, signMsg.Fee, sigs), nil
 }
 
-// sign and build the transaction from the msg
-func (ctx CoreContext) EnsureSignBuildBroadcast(name string, msg sdk.Msg, cdc *wire.Codec) (res *ctypes.ResultBroadcastTxCommit, err error) {
+// sign and build the transaction from the msgs
+func (ctx CoreContext) SignAndBuild(name, passphrase string, msgs []sdk.Msg, cdc *wire.Codec) ([]byte, error) {
 
-	ctx, err = EnsureAccountNumber(ctx)
+	// build the Sign Messsage from the Standard Message
+	signMsg, err := ctx.BuildSignMsg(msgs)
 	if err != nil {
 		return nil, err
 	}
-	// default to next sequence number if none provided
-	ctx, err = EnsureSequence(ctx)
+
+	// sign and build
+	tx, err := ctx.SignStdTx(name, passphrase, signMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	// marshal bytes
+	return cdc.MarshalBinary(tx)
+}
+
+// sign and build the transaction from the msgs
+func (ctx CoreContext) EnsureSignBuildBroadcast(name string, msgs []sdk.Msg, cdc *wire.Codec) (res *ctypes.ResultBroadcastTxCommit, err error) {
+
+	ctx, err = ensureAccountNumberAndSequence(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +280,7 @@ func (ctx CoreContext) EnsureSignBuildBroadcast(name string, msg sdk.Msg, cdc *w
 		return nil, err
 	}
 
-	txBytes, err := ctx.SignAndBuild(name, passphrase, msg, cdc)
+	txBytes, err := ctx.SignAndBuild(name, passphrase, msgs, cdc)
 	if err != nil {
 		return nil, err
 	}
@@ -171,19 +288,48 @@ func (ctx CoreContext) EnsureSignBuildBroadcast(name string, msg sdk.Msg, cdc *w
 	return ctx.BroadcastTx(txBytes)
 }
 
-// get the next sequence for the account address
+// print the unsigned Sign Message of the msgs as JSON, to be signed offline
+func (ctx CoreContext) PrintUnsignedTx(msgs []sdk.Msg, cdc *wire.Codec) error {
+	ctx, err := ensureAccountNumberAndSequence(ctx)
+	if err != nil {
+		return err
+	}
+
+	signMsg, err := ctx.BuildSignMsg(msgs)
+	if err != nil {
+		return err
+	}
+
+	output, err := wire.MarshalJSONIndent(cdc, signMsg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func ensureAccountNumberAndSequence(ctx CoreContext) (CoreContext, error) {
+	ctx, err := EnsureAccountNumber(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	// default to next sequence number if none provided
+	return EnsureSequence(ctx)
+}
+
+// get the account number for the account address
 func (ctx CoreContext) GetAccountNumber(address []byte) (int64, error) {
 	if ctx.Decoder == nil {
 		return 0, errors.New("AccountDecoder required but not provided")
 	}
 
-	res, err := ctx.Query(auth.AddressStoreKey(address), ctx.AccountStore)
+	res, err := ctx.queryLatestAccount(address)
 	if err != nil {
 		return 0, err
 	}
 
 	if len(res) == 0 {
-		fmt.Printf("No account found.  Returning 0.\n")
+		fmt.Fprintf(os.Stderr, "No account found.  Returning 0.\n")
 		return 0, err
 	}
 
@@ -201,13 +347,13 @@ func (ctx CoreContext) NextSequence(address []byte) (int64, error) {
 		return 0, errors.New("AccountDecoder required but not provided")
 	}
 
-	res, err := ctx.Query(auth.AddressStoreKey(address), ctx.AccountStore)
+	res, err := ctx.queryLatestAccount(address)
 	if err != nil {
 		return 0, err
 	}
 
 	if len(res) == 0 {
-		fmt.Printf("No account found, defaulting to sequence 0\n")
+		fmt.Fprintf(os.Stderr, "No account found, defaulting to sequence 0\n")
 		return 0, err
 	}
 
@@ -219,6 +365,13 @@ func (ctx CoreContext) NextSequence(address []byte) (int64, error) {
 	return account.GetSequence(), nil
 }
 
+// Query the account at the latest height, to sign with its current account
+// number and sequence. The latest state can't be verified yet, as its app
+// hash is only in the header of the next block, so the node is trusted.
+func (ctx CoreContext) queryLatestAccount(address []byte) ([]byte, error) {
+	return ctx.WithTrustNode(true).WithHeight(0).Query(auth.AddressStoreKey(address), ctx.AccountStore)
+}
+
 // get passphrase from std input
 func (ctx CoreContext) GetPassphraseFromStdin(name string) (pass string, err error) {
 	buf := client.BufferStdin()
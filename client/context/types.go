@@ -1,6 +1,7 @@
 package context
 
 import (
+	"github.com/tendermint/tendermint/lite"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 
 	"github.com/cosmos/cosmos-sdk/x/auth"
@@ -19,6 +20,9 @@ type CoreContext struct {
 	Client          rpcclient.Client
 	Decoder         auth.AccountDecoder
 	AccountStore    string
+	GenerateOnly    bool
+	FeePayer        string
+	Certifier       lite.Certifier
 }
 
 // WithChainID - return a copy of the context with an updated chainID
@@ -45,6 +49,13 @@ func (c CoreContext) WithTrustNode(trustNode bool) CoreContext {
 	return c
 }
 
+// WithCertifier - return a copy of the context with an updated certifier of
+// the headers against which proofs are verified
+func (c CoreContext) WithCertifier(certifier lite.Certifier) CoreContext {
+	c.Certifier = certifier
+	return c
+}
+
 // WithNodeURI - return a copy of the context with an updated node URI
 func (c CoreContext) WithNodeURI(nodeURI string) CoreContext {
 	c.NodeURI = nodeURI
@@ -87,3 +98,17 @@ func (c CoreContext) WithAccountStore(accountStore string) CoreContext {
 	c.AccountStore = accountStore
 	return c
 }
+
+// WithGenerateOnly - return a copy of the context which prints unsigned txs
+// rather than sign and broadcast them
+func (c CoreContext) WithGenerateOnly(generateOnly bool) CoreContext {
+	c.GenerateOnly = generateOnly
+	return c
+}
+
+// WithFeePayer - return a copy of the context with the address of the
+// account paying the fees of txs
+func (c CoreContext) WithFeePayer(feePayer string) CoreContext {
+	c.FeePayer = feePayer
+	return c
+}
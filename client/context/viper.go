@@ -2,6 +2,7 @@ package context
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/viper"
 
@@ -39,6 +40,8 @@ func NewCoreContextFromViper() CoreContext {
 		Client:          rpc,
 		Decoder:         nil,
 		AccountStore:    "acc",
+		GenerateOnly:    viper.GetBool(client.FlagGenerateOnly),
+		FeePayer:        viper.GetString(client.FlagFeePayer),
 	}
 }
 
@@ -69,7 +72,7 @@ func EnsureAccountNumber(ctx CoreContext) (CoreContext, error) {
 	if err != nil {
 		return ctx, err
 	}
-	fmt.Printf("Defaulting to account number: %d\n", accnum)
+	fmt.Fprintf(os.Stderr, "Defaulting to account number: %d\n", accnum)
 	ctx = ctx.WithAccountNumber(accnum)
 	return ctx, nil
 }
@@ -88,7 +91,7 @@ func EnsureSequence(ctx CoreContext) (CoreContext, error) {
 	if err != nil {
 		return ctx, err
 	}
-	fmt.Printf("Defaulting to next sequence number: %d\n", seq)
+	fmt.Fprintf(os.Stderr, "Defaulting to next sequence number: %d\n", seq)
 	ctx = ctx.WithSequence(seq)
 	return ctx, nil
 }
@@ -13,6 +13,8 @@ const (
 	FlagAccountNumber = "account-number"
 	FlagSequence      = "sequence"
 	FlagFee           = "fee"
+	FlagGenerateOnly  = "generate-only"
+	FlagFeePayer      = "fee-payer"
 )
 
 // LineBreak can be included in a command list to provide a blank line
@@ -22,7 +24,7 @@ var LineBreak = &cobra.Command{Run: func(*cobra.Command, []string) {}}
 // GetCommands adds common flags to query commands
 func GetCommands(cmds ...*cobra.Command) []*cobra.Command {
 	for _, c := range cmds {
-		// TODO: make this default false when we support proofs
+		// TODO: make this default false once a certifier of the validator set can be configured
 		c.Flags().Bool(FlagTrustNode, true, "Don't verify proofs for responses")
 		c.Flags().String(FlagChainID, "", "Chain ID of tendermint node")
 		c.Flags().String(FlagNode, "tcp://localhost:46657", "<host>:<port> to tendermint rpc interface for this chain")
@@ -41,6 +43,9 @@ func PostCommands(cmds ...*cobra.Command) []*cobra.Command {
 		c.Flags().String(FlagChainID, "", "Chain ID of tendermint node")
 		c.Flags().String(FlagNode, "tcp://localhost:46657", "<host>:<port> to tendermint rpc interface for this chain")
 		c.Flags().Int64(FlagGas, 200000, "gas limit to set per-transaction")
+		c.Flags().Bool(FlagGenerateOnly, false, "Print the unsigned tx as JSON rather than sign and broadcast it; --name may be the address of a key kept offline")
+		c.Flags().String(FlagFeePayer, "", "Address of the account paying the fee out of a fee allowance it granted to the signer")
+		c.Flags().Bool(FlagTrustNode, true, "Don't verify proofs for responses")
 	}
 	return cmds
 }
@@ -0,0 +1,111 @@
+package keys
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	crypto "github.com/tendermint/go-crypto"
+	keys "github.com/tendermint/go-crypto/keys"
+	"github.com/tendermint/tmlibs/cli"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// MultisigDBName is the name of the db, next to that of the keybase, where we
+// store multisig keys, which the keybase can't hold as they have no private key
+const MultisigDBName = "multisig"
+
+const flagThreshold = "threshold"
+
+// multisigDB is used to make getMultisigDB a singleton
+var multisigDB dbm.DB
+
+func getMultisigDB() (dbm.DB, error) {
+	if multisigDB == nil {
+		rootDir := viper.GetString(cli.HomeFlag)
+		db, err := dbm.NewGoLevelDB(MultisigDBName, filepath.Join(rootDir, "keys"))
+		if err != nil {
+			return nil, err
+		}
+		multisigDB = db
+	}
+	return multisigDB, nil
+}
+
+// GetMultisigKey returns the info of the named multisig key, whose PubKey
+// is an auth.MultisigThresholdPubKey
+func GetMultisigKey(name string) (keys.Info, error) {
+	db, err := getMultisigDB()
+	if err != nil {
+		return keys.Info{}, err
+	}
+	bz := db.Get([]byte(name))
+	if bz == nil {
+		return keys.Info{}, errors.Errorf("No multisig key for: %s", name)
+	}
+	var pubKey auth.MultisigThresholdPubKey
+	if err = cdc.UnmarshalBinaryBare(bz, &pubKey); err != nil {
+		return keys.Info{}, err
+	}
+	return keys.Info{Name: name, PubKey: pubKey}, nil
+}
+
+func addMultisigKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-multisig <name> <key>...",
+		Short: "Create a multisig key from existing keys",
+		Long: `Add a k-of-n multisig public key to the key store, made of the public
+keys of n existing keys, which need not have their private key here. Txs
+from the address of the multisig key are valid with the signatures of k
+of the keys: see the sign-partial and multisign commands.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: runAddMultisigCmd,
+	}
+	cmd.Flags().Int(flagThreshold, 1, "Number of keys which must sign")
+	return cmd
+}
+
+func runAddMultisigCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	kb, err := GetKeyBase()
+	if err != nil {
+		return err
+	}
+	if _, err = kb.Get(name); err == nil {
+		return errors.Errorf("A key named %s exists already", name)
+	}
+
+	pubKeys := make([]crypto.PubKey, len(args)-1)
+	for i, member := range args[1:] {
+		info, err := kb.Get(member)
+		if err != nil {
+			return errors.Errorf("No key for: %s", member)
+		}
+		pubKeys[i] = info.PubKey
+	}
+	pubKey, err := auth.NewMultisigThresholdPubKey(viper.GetInt(flagThreshold), pubKeys)
+	if err != nil {
+		return err
+	}
+
+	db, err := getMultisigDB()
+	if err != nil {
+		return err
+	}
+	bz, err := cdc.MarshalBinaryBare(pubKey)
+	if err != nil {
+		return err
+	}
+	db.SetSync([]byte(name), bz)
+
+	printInfo(keys.Info{Name: name, PubKey: pubKey})
+	if viper.Get(cli.OutputFlag) == "text" {
+		fmt.Printf("%d of the %d keys must sign\n", pubKey.K, len(pubKey.PubKeys))
+	}
+	return nil
+}
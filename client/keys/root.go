@@ -20,6 +20,7 @@ func Commands() *cobra.Command {
 	}
 	cmd.AddCommand(
 		addKeyCommand(),
+		addMultisigKeyCommand(),
 		listKeysCmd,
 		showKeysCmd,
 		client.LineBreak,
@@ -31,7 +31,13 @@ func getKey(name string) (keys.Info, error) {
 		return keys.Info{}, err
 	}
 
-	return kb.Get(name)
+	info, err := kb.Get(name)
+	if err != nil {
+		if multisigInfo, multisigErr := GetMultisigKey(name); multisigErr == nil {
+			return multisigInfo, nil
+		}
+	}
+	return info, err
 }
 
 ///////////////////////////
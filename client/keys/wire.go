@@ -2,6 +2,7 @@ package keys
 
 import (
 	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
 )
 
 var cdc *wire.Codec
@@ -9,6 +10,7 @@ var cdc *wire.Codec
 func init() {
 	cdc = wire.NewCodec()
 	wire.RegisterCrypto(cdc)
+	auth.RegisterMultisig(cdc)
 }
 
 // marshal keys
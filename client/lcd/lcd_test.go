@@ -13,10 +13,13 @@ import (
 
 	abci "github.com/tendermint/abci/types"
 	cryptoKeys "github.com/tendermint/go-crypto/keys"
+	"github.com/tendermint/tendermint/lite"
 	p2p "github.com/tendermint/tendermint/p2p"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 
 	client "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
 	keys "github.com/cosmos/cosmos-sdk/client/keys"
 	rpc "github.com/cosmos/cosmos-sdk/client/rpc"
 	tests "github.com/cosmos/cosmos-sdk/tests"
@@ -243,6 +246,83 @@ func TestCoinSend(t *testing.T) {
 	assert.Equal(t, int64(1), mycoins.Amount)
 }
 
+func TestQueryVerified(t *testing.T) {
+	addr, _ := CreateAddr(t, "test", "1234567890", GetKB(t))
+	cleanup, _, port := InitializeTestLCD(t, 1, []sdk.Address{addr})
+	defer cleanup()
+	tests.WaitForHeight(3, port)
+
+	// proofs aren't verified without a certifier of the validator set
+	ctx := context.NewCoreContextFromViper().WithTrustNode(false)
+	_, err := ctx.Query(auth.AddressStoreKey(addr), "acc")
+	require.NotNil(t, err)
+
+	// trust the genesis validator set
+	node, err := ctx.GetNode()
+	require.Nil(t, err)
+	height := int64(1)
+	vals, err := node.Validators(&height)
+	require.Nil(t, err)
+	ctx = ctx.WithCertifier(lite.NewStaticCertifier(ctx.ChainID, tmtypes.NewValidatorSet(vals.Validators)))
+
+	// queries of the latest height are verified against the previous height,
+	// whose app hash is in the certified header of the latest block
+	res, err := ctx.Query(auth.AddressStoreKey(addr), "acc")
+	require.Nil(t, err)
+	assert.NotNil(t, res)
+
+	// absent keys are verified too
+	res, err = ctx.Query(auth.AddressStoreKey(sdk.Address([]byte("absent"))), "acc")
+	require.Nil(t, err)
+	assert.Empty(t, res)
+}
+
+func TestCoinSendGenerateSignAndBroadcast(t *testing.T) {
+	name, password := "test", "1234567890"
+	addr, _ := CreateAddr(t, "test", password, GetKB(t))
+	cleanup, _, port := InitializeTestLCD(t, 2, []sdk.Address{addr})
+	defer cleanup()
+
+	acc := getAccount(t, port, addr)
+	initialBalance := acc.GetCoins()
+
+	// generate the unsigned tx of a key given by its address
+	receiveAddr := sdk.Address([]byte("receive_address"))
+	jsonStr := []byte(fmt.Sprintf(`{
+		"from":"%s",
+		"generate_only":true,
+		"account_number":%d,
+		"sequence":%d,
+		"gas": 10000,
+		"amount":[{ "denom": "steak", "amount": 1 }]
+	}`, sdk.MustBech32ifyAcc(addr), acc.GetAccountNumber(), acc.GetSequence()))
+	res, body := Request(t, port, "POST", "/accounts/"+sdk.MustBech32ifyAcc(receiveAddr)+"/send", jsonStr)
+	require.Equal(t, http.StatusOK, res.StatusCode, body)
+	var signMsg auth.StdSignMsg
+	require.Nil(t, cdc.UnmarshalJSON([]byte(body), &signMsg))
+	require.Equal(t, 1, len(signMsg.Msgs))
+
+	// sign it
+	jsonStr = []byte(fmt.Sprintf(`{"name":"%s","password":"%s","tx":%s}`, name, password, body))
+	res, body = Request(t, port, "POST", "/txs/sign", jsonStr)
+	require.Equal(t, http.StatusOK, res.StatusCode, body)
+	var tx auth.StdTx
+	require.Nil(t, cdc.UnmarshalJSON([]byte(body), &tx))
+	require.Equal(t, 1, len(tx.GetSignatures()))
+
+	// and broadcast it
+	jsonStr = []byte(fmt.Sprintf(`{"tx":%s}`, body))
+	res, body = Request(t, port, "POST", "/txs/broadcast", jsonStr)
+	require.Equal(t, http.StatusOK, res.StatusCode, body)
+	var resultTx ctypes.ResultBroadcastTxCommit
+	require.Nil(t, cdc.UnmarshalJSON([]byte(body), &resultTx))
+	tests.WaitForHeight(resultTx.Height+1, port)
+	assert.Equal(t, uint32(0), resultTx.DeliverTx.Code)
+
+	acc = getAccount(t, port, addr)
+	assert.Equal(t, initialBalance[0].Amount-1, acc.GetCoins()[0].Amount)
+}
+
 func TestIBCTransfer(t *testing.T) {
 	name, password := "test", "1234567890"
 	addr, seed := CreateAddr(t, "test", password, GetKB(t))
@@ -515,7 +595,7 @@ func doBond(t *testing.T, port, seed, name, password string, delegatorAddr, vali
 		"password": "%s",
 		"account_number": %d,
 		"sequence": %d,
-		"gas": 10000,
+		"gas": 100000,
 		"delegate": [
 			{
 				"delegator_addr": "%s",
@@ -550,7 +630,7 @@ func doUnbond(t *testing.T, port, seed, name, password string, delegatorAddr, va
 		"password": "%s",
 		"account_number": %d,
 		"sequence": %d,
-		"gas": 10000,
+		"gas": 100000,
 		"delegate": [],
 		"unbond": [
 			{
@@ -2,36 +2,97 @@ package tx
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
 )
 
+// BroadcastTxCmd broadcasts a signed tx
+func BroadcastTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast <file>",
+		Short: "Broadcast a tx signed offline",
+		Long: `Broadcast the tx in the file, a signed StdTx in JSON as printed by the
+sign command, and wait for it to be committed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bz, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var tx auth.StdTx
+			if err = cdc.UnmarshalJSON(bz, &tx); err != nil {
+				return errors.Errorf("Invalid tx in %s: %v", args[0], err)
+			}
+			txBytes, err := cdc.MarshalBinary(tx)
+			if err != nil {
+				return err
+			}
+
+			res, err := context.NewCoreContextFromViper().BroadcastTx(txBytes)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+			return nil
+		},
+	}
+	cmd.Flags().String(client.FlagNode, "tcp://localhost:46657", "<host>:<port> to tendermint rpc interface for this chain")
+	return cmd
+}
+
 // Tx Broadcast Body
 type BroadcastTxBody struct {
-	TxBytes string `json="tx"`
+	Tx auth.StdTx `json:"tx"`
 }
 
 // BroadcastTx REST Handler
-func BroadcastTxRequestHandlerFn(ctx context.CoreContext) http.HandlerFunc {
+func BroadcastTxRequestHandlerFn(cdc *wire.Codec, ctx context.CoreContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var m BroadcastTxBody
 
-		decoder := json.NewDecoder(r.Body)
-		err := decoder.Decode(&m)
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		err = cdc.UnmarshalJSON(body, &m)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		txBytes, err := cdc.MarshalBinary(m.Tx)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		res, err := ctx.BroadcastTx(txBytes)
 		if err != nil {
-			w.WriteHeader(400)
+			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte(err.Error()))
 			return
 		}
 
-		res, err := ctx.BroadcastTx([]byte(m.TxBytes))
+		output, err := json.MarshalIndent(res, "", "  ")
 		if err != nil {
-			w.WriteHeader(500)
+			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte(err.Error()))
 			return
 		}
 
-		w.Write([]byte(string(res.Height)))
+		w.Write(output)
 	}
 }
@@ -1,48 +1,53 @@
 package tx
 
 import (
-	"encoding/json"
+	"io/ioutil"
 	"net/http"
 
-	keybase "github.com/cosmos/cosmos-sdk/client/keys"
-	keys "github.com/tendermint/go-crypto/keys"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
 )
 
 // REST request body
-// TODO does this need to be exposed?
 type SignTxBody struct {
-	Name     string `json="name"`
-	Password string `json="password"`
-	TxBytes  string `json="tx"`
+	Name     string          `json:"name"`
+	Password string          `json:"password"`
+	Tx       auth.StdSignMsg `json:"tx"`
 }
 
 // sign transaction REST Handler
-func SignTxRequstHandler(w http.ResponseWriter, r *http.Request) {
-	var kb keys.Keybase
-	var m SignTxBody
-
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&m)
-	if err != nil {
-		w.WriteHeader(400)
-		w.Write([]byte(err.Error()))
-		return
+func SignTxRequestHandlerFn(cdc *wire.Codec, ctx context.CoreContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var m SignTxBody
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		err = cdc.UnmarshalJSON(body, &m)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		tx, err := ctx.SignStdTx(m.Name, m.Password, m.Tx)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		output, err := wire.MarshalJSONIndent(cdc, tx)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.Write(output)
 	}
-
-	kb, err = keybase.GetKeyBase()
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(err.Error()))
-		return
-	}
-
-	//TODO check if account exists
-	sig, _, err := kb.Sign(m.Name, m.Password, []byte(m.TxBytes))
-	if err != nil {
-		w.WriteHeader(403)
-		w.Write([]byte(err.Error()))
-		return
-	}
-
-	w.Write(sig.Bytes())
 }
@@ -41,6 +41,7 @@ type GaiaApp struct {
 	keyIBC      *sdk.KVStoreKey
 	keyStake    *sdk.KVStoreKey
 	keySlashing *sdk.KVStoreKey
+	keyFee      *sdk.KVStoreKey
 
 	// Manage getting and setting accounts
 	accountMapper       auth.AccountMapper
@@ -63,6 +64,7 @@ func NewGaiaApp(logger log.Logger, db dbm.DB) *GaiaApp {
 		keyIBC:      sdk.NewKVStoreKey("ibc"),
 		keyStake:    sdk.NewKVStoreKey("stake"),
 		keySlashing: sdk.NewKVStoreKey("slashing"),
+		keyFee:      sdk.NewKVStoreKey("fee"),
 	}
 
 	// define the accountMapper
@@ -73,6 +75,7 @@ func NewGaiaApp(logger log.Logger, db dbm.DB) *GaiaApp {
 	)
 
 	// add handlers
+	app.feeCollectionKeeper = auth.NewFeeCollectionKeeper(app.cdc, app.keyFee)
 	app.coinKeeper = bank.NewKeeper(app.accountMapper)
 	app.ibcMapper = ibc.NewMapper(app.cdc, app.keyIBC, app.RegisterCodespace(ibc.DefaultCodespace))
 	app.stakeKeeper = stake.NewKeeper(app.cdc, app.keyStake, app.coinKeeper, app.RegisterCodespace(stake.DefaultCodespace))
@@ -83,14 +86,19 @@ func NewGaiaApp(logger log.Logger, db dbm.DB) *GaiaApp {
 		AddRoute("bank", bank.NewHandler(app.coinKeeper)).
 		AddRoute("ibc", ibc.NewHandler(app.ibcMapper, app.coinKeeper)).
 		AddRoute("stake", stake.NewHandler(app.stakeKeeper)).
-		AddRoute("slashing", slashing.NewHandler(app.slashingKeeper))
+		AddRoute("slashing", slashing.NewHandler(app.slashingKeeper)).
+		AddRoute("auth/feeAllowance", auth.NewFeeAllowanceHandler(app.feeCollectionKeeper))
+
+	// register query routes
+	app.QueryRouter().
+		AddRoute("stake", stake.NewQuerier(app.stakeKeeper))
 
 	// initialize BaseApp
 	app.SetInitChainer(app.initChainer)
 	app.SetBeginBlocker(app.BeginBlocker)
 	app.SetEndBlocker(app.EndBlocker)
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper, app.feeCollectionKeeper))
-	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keySlashing)
+	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keySlashing, app.keyFee)
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
 		cmn.Exit(err.Error())
@@ -124,9 +132,11 @@ func (app *GaiaApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) ab
 // application updates every end block
 func (app *GaiaApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
 	validatorUpdates := stake.EndBlocker(ctx, app.stakeKeeper)
+	tags := auth.EndBlocker(ctx, app.accountMapper)
 
 	return abci.ResponseEndBlock{
 		ValidatorUpdates: validatorUpdates,
+		Tags:             tags.ToKVPairs(),
 	}
 }
 
@@ -144,14 +154,23 @@ func (app *GaiaApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) abci
 
 	// load the accounts
 	for _, gacc := range genesisState.Accounts {
+		if err := gacc.Validate(); err != nil {
+			panic(err)
+		}
 		acc := gacc.ToAccount()
-		acc.AccountNumber = app.accountMapper.GetNextAccountNumber(ctx)
+		acc.SetAccountNumber(app.accountMapper.GetNextAccountNumber(ctx))
 		app.accountMapper.SetAccount(ctx, acc)
 	}
 
 	// load the initial stake information
 	stake.InitGenesis(ctx, app.stakeKeeper, genesisState.StakeData)
 
+	// set the chain-wide minimum gas prices
+	app.feeCollectionKeeper.SetMinimumGasPrices(ctx, genesisState.MinimumGasPrices)
+
+	// set the account pruning parameters
+	app.accountMapper.SetPruningParams(ctx, genesisState.AccountPruning)
+
 	return abci.ResponseInitChain{}
 }
 
@@ -169,8 +188,10 @@ func (app *GaiaApp) ExportAppStateAndValidators() (appState json.RawMessage, val
 	app.accountMapper.IterateAccounts(ctx, appendAccount)
 
 	genState := GenesisState{
-		Accounts:  accounts,
-		StakeData: stake.WriteGenesis(ctx, app.stakeKeeper),
+		Accounts:         accounts,
+		StakeData:        stake.WriteGenesis(ctx, app.stakeKeeper),
+		MinimumGasPrices: app.feeCollectionKeeper.GetMinimumGasPrices(ctx),
+		AccountPruning:   app.accountMapper.GetPruningParams(ctx),
 	}
 	appState, err = wire.MarshalJSONIndent(app.cdc, genState)
 	if err != nil {
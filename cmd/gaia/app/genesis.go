@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -18,14 +19,25 @@ import (
 
 // State to Unmarshal
 type GenesisState struct {
-	Accounts  []GenesisAccount   `json:"accounts"`
-	StakeData stake.GenesisState `json:"stake"`
+	Accounts         []GenesisAccount   `json:"accounts"`
+	StakeData        stake.GenesisState `json:"stake"`
+	MinimumGasPrices sdk.GasPrices      `json:"min_gas_prices"`
+	AccountPruning   auth.PruningParams `json:"account_pruning"`
 }
 
-// GenesisAccount doesn't need pubkey or sequence
+// GenesisAccount doesn't need pubkey or sequence. An account with
+// OriginalVesting coins is a vesting account: they vest linearly from
+// VestingStartTime until VestingEndTime, or all at VestingEndTime if it has
+// no start time.
 type GenesisAccount struct {
 	Address sdk.Address `json:"address"`
 	Coins   sdk.Coins   `json:"coins"`
+
+	OriginalVesting  sdk.Coins `json:"original_vesting,omitempty"`
+	DelegatedFree    sdk.Coins `json:"delegated_free,omitempty"`
+	DelegatedVesting sdk.Coins `json:"delegated_vesting,omitempty"`
+	VestingStartTime int64     `json:"vesting_start_time,omitempty"`
+	VestingEndTime   int64     `json:"vesting_end_time,omitempty"`
 }
 
 func NewGenesisAccount(acc *auth.BaseAccount) GenesisAccount {
@@ -36,18 +48,58 @@ func NewGenesisAccount(acc *auth.BaseAccount) GenesisAccount {
 }
 
 func NewGenesisAccountI(acc auth.Account) GenesisAccount {
-	return GenesisAccount{
+	gacc := GenesisAccount{
 		Address: acc.GetAddress(),
 		Coins:   acc.GetCoins(),
 	}
+	if vacc, ok := acc.(auth.VestingAccount); ok {
+		gacc.OriginalVesting = vacc.GetOriginalVesting()
+		gacc.DelegatedFree = vacc.GetDelegatedFree()
+		gacc.DelegatedVesting = vacc.GetDelegatedVesting()
+		gacc.VestingEndTime = vacc.GetEndTime()
+		if cvacc, ok := acc.(*auth.ContinuousVestingAccount); ok {
+			gacc.VestingStartTime = cvacc.GetStartTime()
+		}
+	}
+	return gacc
 }
 
-// convert GenesisAccount to auth.BaseAccount
-func (ga *GenesisAccount) ToAccount() (acc *auth.BaseAccount) {
-	return &auth.BaseAccount{
+// Validate checks the vesting schedule of the account
+func (ga *GenesisAccount) Validate() error {
+	if len(ga.OriginalVesting) == 0 {
+		return nil
+	}
+	if !ga.OriginalVesting.Sort().IsValid() || !ga.OriginalVesting.IsPositive() {
+		return fmt.Errorf("invalid original vesting coins of %v: %v", ga.Address, ga.OriginalVesting)
+	}
+	if !ga.Coins.Sort().Plus(ga.DelegatedFree.Sort()).Plus(ga.DelegatedVesting.Sort()).IsGTE(ga.OriginalVesting) {
+		return fmt.Errorf("original vesting coins of %v exceed its coins", ga.Address)
+	}
+	if ga.VestingStartTime != 0 && ga.VestingStartTime >= ga.VestingEndTime {
+		return fmt.Errorf("vesting of %v must end after it starts", ga.Address)
+	}
+	return nil
+}
+
+// convert GenesisAccount to auth.Account, a vesting account if it has
+// original vesting coins
+func (ga *GenesisAccount) ToAccount() auth.Account {
+	acc := auth.BaseAccount{
 		Address: ga.Address,
 		Coins:   ga.Coins.Sort(),
 	}
+	if len(ga.OriginalVesting) == 0 {
+		return &acc
+	}
+
+	if ga.VestingStartTime != 0 {
+		cvacc := auth.NewContinuousVestingAccount(acc, ga.OriginalVesting.Sort(), ga.VestingStartTime, ga.VestingEndTime)
+		cvacc.DelegatedFree, cvacc.DelegatedVesting = ga.DelegatedFree.Sort(), ga.DelegatedVesting.Sort()
+		return cvacc
+	}
+	dvacc := auth.NewDelayedVestingAccount(acc, ga.OriginalVesting.Sort(), ga.VestingEndTime)
+	dvacc.DelegatedFree, dvacc.DelegatedVesting = ga.DelegatedFree.Sort(), ga.DelegatedVesting.Sort()
+	return dvacc
 }
 
 var (
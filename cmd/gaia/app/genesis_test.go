@@ -14,7 +14,32 @@ func TestToAccount(t *testing.T) {
 	addr := sdk.Address(priv.PubKey().Address())
 	authAcc := auth.NewBaseAccountWithAddress(addr)
 	genAcc := NewGenesisAccount(&authAcc)
-	assert.Equal(t, authAcc, *genAcc.ToAccount())
+	assert.Equal(t, &authAcc, genAcc.ToAccount())
+}
+
+func TestToVestingAccount(t *testing.T) {
+	addr := sdk.Address(crypto.GenPrivKeyEd25519().PubKey().Address())
+	coins := sdk.Coins{{"steak", 100}}
+
+	// a start time makes a continuous vesting account
+	genAcc := GenesisAccount{Address: addr, Coins: coins, OriginalVesting: coins, VestingStartTime: 10, VestingEndTime: 20}
+	assert.Nil(t, genAcc.Validate())
+	acc := genAcc.ToAccount()
+	assert.IsType(t, &auth.ContinuousVestingAccount{}, acc)
+	assert.Equal(t, genAcc, NewGenesisAccountI(acc))
+
+	// otherwise it is delayed
+	genAcc = GenesisAccount{Address: addr, Coins: coins, OriginalVesting: coins, VestingEndTime: 20}
+	assert.Nil(t, genAcc.Validate())
+	acc = genAcc.ToAccount()
+	assert.IsType(t, &auth.DelayedVestingAccount{}, acc)
+	assert.Equal(t, genAcc, NewGenesisAccountI(acc))
+
+	// the schedule must be valid
+	genAcc = GenesisAccount{Address: addr, Coins: coins, OriginalVesting: sdk.Coins{{"steak", 200}}, VestingEndTime: 20}
+	assert.NotNil(t, genAcc.Validate())
+	genAcc = GenesisAccount{Address: addr, Coins: coins, OriginalVesting: coins, VestingStartTime: 20, VestingEndTime: 10}
+	assert.NotNil(t, genAcc.Validate())
 }
 
 func TestGaiaAppGenTx(t *testing.T) {
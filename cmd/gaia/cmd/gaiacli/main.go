@@ -105,11 +105,20 @@ func main() {
 	rootCmd.AddCommand(
 		client.GetCommands(
 			authcmd.GetAccountCmd("acc", cdc, authcmd.GetAccountDecoder(cdc)),
+			authcmd.GetFeeAllowanceCmd("fee", cdc),
 		)...)
 	rootCmd.AddCommand(
 		client.PostCommands(
 			bankcmd.SendTxCmd(cdc),
+			authcmd.GrantFeeAllowanceCmd(cdc),
+			authcmd.RevokeFeeAllowanceCmd(cdc),
 		)...)
+	rootCmd.AddCommand(
+		authcmd.SignTxCmd(cdc),
+		tx.BroadcastTxCmd(cdc),
+		authcmd.SignPartialCmd(cdc),
+		authcmd.MultiSignCmd(cdc),
+	)
 
 	// add proxy, version and key info
 	rootCmd.AddCommand(
@@ -25,9 +25,11 @@ func main() {
 		PersistentPreRunE: server.PersistentPreRunEFn(ctx),
 	}
 
+	appCreator := server.ConstructAppCreator(newApp, "gaia")
 	server.AddCommands(ctx, cdc, rootCmd, app.GaiaAppInit(),
-		server.ConstructAppCreator(newApp, "gaia"),
+		appCreator,
 		server.ConstructAppExporter(exportAppStateAndTMValidators, "gaia"))
+	rootCmd.AddCommand(server.MigrateDBCmd(ctx, appCreator, "gaia"))
 
 	// prepare and add flags
 	executor := cli.PrepareBaseCmd(rootCmd, "GA", app.DefaultNodeHome)
@@ -36,6 +36,7 @@ type BasecoinApp struct {
 	keyIBC      *sdk.KVStoreKey
 	keyStake    *sdk.KVStoreKey
 	keySlashing *sdk.KVStoreKey
+	keyFee      *sdk.KVStoreKey
 
 	// Manage getting and setting accounts
 	accountMapper       auth.AccountMapper
@@ -60,6 +61,7 @@ func NewBasecoinApp(logger log.Logger, db dbm.DB) *BasecoinApp {
 		keyIBC:      sdk.NewKVStoreKey("ibc"),
 		keyStake:    sdk.NewKVStoreKey("stake"),
 		keySlashing: sdk.NewKVStoreKey("slashing"),
+		keyFee:      sdk.NewKVStoreKey("fee"),
 	}
 
 	// Define the accountMapper.
@@ -70,6 +72,7 @@ func NewBasecoinApp(logger log.Logger, db dbm.DB) *BasecoinApp {
 	)
 
 	// add accountMapper/handlers
+	app.feeCollectionKeeper = auth.NewFeeCollectionKeeper(app.cdc, app.keyFee)
 	app.coinKeeper = bank.NewKeeper(app.accountMapper)
 	app.ibcMapper = ibc.NewMapper(app.cdc, app.keyIBC, app.RegisterCodespace(ibc.DefaultCodespace))
 	app.stakeKeeper = stake.NewKeeper(app.cdc, app.keyStake, app.coinKeeper, app.RegisterCodespace(stake.DefaultCodespace))
@@ -78,6 +81,7 @@ func NewBasecoinApp(logger log.Logger, db dbm.DB) *BasecoinApp {
 	// register message routes
 	app.Router().
 		AddRoute("auth", auth.NewHandler(app.accountMapper)).
+		AddRoute("auth/feeAllowance", auth.NewFeeAllowanceHandler(app.feeCollectionKeeper)).
 		AddRoute("bank", bank.NewHandler(app.coinKeeper)).
 		AddRoute("ibc", ibc.NewHandler(app.ibcMapper, app.coinKeeper)).
 		AddRoute("stake", stake.NewHandler(app.stakeKeeper))
@@ -87,7 +91,7 @@ func NewBasecoinApp(logger log.Logger, db dbm.DB) *BasecoinApp {
 	app.SetBeginBlocker(app.BeginBlocker)
 	app.SetEndBlocker(app.EndBlocker)
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper, app.feeCollectionKeeper))
-	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keySlashing)
+	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keySlashing, app.keyFee)
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
 		cmn.Exit(err.Error())
@@ -107,6 +111,9 @@ func MakeCodec() *wire.Codec {
 
 	// register custom AppAccount
 	cdc.RegisterInterface((*auth.Account)(nil), nil)
+	auth.RegisterMultisig(cdc)
+	cdc.RegisterConcrete(auth.MsgGrantFeeAllowance{}, "auth/GrantFeeAllowance", nil)
+	cdc.RegisterConcrete(auth.MsgRevokeFeeAllowance{}, "auth/RevokeFeeAllowance", nil)
 	cdc.RegisterConcrete(&types.AppAccount{}, "basecoin/Account", nil)
 	return cdc
 }
@@ -26,9 +26,11 @@ func main() {
 		PersistentPreRunE: server.PersistentPreRunEFn(ctx),
 	}
 
+	appCreator := server.ConstructAppCreator(newApp, "basecoin")
 	server.AddCommands(ctx, cdc, rootCmd, server.DefaultAppInit,
-		server.ConstructAppCreator(newApp, "basecoin"),
+		appCreator,
 		server.ConstructAppExporter(exportAppStateAndTMValidators, "basecoin"))
+	rootCmd.AddCommand(server.MigrateDBCmd(ctx, appCreator, "basecoin"))
 
 	// prepare and add flags
 	rootDir := os.ExpandEnv("$HOME/.basecoind")
@@ -62,9 +62,11 @@ func main() {
 		PersistentPreRunE: server.PersistentPreRunEFn(ctx),
 	}
 
+	appCreator := server.ConstructAppCreator(newApp, "democoin")
 	server.AddCommands(ctx, cdc, rootCmd, CoolAppInit,
-		server.ConstructAppCreator(newApp, "democoin"),
+		appCreator,
 		server.ConstructAppExporter(exportAppStateAndTMValidators, "democoin"))
+	rootCmd.AddCommand(server.MigrateDBCmd(ctx, appCreator, "democoin"))
 
 	// prepare and add flags
 	rootDir := os.ExpandEnv("$HOME/.democoind")
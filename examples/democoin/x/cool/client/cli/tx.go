@@ -8,6 +8,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
 
@@ -35,8 +36,12 @@ func QuizTxCmd(cdc *wire.Codec) *cobra.Command {
 			// get account name
 			name := viper.GetString(client.FlagName)
 
+			if ctx.GenerateOnly {
+				return ctx.PrintUnsignedTx([]sdk.Msg{msg}, cdc)
+			}
+
 			// build and sign the transaction, then broadcast to Tendermint
-			res, err := ctx.EnsureSignBuildBroadcast(name, msg, cdc)
+			res, err := ctx.EnsureSignBuildBroadcast(name, []sdk.Msg{msg}, cdc)
 			if err != nil {
 				return err
 			}
@@ -68,8 +73,12 @@ func SetTrendTxCmd(cdc *wire.Codec) *cobra.Command {
 			// create the message
 			msg := cool.NewMsgSetTrend(from, args[0])
 
+			if ctx.GenerateOnly {
+				return ctx.PrintUnsignedTx([]sdk.Msg{msg}, cdc)
+			}
+
 			// build and sign the transaction, then broadcast to Tendermint
-			res, err := ctx.EnsureSignBuildBroadcast(name, msg, cdc)
+			res, err := ctx.EnsureSignBuildBroadcast(name, []sdk.Msg{msg}, cdc)
 			if err != nil {
 				return err
 			}
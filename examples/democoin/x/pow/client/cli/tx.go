@@ -9,6 +9,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/context"
 
 	"github.com/cosmos/cosmos-sdk/examples/democoin/x/pow"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
 )
@@ -47,8 +48,12 @@ func MineCmd(cdc *wire.Codec) *cobra.Command {
 			// get account name
 			name := ctx.FromAddressName
 
+			if ctx.GenerateOnly {
+				return ctx.PrintUnsignedTx([]sdk.Msg{msg}, cdc)
+			}
+
 			// build and sign the transaction, then broadcast to Tendermint
-			res, err := ctx.EnsureSignBuildBroadcast(name, msg, cdc)
+			res, err := ctx.EnsureSignBuildBroadcast(name, []sdk.Msg{msg}, cdc)
 			if err != nil {
 				return err
 			}
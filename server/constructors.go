@@ -2,7 +2,13 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 
 	abci "github.com/tendermint/abci/types"
 	tmtypes "github.com/tendermint/tendermint/types"
@@ -20,8 +26,7 @@ type AppExporter func(home string, log log.Logger) (json.RawMessage, []tmtypes.G
 // ConstructAppCreator returns an application generation function
 func ConstructAppCreator(appFn func(log.Logger, dbm.DB) abci.Application, name string) AppCreator {
 	return func(rootDir string, logger log.Logger) (abci.Application, error) {
-		dataDir := filepath.Join(rootDir, "data")
-		db, err := dbm.NewGoLevelDB(name, dataDir)
+		db, err := openAppDB(rootDir, name)
 		if err != nil {
 			return nil, err
 		}
@@ -33,11 +38,131 @@ func ConstructAppCreator(appFn func(log.Logger, dbm.DB) abci.Application, name s
 // ConstructAppExporter returns an application export function
 func ConstructAppExporter(appFn func(log.Logger, dbm.DB) (json.RawMessage, []tmtypes.GenesisValidator, error), name string) AppExporter {
 	return func(rootDir string, logger log.Logger) (json.RawMessage, []tmtypes.GenesisValidator, error) {
-		dataDir := filepath.Join(rootDir, "data")
-		db, err := dbm.NewGoLevelDB(name, dataDir)
+		db, err := openAppDB(rootDir, name)
 		if err != nil {
 			return nil, nil, err
 		}
 		return appFn(logger, db)
 	}
 }
+
+//___________________________________________________________________________________
+
+// The app db is opened with the db backend of the node config. With a db per
+// store, the app keeps each of its stores in a db of its own, in the stores
+// directory of the app, rather than under a prefix of the app db.
+const (
+	flagDBBackend  = "db_backend"
+	flagDBPerStore = "db_per_store"
+)
+
+// dbBackend returns the db backend of the node config
+func dbBackend() dbm.DBBackendType {
+	backend := viper.GetString(flagDBBackend)
+	if backend == "" {
+		return dbm.GoLevelDBBackend
+	}
+	return dbm.DBBackendType(backend)
+}
+
+// the directory of the dbs of an app with a db per store
+func appStoresDir(dataDir, name string) string {
+	return filepath.Join(dataDir, name+"-stores")
+}
+
+// openAppDB opens the db of the app in the data directory. An app which was
+// migrated to a db per store keeps one, a new app starts with one if the
+// node config asks for it.
+func openAppDB(rootDir, name string) (dbm.DB, error) {
+	dataDir := filepath.Join(rootDir, "data")
+	backend := dbBackend()
+	storesDir := appStoresDir(dataDir, name)
+	perStore, err := dirExists(storesDir)
+	if err != nil {
+		return nil, err
+	}
+	if !perStore && viper.GetBool(flagDBPerStore) {
+		exists, err := dirExists(filepath.Join(dataDir, name+".db"))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.Errorf("app db of %s exists, migrate it to a db per store with migrate-db", name)
+		}
+		perStore = true
+	}
+	if perStore {
+		return newStoreDBs(name, backend, storesDir)
+	}
+	return newDB(name, backend, dataDir)
+}
+
+// newDB opens a db, returning the errors dbm.NewDB panics with, like that of
+// an unknown backend
+func newDB(name string, backend dbm.DBBackendType, dir string) (db dbm.DB, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed to open %s db %s: %v", backend, name, r)
+		}
+	}()
+	return dbm.NewDB(name, backend, dir), nil
+}
+
+func dirExists(dir string) (bool, error) {
+	_, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// storeDBs is the db of an app with a db per store: the app db, which holds
+// the commit infos, and the dbs of the stores opened as BaseApp mounts them.
+type storeDBs struct {
+	dbm.DB
+	backend dbm.DBBackendType
+	dir     string
+
+	mtx sync.Mutex
+	dbs map[string]dbm.DB
+}
+
+func newStoreDBs(name string, backend dbm.DBBackendType, dir string) (*storeDBs, error) {
+	db, err := newDB(name, backend, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &storeDBs{
+		DB:      db,
+		backend: backend,
+		dir:     dir,
+		dbs:     make(map[string]dbm.DB),
+	}, nil
+}
+
+// StoreDB returns the db of the named store, opening it on first use.
+// Like the other methods of the db, it panics on failure.
+func (s *storeDBs) StoreDB(name string) dbm.DB {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	db, ok := s.dbs[name]
+	if !ok {
+		var err error
+		db, err = newDB(name, s.backend, filepath.Join(s.dir, "stores"))
+		if err != nil {
+			panic(err)
+		}
+		s.dbs[name] = db
+	}
+	return db
+}
+
+// Close closes the dbs of the stores and the app db
+func (s *storeDBs) Close() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, db := range s.dbs {
+		db.Close()
+	}
+	s.DB.Close()
+}
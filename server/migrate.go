@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	abci "github.com/tendermint/abci/types"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	"github.com/cosmos/cosmos-sdk/store"
+)
+
+const flagFromDBBackend = "from-db-backend"
+
+// MigrateDBCmd copies the db of the named app into a db per store, in the
+// db backend of the node config
+func MigrateDBCmd(ctx *Context, appCreator AppCreator, name string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-db",
+		Short: "Copy the app db into a db per store",
+		Long: `Copy the app db into a db per store, in the db backend of the node config.
+The app is then loaded from the copy, which must be at the same commit as the
+app db. The app db is left as is, and can be deleted once the copy is verified.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			home := viper.GetString("home")
+			dataDir := filepath.Join(home, "data")
+			storesDir := appStoresDir(dataDir, name)
+			exists, err := dirExists(storesDir)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return errors.Errorf("app db already migrated to %s", storesDir)
+			}
+
+			fromBackend := dbm.DBBackendType(viper.GetString(flagFromDBBackend))
+			if fromBackend == "" {
+				fromBackend = dbBackend()
+			}
+			commitID, err := migrateDB(dataDir, name, fromBackend, dbBackend())
+			if err != nil {
+				return errors.Errorf("Error migrating app db: %v\n", err)
+			}
+
+			// The app is loaded from the copy, now that it exists.
+			app, err := appCreator(home, ctx.Logger)
+			if err != nil {
+				return err
+			}
+			info := app.Info(abci.RequestInfo{})
+			if info.LastBlockHeight != commitID.Version || !bytes.Equal(info.LastBlockAppHash, commitID.Hash) {
+				os.RemoveAll(storesDir)
+				return errors.Errorf("Error migrating app db: copy is at height %d, hash %X, expected %v",
+					info.LastBlockHeight, info.LastBlockAppHash, commitID)
+			}
+			fmt.Printf("Migrated app db to %s at height %d\n", storesDir, commitID.Version)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagFromDBBackend, "", "Db backend of the app db, if not that of the node config")
+	return cmd
+}
+
+// migrateDB copies the app db into a db per store, in a temporary directory
+// which only becomes the stores directory once the copy is complete
+func migrateDB(dataDir, name string, fromBackend, toBackend dbm.DBBackendType) (store.CommitID, error) {
+	exists, err := dirExists(filepath.Join(dataDir, name+".db"))
+	if err != nil {
+		return store.CommitID{}, err
+	}
+	if !exists {
+		return store.CommitID{}, errors.Errorf("no app db %s in %s", name, dataDir)
+	}
+	src, err := newDB(name, fromBackend, dataDir)
+	if err != nil {
+		return store.CommitID{}, err
+	}
+	defer src.Close()
+
+	storesDir := appStoresDir(dataDir, name)
+	tmpDir := storesDir + ".tmp"
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return store.CommitID{}, err
+	}
+	dst, err := newStoreDBs(name, toBackend, tmpDir)
+	if err != nil {
+		return store.CommitID{}, err
+	}
+	commitID, err := store.MigrateToStoreDBs(src, dst.DB, dst.StoreDB)
+	dst.Close()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return store.CommitID{}, err
+	}
+	return commitID, os.Rename(tmpDir, storesDir)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/tmlibs/cli"
+	dbm "github.com/tendermint/tmlibs/db"
+	"github.com/tendermint/tmlibs/log"
+
+	bam "github.com/cosmos/cosmos-sdk/baseapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestMigrateDB(t *testing.T) {
+	defer setupViper(t)()
+	home := viper.GetString(cli.HomeFlag)
+	defer viper.Set(flagDBPerStore, false)
+
+	// the app writes a key of each store on InitChain
+	keys := []*sdk.KVStoreKey{sdk.NewKVStoreKey("main"), sdk.NewKVStoreKey("acc")}
+	var appDB dbm.DB
+	appCreator := ConstructAppCreator(func(logger log.Logger, db dbm.DB) abci.Application {
+		appDB = db
+		app := bam.NewBaseApp("test", nil, logger, db)
+		app.MountStoresIAVL(keys...)
+		app.SetInitChainer(func(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+			for _, key := range keys {
+				ctx.KVStore(key).Set([]byte("key"), []byte(key.Name()))
+			}
+			return abci.ResponseInitChain{}
+		})
+		if err := app.LoadLatestVersion(keys[0]); err != nil {
+			panic(err)
+		}
+		return app
+	}, "test")
+
+	app, err := appCreator(home, log.NewNopLogger())
+	require.Nil(t, err)
+	app.InitChain(abci.RequestInitChain{})
+	res := app.Commit()
+	appDB.Close()
+	_, isStoreDBs := appDB.(*storeDBs)
+	assert.False(t, isStoreDBs)
+
+	// an existing app db isn't replaced by a db per store
+	viper.Set(flagDBPerStore, true)
+	_, err = appCreator(home, log.NewNopLogger())
+	assert.NotNil(t, err)
+
+	cmd := MigrateDBCmd(NewDefaultContext(), appCreator, "test")
+	require.Nil(t, cmd.RunE(nil, nil))
+	appDB.Close()
+	for _, key := range keys {
+		_, err = os.Stat(filepath.Join(home, "data", "test-stores", "stores", key.Name()+".db"))
+		assert.Nil(t, err)
+	}
+
+	// the migrated app loads with a db per store, at the same commit
+	app, err = appCreator(home, log.NewNopLogger())
+	require.Nil(t, err)
+	info := app.Info(abci.RequestInfo{})
+	assert.Equal(t, int64(1), info.LastBlockHeight)
+	assert.Equal(t, res.Data, info.LastBlockAppHash)
+	_, isStoreDBs = appDB.(*storeDBs)
+	assert.True(t, isStoreDBs)
+	appDB.Close()
+
+	// an app db is migrated only once
+	assert.NotNil(t, cmd.RunE(nil, nil))
+}
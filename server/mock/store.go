@@ -46,11 +46,51 @@ func (ms multiStore) LoadVersion(ver int64) error {
 	panic("not implemented")
 }
 
+func (ms multiStore) SetPruning(opts sdk.PruningOptions) {
+	panic("not implemented")
+}
+
+func (ms multiStore) AddListeners(key sdk.StoreKey, listeners []sdk.WriteListener) {
+	panic("not implemented")
+}
+
+func (ms multiStore) CacheMultiStoreWithListeners() sdk.CacheMultiStore {
+	panic("not implemented")
+}
+
+func (ms multiStore) CacheMultiStoreWithVersion(ver int64) (sdk.CacheMultiStore, error) {
+	panic("not implemented")
+}
+
+func (ms multiStore) Snapshot(dir string, ver int64, chunkSize int) (sdk.SnapshotManifest, error) {
+	panic("not implemented")
+}
+
+func (ms multiStore) HoldVersion(ver int64) func() {
+	panic("not implemented")
+}
+
+func (ms multiStore) Restore(dir string, ver int64, appHash []byte) error {
+	panic("not implemented")
+}
+
+func (ms multiStore) Rollback(ver int64) error {
+	panic("not implemented")
+}
+
 func (ms multiStore) GetKVStore(key sdk.StoreKey) sdk.KVStore {
 	return ms.kv[key]
 }
 
-func (ms multiStore) GetKVStoreWithGas(meter sdk.GasMeter, key sdk.StoreKey) sdk.KVStore {
+func (ms multiStore) GetKVStoreWithGas(meter sdk.GasMeter, config sdk.GasConfig, key sdk.StoreKey) sdk.KVStore {
+	panic("not implemented")
+}
+
+func (ms multiStore) GetGasConfig(key sdk.StoreKey) sdk.GasConfig {
+	panic("not implemented")
+}
+
+func (ms multiStore) SetGasConfig(key sdk.StoreKey, config sdk.GasConfig) {
 	panic("not implemented")
 }
 
@@ -103,6 +143,10 @@ func (kv kvStore) ReverseIterator(start, end []byte) sdk.Iterator {
 	panic("not implemented")
 }
 
+func (kv kvStore) Prefix(prefix []byte) sdk.KVStore {
+	panic("not implemented")
+}
+
 func (kv kvStore) SubspaceIterator(prefix []byte) sdk.Iterator {
 	panic("not implemented")
 }
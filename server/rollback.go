@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const flagHeight = "height"
+
+// implemented by apps which can roll their state back, eg. BaseApp
+type rollbacker interface {
+	Rollback(height int64) error
+}
+
+// RollbackCmd rolls the app state back to a previous height
+func RollbackCmd(ctx *Context, appCreator AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll the app state back to a previous height",
+		Long: `Roll the app state back to a previous height, deleting the state of
+all later heights. Heights which were pruned can't be rolled back to.
+On the next start, tendermint replays the blocks after the height.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height := viper.GetInt64(flagHeight)
+			if height <= 0 {
+				return errors.Errorf("--%s must be positive", flagHeight)
+			}
+			app, err := appCreator(viper.GetString("home"), ctx.Logger)
+			if err != nil {
+				return err
+			}
+			r, ok := app.(rollbacker)
+			if !ok {
+				return errors.New("app does not support rollbacks")
+			}
+			err = r.Rollback(height)
+			if err != nil {
+				return errors.Errorf("Error rolling back: %v\n", err)
+			}
+			fmt.Printf("Rolled app state back to height %d\n", height)
+			return nil
+		},
+	}
+	cmd.Flags().Int64(flagHeight, 0, "Height to roll back to")
+	return cmd
+}
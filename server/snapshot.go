@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	abci "github.com/tendermint/abci/types"
+)
+
+const (
+	flagSnapshotInterval = "snapshot-interval"
+	flagAppHash          = "app-hash"
+)
+
+// implemented by apps which can snapshot their state, eg. BaseApp
+type snapshotter interface {
+	SetSnapshotOptions(dir string, interval int64)
+	RestoreSnapshot(height int64, appHash []byte) error
+}
+
+// snapshots are kept in the data directory of the node
+func snapshotDir(home string) string {
+	return filepath.Join(home, "data", "snapshots")
+}
+
+// apply the snapshot interval flag to the app
+func setSnapshotOptions(app abci.Application, home string) error {
+	interval := viper.GetInt64(flagSnapshotInterval)
+	if interval == 0 {
+		return nil
+	}
+	if interval < 0 {
+		return errors.Errorf("--%s must not be negative", flagSnapshotInterval)
+	}
+	s, ok := app.(snapshotter)
+	if !ok {
+		return errors.Errorf("app does not support --%s", flagSnapshotInterval)
+	}
+	s.SetSnapshotOptions(snapshotDir(home), interval)
+	return nil
+}
+
+// RestoreSnapshotCmd restores the app state from a snapshot
+func RestoreSnapshotCmd(ctx *Context, appCreator AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore-snapshot [height]",
+		Short: "Restore the app state from the snapshot of a height",
+		Long: `Restore the app state from the snapshot of a height, found in the
+data/snapshots directory. The app state must be empty. The restored state
+is verified against --app-hash, the app hash of the height as found in the
+header of the next block from a trusted source, before it is written.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return errors.Errorf("Invalid height %s: %v\n", args[0], err)
+			}
+			appHash, err := hex.DecodeString(viper.GetString(flagAppHash))
+			if err != nil || len(appHash) == 0 {
+				return errors.Errorf("--%s must be the hex app hash of height %d", flagAppHash, height)
+			}
+			home := viper.GetString("home")
+			app, err := appCreator(home, ctx.Logger)
+			if err != nil {
+				return err
+			}
+			s, ok := app.(snapshotter)
+			if !ok {
+				return errors.New("app does not support snapshots")
+			}
+			s.SetSnapshotOptions(snapshotDir(home), 0)
+			err = s.RestoreSnapshot(height, appHash)
+			if err != nil {
+				return errors.Errorf("Error restoring snapshot: %v\n", err)
+			}
+			fmt.Printf("Restored app state at height %d\n", height)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagAppHash, "", "Trusted app hash of the height, in hex")
+	return cmd
+}
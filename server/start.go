@@ -6,17 +6,23 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/tendermint/abci/server"
+	abci "github.com/tendermint/abci/types"
 
 	tcmd "github.com/tendermint/tendermint/cmd/tendermint/commands"
 	"github.com/tendermint/tendermint/node"
 	"github.com/tendermint/tendermint/proxy"
 	pvm "github.com/tendermint/tendermint/privval"
 	cmn "github.com/tendermint/tmlibs/common"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 const (
 	flagWithTendermint = "with-tendermint"
 	flagAddress        = "address"
+
+	flagMinimumGasPrices = "minimum-gas-prices"
+	flagPruning          = "pruning"
 )
 
 // StartCmd runs the service passed in, either
@@ -38,6 +44,9 @@ func StartCmd(ctx *Context, appCreator AppCreator) *cobra.Command {
 	// basic flags for abci app
 	cmd.Flags().Bool(flagWithTendermint, true, "run abci app embedded in-process with tendermint")
 	cmd.Flags().String(flagAddress, "tcp://0.0.0.0:46658", "Listen address")
+	cmd.Flags().String(flagMinimumGasPrices, "", "Minimum gas prices for txs to enter the mempool, eg. 0.025steak,1photino")
+	cmd.Flags().String(flagPruning, "", "Pruning strategy of the app state: nothing (default), everything, or keep-every-N-plus-recent-M")
+	cmd.Flags().Int64(flagSnapshotInterval, 0, "Snapshot the app state every this many blocks, never if 0")
 
 	// AddNodeFlags adds support for all tendermint-specific command line options
 	tcmd.AddNodeFlags(cmd)
@@ -52,6 +61,18 @@ func startStandAlone(ctx *Context, appCreator AppCreator) error {
 	if err != nil {
 		return err
 	}
+	err = setMinimumGasPrices(app)
+	if err != nil {
+		return err
+	}
+	err = setSnapshotOptions(app, home)
+	if err != nil {
+		return err
+	}
+	err = setPruning(app)
+	if err != nil {
+		return err
+	}
 
 	svr, err := server.NewServer(addr, "socket", app)
 	if err != nil {
@@ -75,6 +96,18 @@ func startInProcess(ctx *Context, appCreator AppCreator) error {
 	if err != nil {
 		return err
 	}
+	err = setMinimumGasPrices(app)
+	if err != nil {
+		return err
+	}
+	err = setSnapshotOptions(app, home)
+	if err != nil {
+		return err
+	}
+	err = setPruning(app)
+	if err != nil {
+		return err
+	}
 
 	// Create & start tendermint node
 	n, err := node.NewNode(cfg,
@@ -96,3 +129,48 @@ func startInProcess(ctx *Context, appCreator AppCreator) error {
 	n.RunForever()
 	return nil
 }
+
+// implemented by apps accepting node-local minimum gas prices, eg. BaseApp
+type minimumGasPricesSetter interface {
+	SetMinimumGasPrices(prices sdk.GasPrices)
+}
+
+// apply the minimum gas prices flag to the app
+func setMinimumGasPrices(app abci.Application) error {
+	prices, err := sdk.ParseGasPrices(viper.GetString(flagMinimumGasPrices))
+	if err != nil {
+		return err
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+	setter, ok := app.(minimumGasPricesSetter)
+	if !ok {
+		return errors.Errorf("app does not support --%s", flagMinimumGasPrices)
+	}
+	setter.SetMinimumGasPrices(prices)
+	return nil
+}
+
+// implemented by apps which prune their state, eg. BaseApp
+type pruningSetter interface {
+	SetPruning(opts sdk.PruningOptions)
+}
+
+// apply the pruning flag to the app
+func setPruning(app abci.Application) error {
+	strategy := viper.GetString(flagPruning)
+	if strategy == "" {
+		return nil
+	}
+	opts, err := sdk.ParsePruningOptions(strategy)
+	if err != nil {
+		return err
+	}
+	setter, ok := app.(pruningSetter)
+	if !ok {
+		return errors.Errorf("app does not support --%s", flagPruning)
+	}
+	setter.SetPruning(opts)
+	return nil
+}
@@ -87,6 +87,8 @@ func AddCommands(
 		InitCmd(ctx, cdc, appInit),
 		StartCmd(ctx, appCreator),
 		UnsafeResetAllCmd(ctx),
+		RestoreSnapshotCmd(ctx, appCreator),
+		RollbackCmd(ctx, appCreator),
 		client.LineBreak,
 		tendermintCmd,
 		ExportCmd(ctx, cdc, appExport),
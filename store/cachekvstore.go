@@ -1,8 +1,6 @@
 package store
 
 import (
-	"bytes"
-	"sort"
 	"sync"
 
 	cmn "github.com/tendermint/tmlibs/common"
@@ -18,9 +16,14 @@ type cValue struct {
 
 // cacheKVStore wraps an in-memory cache around an underlying KVStore.
 type cacheKVStore struct {
-	mtx    sync.Mutex
-	cache  map[string]cValue
-	parent KVStore
+	mtx       sync.Mutex
+	cache     map[string]cValue
+	dirtyKeys sortedKeys // the keys of the dirty values, sorted
+	parent    KVStore
+
+	// Notified of the writes to the cache, if any
+	storeKey  StoreKey
+	listeners []WriteListener
 }
 
 var _ CacheKVStore = (*cacheKVStore)(nil)
@@ -63,6 +66,7 @@ func (ci *cacheKVStore) Set(key []byte, value []byte) {
 	ci.assertValidKey(key)
 
 	ci.setCacheValue(key, value, false, true)
+	ci.onWrite(key, value, false)
 }
 
 // Implements KVStore.
@@ -78,6 +82,7 @@ func (ci *cacheKVStore) Delete(key []byte) {
 	ci.assertValidKey(key)
 
 	ci.setCacheValue(key, nil, true, true)
+	ci.onWrite(key, nil, true)
 }
 
 // Implements CacheKVStore.
@@ -85,19 +90,9 @@ func (ci *cacheKVStore) Write() {
 	ci.mtx.Lock()
 	defer ci.mtx.Unlock()
 
-	// We need a copy of all of the keys.
-	// Not the best, but probably not a bottleneck depending.
-	keys := make([]string, 0, len(ci.cache))
-	for key, dbValue := range ci.cache {
-		if dbValue.dirty {
-			keys = append(keys, key)
-		}
-	}
-	sort.Strings(keys)
-
 	// TODO: Consider allowing usage of Batch, which would allow the write to
 	// at least happen atomically.
-	for _, key := range keys {
+	for _, key := range ci.dirtyKeys.keys(nil, nil) {
 		cacheValue := ci.cache[key]
 		if cacheValue.deleted {
 			ci.parent.Delete([]byte(key))
@@ -110,6 +105,20 @@ func (ci *cacheKVStore) Write() {
 
 	// Clear the cache
 	ci.cache = make(map[string]cValue)
+	ci.dirtyKeys = sortedKeys{}
+}
+
+// setListeners makes the listeners notified of the writes to the cache
+func (ci *cacheKVStore) setListeners(storeKey StoreKey, listeners []WriteListener) {
+	ci.storeKey = storeKey
+	ci.listeners = listeners
+}
+
+// Only call with lock.
+func (ci *cacheKVStore) onWrite(key, value []byte, deleted bool) {
+	for _, listener := range ci.listeners {
+		listener.OnWrite(ci.storeKey, key, value, deleted)
+	}
 }
 
 //----------------------------------------
@@ -120,6 +129,11 @@ func (ci *cacheKVStore) CacheWrap() CacheWrap {
 	return NewCacheKVStore(ci)
 }
 
+// Implements KVStore.
+func (ci *cacheKVStore) Prefix(prefix []byte) KVStore {
+	return NewPrefixStore(ci, prefix)
+}
+
 //----------------------------------------
 // Iteration
 
@@ -134,33 +148,35 @@ func (ci *cacheKVStore) ReverseIterator(start, end []byte) Iterator {
 }
 
 func (ci *cacheKVStore) iterator(start, end []byte, ascending bool) Iterator {
+	ci.mtx.Lock()
+	defer ci.mtx.Unlock()
+
 	var parent, cache Iterator
 	if ascending {
 		parent = ci.parent.Iterator(start, end)
 	} else {
 		parent = ci.parent.ReverseIterator(start, end)
 	}
-	items := ci.dirtyItems(ascending)
+	items := ci.dirtyItems(start, end, ascending)
 	cache = newMemIterator(start, end, items)
 	return newCacheMergeIterator(parent, cache, ascending)
 }
 
-// Constructs a slice of dirty items, to use w/ memIterator.
-func (ci *cacheKVStore) dirtyItems(ascending bool) []cmn.KVPair {
-	items := make([]cmn.KVPair, 0, len(ci.cache))
-	for key, cacheValue := range ci.cache {
-		if !cacheValue.dirty {
-			continue
-		}
-		items = append(items,
-			cmn.KVPair{[]byte(key), cacheValue.value})
+// Constructs a slice of the dirty items between start and end, to use w/
+// memIterator, which keeps those of its domain. Only call with lock.
+func (ci *cacheKVStore) dirtyItems(start, end []byte, ascending bool) []cmn.KVPair {
+	// Take the keys between the bounds, whichever comes first.
+	if keyCompare(start, end) >= 0 {
+		start, end = end, start
 	}
-	sort.Slice(items, func(i, j int) bool {
-		if ascending {
-			return bytes.Compare(items[i].Key, items[j].Key) < 0
+	keys := ci.dirtyKeys.keys(start, end)
+	items := make([]cmn.KVPair, len(keys))
+	for i, key := range keys {
+		if !ascending {
+			i = len(keys) - 1 - i
 		}
-		return bytes.Compare(items[i].Key, items[j].Key) > 0
-	})
+		items[i] = cmn.KVPair{Key: []byte(key), Value: ci.cache[key].value}
+	}
 	return items
 }
 
@@ -180,5 +196,8 @@ func (ci *cacheKVStore) setCacheValue(key, value []byte, deleted bool, dirty boo
 		deleted: deleted,
 		dirty:   dirty,
 	}
+	if dirty && !ci.cache[string(key)].dirty {
+		ci.dirtyKeys.add(string(key))
+	}
 	ci.cache[string(key)] = cacheValue
 }
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"bytes"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -294,6 +296,23 @@ func TestCacheKVMergeIteratorRandom(t *testing.T) {
 	}
 }
 
+func TestCacheKVMergeIteratorRandomRanges(t *testing.T) {
+	st := newCacheKVStore()
+	truth := dbm.NewMemDB()
+
+	// more keys than fit in a chunk of the sorted dirty keys
+	max := 3 * maxSortedKeysChunk
+	setRange(st, truth, 0, max/2)
+	st.Write()
+
+	// do an op, test iterators over a random range both ways
+	for i := 0; i < 500; i++ {
+		doRandomOp(st, truth, max)
+		start, end := randRange(max)
+		assertIterateRangeCompare(t, st, truth, start, end)
+	}
+}
+
 //-------------------------------------------------------------------------------------------
 // do some random ops
 
@@ -412,6 +431,25 @@ func assertIterateDomainCompare(t *testing.T, st KVStore, mem dbm.DB) {
 	checkIterators(t, itr2, itr)
 }
 
+func assertIterateRangeCompare(t *testing.T, st KVStore, mem dbm.DB, start, end []byte) {
+	checkIterators(t, st.Iterator(start, end), mem.Iterator(start, end))
+	checkIterators(t, st.ReverseIterator(start, end), mem.ReverseIterator(start, end))
+}
+
+// a random range of keys, left open on either side at times
+func randRange(maxKey int) (start, end []byte) {
+	if randInt(4) > 0 {
+		start = keyFmt(randInt(maxKey))
+	}
+	if randInt(4) > 0 {
+		end = keyFmt(randInt(maxKey))
+	}
+	if start != nil && end != nil && bytes.Compare(start, end) > 0 {
+		start, end = end, start
+	}
+	return
+}
+
 func checkIterators(t *testing.T, itr, itr2 Iterator) {
 	for ; itr.Valid(); itr.Next() {
 		assert.True(t, itr2.Valid())
@@ -494,3 +532,66 @@ func (krc *keyRangeCounter) key() int {
 //--------------------------------------------------------
 
 func bz(s string) []byte { return []byte(s) }
+
+//--------------------------------------------------------
+// benchmarks
+
+// Iterating a few keys after each write, among many dirty keys, as a tx
+// iterating a prefix of a store it writes to.
+func BenchmarkCacheKVStoreSetIterate(b *testing.B) {
+	st := newCacheKVStore()
+	for i := 0; i < 10000; i++ {
+		st.Set(keyFmt(i), valFmt(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := 10000 + i%10000
+		st.Set(keyFmt(k), valFmt(k))
+		itr := st.Iterator(keyFmt(k-10), keyFmt(k))
+		for ; itr.Valid(); itr.Next() {
+		}
+		itr.Close()
+	}
+}
+
+func BenchmarkCacheKVStoreDirtyItems(b *testing.B) {
+	benchmarkDirtyItems(b, func(ci *cacheKVStore, start, end []byte) []cmn.KVPair {
+		return ci.dirtyItems(start, end, true)
+	})
+}
+
+func BenchmarkCacheKVStoreDirtyItemsResort(b *testing.B) {
+	benchmarkDirtyItems(b, resortDirtyItems)
+}
+
+// the dirty items of 10 keys among 10000
+func benchmarkDirtyItems(b *testing.B, dirtyItems func(ci *cacheKVStore, start, end []byte) []cmn.KVPair) {
+	ci := newCacheKVStore().(*cacheKVStore)
+	for i := 0; i < 10000; i++ {
+		ci.Set(keyFmt(i), valFmt(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := i % 9990
+		items := newMemIterator(keyFmt(k), keyFmt(k+10), dirtyItems(ci, keyFmt(k), keyFmt(k+10))).items
+		if len(items) != 10 {
+			b.Fatalf("got %d items", len(items))
+		}
+	}
+}
+
+// resortDirtyItems sorts all the dirty items of the cache, as the cache did
+// before it kept its dirty keys sorted
+func resortDirtyItems(ci *cacheKVStore, start, end []byte) []cmn.KVPair {
+	items := make([]cmn.KVPair, 0, len(ci.cache))
+	for key, cacheValue := range ci.cache {
+		if !cacheValue.dirty {
+			continue
+		}
+		items = append(items, cmn.KVPair{Key: []byte(key), Value: cacheValue.value})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return bytes.Compare(items[i].Key, items[j].Key) < 0
+	})
+	return items
+}
@@ -1,6 +1,10 @@
 package store
 
 import (
+	"sort"
+
+	dbm "github.com/tendermint/tmlibs/db"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
@@ -13,17 +17,25 @@ type cacheMultiStore struct {
 	db         CacheKVStore
 	stores     map[StoreKey]CacheWrap
 	keysByName map[string]StoreKey
+	gasConfigs map[StoreKey]GasConfig
 }
 
 var _ CacheMultiStore = cacheMultiStore{}
 
 func newCacheMultiStoreFromRMS(rms *rootMultiStore) cacheMultiStore {
+	return newCacheMultiStoreFromStores(rms.db, rms.stores, rms.keysByName, rms.gasConfigs)
+}
+
+func newCacheMultiStoreFromStores(db dbm.DB, stores map[StoreKey]CommitStore,
+	keysByName map[string]StoreKey, gasConfigs map[StoreKey]GasConfig) cacheMultiStore {
+
 	cms := cacheMultiStore{
-		db:         NewCacheKVStore(dbStoreAdapter{rms.db}),
-		stores:     make(map[StoreKey]CacheWrap, len(rms.stores)),
-		keysByName: rms.keysByName,
+		db:         NewCacheKVStore(dbStoreAdapter{db}),
+		stores:     make(map[StoreKey]CacheWrap, len(stores)),
+		keysByName: keysByName,
+		gasConfigs: gasConfigs,
 	}
-	for key, store := range rms.stores {
+	for key, store := range stores {
 		cms.stores[key] = store.CacheWrap()
 	}
 	return cms
@@ -31,8 +43,10 @@ func newCacheMultiStoreFromRMS(rms *rootMultiStore) cacheMultiStore {
 
 func newCacheMultiStoreFromCMS(cms cacheMultiStore) cacheMultiStore {
 	cms2 := cacheMultiStore{
-		db:     NewCacheKVStore(cms.db),
-		stores: make(map[StoreKey]CacheWrap, len(cms.stores)),
+		db:         NewCacheKVStore(cms.db),
+		stores:     make(map[StoreKey]CacheWrap, len(cms.stores)),
+		keysByName: cms.keysByName,
+		gasConfigs: cms.gasConfigs,
 	}
 	for key, store := range cms.stores {
 		cms2.stores[key] = store.CacheWrap()
@@ -46,10 +60,17 @@ func (cms cacheMultiStore) GetStoreType() StoreType {
 }
 
 // Implements CacheMultiStore.
+// The stores are written in the order of their names, so that the writes
+// reach the parent stores, and their listeners, deterministically.
 func (cms cacheMultiStore) Write() {
 	cms.db.Write()
-	for _, store := range cms.stores {
-		store.Write()
+	keys := make([]StoreKey, 0, len(cms.stores))
+	for key := range cms.stores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name() < keys[j].Name() })
+	for _, key := range keys {
+		cms.stores[key].Write()
 	}
 }
 
@@ -74,6 +95,11 @@ func (cms cacheMultiStore) GetKVStore(key StoreKey) KVStore {
 }
 
 // Implements MultiStore.
-func (cms cacheMultiStore) GetKVStoreWithGas(meter sdk.GasMeter, key StoreKey) KVStore {
-	return NewGasKVStore(meter, cms.GetKVStore(key))
+func (cms cacheMultiStore) GetKVStoreWithGas(meter sdk.GasMeter, config GasConfig, key StoreKey) KVStore {
+	return NewGasKVStore(meter, config, cms.GetKVStore(key))
+}
+
+// Implements MultiStore.
+func (cms cacheMultiStore) GetGasConfig(key StoreKey) GasConfig {
+	return getGasConfig(cms.gasConfigs, key)
 }
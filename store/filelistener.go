@@ -0,0 +1,88 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+var _ WriteListener = (*FileListener)(nil)
+
+// FileListener is a WriteListener appending the writes it is notified of to
+// a file, one JSON object per line, so that an indexer can follow the state
+// without executing the transactions:
+//
+//	{"store_key":"acc","key":"<base64>","value":"<base64>"}
+//	{"store_key":"acc","key":"<base64>","deleted":true}
+//	{"commit":{"Version":3,"Hash":"<base64>"}}
+//
+// The writes before a commit line are those of the committed version.  The
+// file is synced on every commit.
+type FileListener struct {
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+}
+
+// fileListenerLine is a line of the file of a FileListener
+type fileListenerLine struct {
+	StoreKey string    `json:"store_key,omitempty"`
+	Key      []byte    `json:"key,omitempty"`
+	Value    []byte    `json:"value,omitempty"`
+	Deleted  bool      `json:"deleted,omitempty"`
+	Commit   *CommitID `json:"commit,omitempty"`
+}
+
+// NewFileListener returns a FileListener appending to the file at path,
+// which is created if it doesn't exist
+func NewFileListener(path string) (*FileListener, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	return &FileListener{
+		file: file,
+		w:    w,
+		enc:  json.NewEncoder(w),
+	}, nil
+}
+
+// Implements WriteListener.
+func (fl *FileListener) OnWrite(storeKey StoreKey, key, value []byte, deleted bool) {
+	fl.writeLine(fileListenerLine{
+		StoreKey: storeKey.Name(),
+		Key:      key,
+		Value:    value,
+		Deleted:  deleted,
+	})
+}
+
+// Implements WriteListener.
+func (fl *FileListener) OnCommit(commitID CommitID) {
+	fl.writeLine(fileListenerLine{Commit: &commitID})
+	if err := fl.w.Flush(); err != nil {
+		panic(fmt.Sprintf("Failed to write to %s: %v", fl.file.Name(), err))
+	}
+	if err := fl.file.Sync(); err != nil {
+		panic(fmt.Sprintf("Failed to sync %s: %v", fl.file.Name(), err))
+	}
+}
+
+// A listener can't fail the write it is notified of, and skipping a line
+// would silently corrupt the stream, so errors panic like those of the dbs.
+func (fl *FileListener) writeLine(line fileListenerLine) {
+	if err := fl.enc.Encode(line); err != nil {
+		panic(fmt.Sprintf("Failed to write to %s: %v", fl.file.Name(), err))
+	}
+}
+
+// Close flushes and closes the file
+func (fl *FileListener) Close() error {
+	if err := fl.w.Flush(); err != nil {
+		fl.file.Close()
+		return err
+	}
+	return fl.file.Close()
+}
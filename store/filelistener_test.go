@@ -0,0 +1,53 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestFileListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filelistener")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "writes.jsonl")
+
+	key := sdk.NewKVStoreKey("acc")
+	fl, err := NewFileListener(path)
+	require.Nil(t, err)
+	fl.OnWrite(key, []byte("k1"), []byte("v1"), false)
+	fl.OnWrite(key, []byte("k2"), nil, true)
+	commitID := CommitID{Version: 1, Hash: []byte("hash")}
+	fl.OnCommit(commitID)
+	require.Nil(t, fl.Close())
+
+	// reopening appends
+	fl, err = NewFileListener(path)
+	require.Nil(t, err)
+	fl.OnWrite(key, []byte("k3"), []byte("v3"), false)
+	require.Nil(t, fl.Close())
+
+	bz, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(bz)), "\n")
+	require.Equal(t, 4, len(lines))
+	expected := []fileListenerLine{
+		{StoreKey: "acc", Key: []byte("k1"), Value: []byte("v1")},
+		{StoreKey: "acc", Key: []byte("k2"), Deleted: true},
+		{Commit: &commitID},
+		{StoreKey: "acc", Key: []byte("k3"), Value: []byte("v3")},
+	}
+	for i, line := range lines {
+		var got fileListenerLine
+		require.Nil(t, json.Unmarshal([]byte(line), &got))
+		assert.Equal(t, expected[i], got)
+	}
+}
@@ -4,29 +4,31 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-// nolint
+// Descriptors of the gas consumed by a gasKVStore, reported by out-of-gas
+// errors.
 const (
-	HasCost          = 10
-	ReadCostFlat     = 10
-	ReadCostPerByte  = 1
-	WriteCostFlat    = 10
-	WriteCostPerByte = 10
-	KeyCostFlat      = 5
-	ValueCostFlat    = 10
-	ValueCostPerByte = 1
+	GasHasDesc          = "Has"
+	GasDeleteDesc       = "Delete"
+	GasReadFlatDesc     = "ReadFlat"
+	GasReadPerByteDesc  = "ReadPerByte"
+	GasWriteFlatDesc    = "WriteFlat"
+	GasWritePerByteDesc = "WritePerByte"
+	GasIterNextFlatDesc = "IterNextFlat"
 )
 
 // gasKVStore applies gas tracking to an underlying kvstore
 type gasKVStore struct {
-	gasMeter sdk.GasMeter
-	parent   sdk.KVStore
+	gasMeter  sdk.GasMeter
+	gasConfig sdk.GasConfig
+	parent    sdk.KVStore
 }
 
 // nolint
-func NewGasKVStore(gasMeter sdk.GasMeter, parent sdk.KVStore) *gasKVStore {
+func NewGasKVStore(gasMeter sdk.GasMeter, gasConfig sdk.GasConfig, parent sdk.KVStore) *gasKVStore {
 	kvs := &gasKVStore{
-		gasMeter: gasMeter,
-		parent:   parent,
+		gasMeter:  gasMeter,
+		gasConfig: gasConfig,
+		parent:    parent,
 	}
 	return kvs
 }
@@ -38,33 +40,38 @@ func (gi *gasKVStore) GetStoreType() sdk.StoreType {
 
 // Implements KVStore.
 func (gi *gasKVStore) Get(key []byte) (value []byte) {
-	gi.gasMeter.ConsumeGas(ReadCostFlat, "GetFlat")
+	gi.gasMeter.ConsumeGas(gi.gasConfig.ReadCostFlat, GasReadFlatDesc)
 	value = gi.parent.Get(key)
 	// TODO overflow-safe math?
-	gi.gasMeter.ConsumeGas(ReadCostPerByte*sdk.Gas(len(value)), "ReadPerByte")
+	gi.gasMeter.ConsumeGas(gi.gasConfig.ReadCostPerByte*sdk.Gas(len(key)+len(value)), GasReadPerByteDesc)
 	return value
 }
 
 // Implements KVStore.
 func (gi *gasKVStore) Set(key []byte, value []byte) {
-	gi.gasMeter.ConsumeGas(WriteCostFlat, "SetFlat")
+	gi.gasMeter.ConsumeGas(gi.gasConfig.WriteCostFlat, GasWriteFlatDesc)
 	// TODO overflow-safe math?
-	gi.gasMeter.ConsumeGas(WriteCostPerByte*sdk.Gas(len(value)), "SetPerByte")
+	gi.gasMeter.ConsumeGas(gi.gasConfig.WriteCostPerByte*sdk.Gas(len(key)+len(value)), GasWritePerByteDesc)
 	gi.parent.Set(key, value)
 }
 
 // Implements KVStore.
 func (gi *gasKVStore) Has(key []byte) bool {
-	gi.gasMeter.ConsumeGas(HasCost, "Has")
+	gi.gasMeter.ConsumeGas(gi.gasConfig.HasCost, GasHasDesc)
 	return gi.parent.Has(key)
 }
 
 // Implements KVStore.
 func (gi *gasKVStore) Delete(key []byte) {
-	// No gas costs for deletion
+	gi.gasMeter.ConsumeGas(gi.gasConfig.DeleteCost, GasDeleteDesc)
 	gi.parent.Delete(key)
 }
 
+// Implements KVStore.
+func (gi *gasKVStore) Prefix(prefix []byte) sdk.KVStore {
+	return NewPrefixStore(gi, prefix)
+}
+
 // Implements KVStore.
 func (gi *gasKVStore) Iterator(start, end []byte) sdk.Iterator {
 	return gi.iterator(start, end, true)
@@ -87,18 +94,25 @@ func (gi *gasKVStore) iterator(start, end []byte, ascending bool) sdk.Iterator {
 	} else {
 		parent = gi.parent.ReverseIterator(start, end)
 	}
-	return newGasIterator(gi.gasMeter, parent)
+	return newGasIterator(gi.gasMeter, gi.gasConfig, parent)
 }
 
+// gasIterator charges the bytes of the key and the value of every entry it
+// reaches, once, when the entry is first looked at by Valid, Key or Value:
+// a scan is charged for what it seeks through even if it never reads the
+// keys or values.
 type gasIterator struct {
-	gasMeter sdk.GasMeter
-	parent   sdk.Iterator
+	gasMeter  sdk.GasMeter
+	gasConfig sdk.GasConfig
+	parent    sdk.Iterator
+	charged   bool // whether the current entry was charged
 }
 
-func newGasIterator(gasMeter sdk.GasMeter, parent sdk.Iterator) sdk.Iterator {
+func newGasIterator(gasMeter sdk.GasMeter, gasConfig sdk.GasConfig, parent sdk.Iterator) sdk.Iterator {
 	return &gasIterator{
-		gasMeter: gasMeter,
-		parent:   parent,
+		gasMeter:  gasMeter,
+		gasConfig: gasConfig,
+		parent:    parent,
 	}
 }
 
@@ -109,27 +123,33 @@ func (g *gasIterator) Domain() (start []byte, end []byte) {
 
 // Implements Iterator.
 func (g *gasIterator) Valid() bool {
-	return g.parent.Valid()
+	valid := g.parent.Valid()
+	if valid && !g.charged {
+		g.charged = true
+		size := len(g.parent.Key()) + len(g.parent.Value())
+		// TODO overflow-safe math?
+		g.gasMeter.ConsumeGas(g.gasConfig.ReadCostPerByte*sdk.Gas(size), GasReadPerByteDesc)
+	}
+	return valid
 }
 
 // Implements Iterator.
 func (g *gasIterator) Next() {
 	g.parent.Next()
+	g.charged = false
+	g.gasMeter.ConsumeGas(g.gasConfig.IterNextCostFlat, GasIterNextFlatDesc)
 }
 
 // Implements Iterator.
 func (g *gasIterator) Key() (key []byte) {
-	g.gasMeter.ConsumeGas(KeyCostFlat, "KeyFlat")
-	key = g.parent.Key()
-	return key
+	g.Valid()
+	return g.parent.Key()
 }
 
 // Implements Iterator.
 func (g *gasIterator) Value() (value []byte) {
-	value = g.parent.Value()
-	g.gasMeter.ConsumeGas(ValueCostFlat, "ValueFlat")
-	g.gasMeter.ConsumeGas(ValueCostPerByte*sdk.Gas(len(value)), "ValuePerByte")
-	return value
+	g.Valid()
+	return g.parent.Value()
 }
 
 // Implements Iterator.
@@ -11,25 +11,25 @@ import (
 func newGasKVStore() KVStore {
 	meter := sdk.NewGasMeter(1000)
 	mem := dbStoreAdapter{dbm.NewMemDB()}
-	return NewGasKVStore(meter, mem)
+	return NewGasKVStore(meter, sdk.KVGasConfig(), mem)
 }
 
 func TestGasKVStoreBasic(t *testing.T) {
 	mem := dbStoreAdapter{dbm.NewMemDB()}
 	meter := sdk.NewGasMeter(1000)
-	st := NewGasKVStore(meter, mem)
+	st := NewGasKVStore(meter, sdk.KVGasConfig(), mem)
 	require.Empty(t, st.Get(keyFmt(1)), "Expected `key1` to be empty")
 	st.Set(keyFmt(1), valFmt(1))
 	require.Equal(t, valFmt(1), st.Get(keyFmt(1)))
 	st.Delete(keyFmt(1))
 	require.Empty(t, st.Get(keyFmt(1)), "Expected `key1` to be empty")
-	require.Equal(t, meter.GasConsumed(), sdk.Gas(183))
+	require.Equal(t, meter.GasConsumed(), sdk.Gas(336))
 }
 
 func TestGasKVStoreIterator(t *testing.T) {
 	mem := dbStoreAdapter{dbm.NewMemDB()}
 	meter := sdk.NewGasMeter(1000)
-	st := NewGasKVStore(meter, mem)
+	st := NewGasKVStore(meter, sdk.KVGasConfig(), mem)
 	require.Empty(t, st.Get(keyFmt(1)), "Expected `key1` to be empty")
 	require.Empty(t, st.Get(keyFmt(2)), "Expected `key2` to be empty")
 	st.Set(keyFmt(1), valFmt(1))
@@ -47,22 +47,83 @@ func TestGasKVStoreIterator(t *testing.T) {
 	iterator.Next()
 	require.False(t, iterator.Valid())
 	require.Panics(t, iterator.Next)
-	require.Equal(t, meter.GasConsumed(), sdk.Gas(356))
+	require.Equal(t, meter.GasConsumed(), sdk.Gas(620))
 }
 
 func TestGasKVStoreOutOfGasSet(t *testing.T) {
 	mem := dbStoreAdapter{dbm.NewMemDB()}
 	meter := sdk.NewGasMeter(0)
-	st := NewGasKVStore(meter, mem)
+	st := NewGasKVStore(meter, sdk.KVGasConfig(), mem)
 	require.Panics(t, func() { st.Set(keyFmt(1), valFmt(1)) }, "Expected out-of-gas")
 }
 
 func TestGasKVStoreOutOfGasIterator(t *testing.T) {
 	mem := dbStoreAdapter{dbm.NewMemDB()}
-	meter := sdk.NewGasMeter(200)
-	st := NewGasKVStore(meter, mem)
+	meter := sdk.NewGasMeter(270)
+	st := NewGasKVStore(meter, sdk.KVGasConfig(), mem)
 	st.Set(keyFmt(1), valFmt(1))
 	iterator := st.Iterator(nil, nil)
-	iterator.Next()
-	require.Panics(t, func() { iterator.Value() }, "Expected out-of-gas")
+	require.Panics(t, func() { iterator.Valid() }, "Expected out-of-gas")
+}
+
+func TestGasKVStoreIteratorSeek(t *testing.T) {
+	mem := dbStoreAdapter{dbm.NewMemDB()}
+	for i := 0; i < 10; i++ {
+		mem.Set(keyFmt(i), valFmt(i))
+	}
+	meter := sdk.NewInfiniteGasMeter()
+	st := NewGasKVStore(meter, sdk.KVGasConfig(), mem)
+
+	// a scan which reads nothing pays for the entries it seeks through
+	iterator := st.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+	}
+	iterator.Close()
+	require.Equal(t, sdk.Gas(10*(11+13)+10*15), meter.GasConsumed())
+
+	// each entry is charged once, however it is read
+	consumed := meter.GasConsumed()
+	iterator = st.Iterator(nil, nil)
+	iterator.Valid()
+	iterator.Key()
+	iterator.Value()
+	iterator.Valid()
+	iterator.Close()
+	require.Equal(t, consumed+sdk.Gas(11+13), meter.GasConsumed())
+}
+
+func TestGasKVStoreConfig(t *testing.T) {
+	mem := dbStoreAdapter{dbm.NewMemDB()}
+	meter := sdk.NewGasMeter(1000)
+	config := sdk.GasConfig{
+		HasCost:          1,
+		DeleteCost:       2,
+		ReadCostFlat:     3,
+		ReadCostPerByte:  4,
+		WriteCostFlat:    5,
+		WriteCostPerByte: 6,
+		IterNextCostFlat: 7,
+	}
+	st := NewGasKVStore(meter, config, mem)
+	k, v := []byte("key"), []byte("value")
+
+	// costs are charged on the bytes of the key and the value
+	st.Set(k, v)
+	require.Equal(t, sdk.Gas(5+6*8), meter.GasConsumed())
+	st.Get(k)
+	require.Equal(t, sdk.Gas(53+3+4*8), meter.GasConsumed())
+	st.Has(k)
+	require.Equal(t, sdk.Gas(88+1), meter.GasConsumed())
+	st.Delete(k)
+	require.Equal(t, sdk.Gas(89+2), meter.GasConsumed())
+}
+
+func TestGasKVStoreOutOfGasDescriptor(t *testing.T) {
+	mem := dbStoreAdapter{dbm.NewMemDB()}
+	st := NewGasKVStore(sdk.NewGasMeter(15), sdk.KVGasConfig(), mem)
+	defer func() {
+		r := recover()
+		require.Equal(t, sdk.ErrorOutOfGas{GasWritePerByteDesc}, r)
+	}()
+	st.Set(keyFmt(1), valFmt(1))
 }
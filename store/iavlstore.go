@@ -13,8 +13,7 @@ import (
 )
 
 const (
-	defaultIAVLCacheSize  = 10000
-	defaultIAVLNumHistory = 1<<53 - 1 // DEPRECATED
+	defaultIAVLCacheSize = 10000
 )
 
 // load the iavl store
@@ -24,7 +23,8 @@ func LoadIAVLStore(db dbm.DB, id CommitID) (CommitStore, error) {
 	if err != nil {
 		return nil, err
 	}
-	store := newIAVLStore(tree, defaultIAVLNumHistory)
+	// Keep all history, old versions are pruned by the rootMultiStore.
+	store := newIAVLStore(tree, 0)
 	return store, nil
 }
 
@@ -76,6 +76,17 @@ func (st *iavlStore) Commit() CommitID {
 	}
 }
 
+// Delete a version of the tree, unless it is already deleted.
+func (st *iavlStore) deleteVersion(version int64) {
+	if !st.tree.VersionExists(version) {
+		return
+	}
+	err := st.tree.DeleteVersion(version)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Implements Committer.
 func (st *iavlStore) LastCommitID() CommitID {
 	return CommitID{
@@ -115,6 +126,11 @@ func (st *iavlStore) Delete(key []byte) {
 	st.tree.Remove(key)
 }
 
+// Implements KVStore.
+func (st *iavlStore) Prefix(prefix []byte) KVStore {
+	return NewPrefixStore(st, prefix)
+}
+
 // Implements KVStore.
 func (st *iavlStore) Iterator(start, end []byte) Iterator {
 	return newIAVLIterator(st.tree.Tree(), start, end, true)
@@ -151,6 +167,11 @@ func (st *iavlStore) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 	// store the height we chose in the response
 	res.Height = height
 
+	if height > 0 && height <= tree.Version64() && !tree.VersionExists(height) {
+		msg := fmt.Sprintf("height %d was pruned", height)
+		return sdk.ErrPrunedHeight(msg).QueryResult()
+	}
+
 	switch req.Path {
 	case "/store", "/key": // Get by key
 		key := req.Data // Data holds the key bytes
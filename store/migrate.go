@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+// The prefixes of the substores in the db of a rootMultiStore: that of the
+// named substores mounted without a db, and that of the substores in the
+// db they were mounted with.
+const (
+	storeKeyPrefix   = "s/k:" // s/k:<name>/
+	storeOwnDBPrefix = "s/_/"
+)
+
+// MigrateToStoreDBs copies the rootMultiStore of src, which keeps its
+// substores under prefixes of src, to dst and to the dbs returned by
+// storeDB for the names of the substores, laid out as if each substore had
+// been mounted with MountStoreWithDB(key, typ, storeDB(key.Name())).
+// It returns the latest CommitID of src, that of the copy once loaded.
+func MigrateToStoreDBs(src, dst dbm.DB, storeDB func(name string) dbm.DB) (CommitID, error) {
+	ver := getLatestVersion(src)
+	if ver == 0 {
+		return CommitID{}, errors.New("Failed to migrate rootMultiStore: nothing committed")
+	}
+	cInfo, err := getCommitInfo(src, ver)
+	if err != nil {
+		return CommitID{}, err
+	}
+	if getLatestVersion(dst) != 0 {
+		return CommitID{}, errors.New("Failed to migrate rootMultiStore: destination is not empty")
+	}
+
+	dbs := make(map[string]dbm.DB)
+	itr := src.Iterator(nil, nil)
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		key, value := itr.Key(), itr.Value()
+		name, storeKey, ok := splitStoreKey(key)
+		if !ok {
+			// The latest version is written last, so that an interrupted
+			// copy isn't loaded.
+			if !bytes.Equal(key, []byte(latestVersionKey)) {
+				dst.Set(key, value)
+			}
+			continue
+		}
+		db, ok := dbs[name]
+		if !ok {
+			db = dbm.NewPrefixDB(storeDB(name), []byte(storeOwnDBPrefix))
+			dbs[name] = db
+		}
+		db.Set(storeKey, value)
+	}
+
+	batch := dst.NewBatch()
+	setLatestVersion(batch, ver)
+	batch.Write()
+	return cInfo.CommitID(), nil
+}
+
+// splitStoreKey splits a key of the db of a rootMultiStore under the prefix
+// of a named substore into the name and the key in the substore
+func splitStoreKey(key []byte) (name string, storeKey []byte, ok bool) {
+	if !bytes.HasPrefix(key, []byte(storeKeyPrefix)) {
+		return "", nil, false
+	}
+	rest := key[len(storeKeyPrefix):]
+	i := bytes.IndexByte(rest, '/')
+	if i <= 0 {
+		return "", nil, false
+	}
+	return string(rest[:i]), rest[i+1:], true
+}
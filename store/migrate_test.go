@@ -0,0 +1,73 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestMigrateToStoreDBs(t *testing.T) {
+	keys := []*sdk.KVStoreKey{sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("store2")}
+	src := dbm.NewMemDB()
+	multi := NewCommitMultiStore(src)
+	for _, key := range keys {
+		multi.MountStoreWithDB(key, sdk.StoreTypeIAVL, nil)
+	}
+	require.Nil(t, multi.LoadLatestVersion())
+
+	// nothing to migrate before the first commit
+	_, err := MigrateToStoreDBs(src, dbm.NewMemDB(), func(string) dbm.DB { return dbm.NewMemDB() })
+	assert.NotNil(t, err)
+
+	for i := 0; i < 3; i++ {
+		for _, key := range keys {
+			multi.GetKVStore(key).Set(keyFmt(i), valFmt(i))
+		}
+		multi.Commit()
+	}
+	multi.GetKVStore(keys[0]).Delete(keyFmt(0))
+	last := multi.Commit()
+
+	dst := dbm.NewMemDB()
+	dbs := make(map[string]dbm.DB)
+	storeDB := func(name string) dbm.DB {
+		if _, ok := dbs[name]; !ok {
+			dbs[name] = dbm.NewMemDB()
+		}
+		return dbs[name]
+	}
+	commitID, err := MigrateToStoreDBs(src, dst, storeDB)
+	require.Nil(t, err)
+	assert.Equal(t, last, commitID)
+	assert.Equal(t, 2, len(dbs))
+
+	// the copy loads with the stores in their own dbs, at the same version
+	migrated := NewCommitMultiStore(dst)
+	for _, key := range keys {
+		migrated.MountStoreWithDB(key, sdk.StoreTypeIAVL, storeDB(key.Name()))
+	}
+	require.Nil(t, migrated.LoadLatestVersion())
+	assert.Equal(t, last, migrated.LastCommitID())
+	assert.Equal(t, last, getExpectedCommitID(migrated, last.Version))
+	assert.Nil(t, migrated.GetKVStore(keys[0]).Get(keyFmt(0)))
+	assert.Equal(t, valFmt(2), migrated.GetKVStore(keys[1]).Get(keyFmt(2)))
+
+	// older versions are copied too
+	cacheMulti, err := migrated.CacheMultiStoreWithVersion(1)
+	require.Nil(t, err)
+	assert.Equal(t, valFmt(0), cacheMulti.GetKVStore(keys[0]).Get(keyFmt(0)))
+
+	// the copy commits on from the same state as the original
+	for _, store := range []*rootMultiStore{multi, migrated} {
+		store.GetKVStore(keys[1]).Set([]byte("new"), valFmt(3))
+	}
+	assert.Equal(t, multi.Commit(), migrated.Commit())
+
+	// the destination must be empty
+	_, err = MigrateToStoreDBs(src, dst, storeDB)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tendermint/iavl"
+	"github.com/tendermint/tmlibs/merkle"
+)
+
+// MultiStoreProof proves a query of a substore against the commit hash of
+// the rootMultiStore, ie. the app hash: it chains the proof of the key in
+// the substore with the simple merkle proof of the storeInfo of the
+// substore in the commitInfo of the same version.
+// The proof of a key in a nested multistore is itself a MultiStoreProof.
+type MultiStoreProof struct {
+	StoreName     string             // name of the substore
+	StoreCommitID CommitID           // CommitID of the substore, the root of StoreProof
+	StoreProof    []byte             // proof of the key in the substore, an iavl.KeyProof or MultiStoreProof
+	Index         int                // index of the storeInfo leaf
+	Total         int                // number of storeInfo leaves
+	RootProof     merkle.SimpleProof // proof of the storeInfo leaf
+}
+
+// VerifyMultiStoreProof verifies that key has the given value in the named
+// substore, or is absent if value is nil, in the rootMultiStore whose
+// commit hash is appHash. proofBytes is the proof of a query response.
+// Stores nested in multistores are named by their path, eg. "outer/inner".
+func VerifyMultiStoreProof(proofBytes, appHash []byte, storePath string, key, value []byte) error {
+	var proof MultiStoreProof
+	err := cdc.UnmarshalBinary(proofBytes, &proof)
+	if err != nil {
+		return fmt.Errorf("Failed to decode proof: %v", err)
+	}
+	names := strings.SplitN(storePath, "/", 2)
+	storeName := names[0]
+	if proof.StoreName != storeName {
+		return fmt.Errorf("Proof is for store %s, expected %s", proof.StoreName, storeName)
+	}
+
+	// The key in the substore.
+	if len(names) == 2 {
+		err = VerifyMultiStoreProof(proof.StoreProof, proof.StoreCommitID.Hash, names[1], key, value)
+		if err != nil {
+			return fmt.Errorf("Invalid proof of store %s: %v", storeName, err)
+		}
+	} else {
+		keyProof, err := iavl.ReadKeyProof(proof.StoreProof)
+		if err != nil {
+			return fmt.Errorf("Failed to decode proof of store %s: %v", storeName, err)
+		}
+		err = keyProof.Verify(key, value, proof.StoreCommitID.Hash)
+		if err != nil {
+			return fmt.Errorf("Invalid proof of store %s: %v", storeName, err)
+		}
+	}
+
+	// The substore in the rootMultiStore.
+	si := storeInfo{}
+	si.Name = proof.StoreName
+	si.Core.CommitID = proof.StoreCommitID
+	leaf := storeInfoLeaf(si)
+	if !proof.RootProof.Verify(proof.Index, proof.Total, leaf.Hash(), appHash) {
+		return fmt.Errorf("Invalid proof of store %s against app hash %X", storeName, appHash)
+	}
+	return nil
+}
+
+// proveStore chains the proof of a query of the named substore at a version
+// with the proof of the substore in the commitInfo of the version
+func (rs *rootMultiStore) proveStore(name string, ver int64, storeProof []byte) ([]byte, error) {
+	cInfo, err := getCommitInfo(rs.db, ver)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := cInfo.proveStoreInfo(name)
+	if err != nil {
+		return nil, err
+	}
+	proof.StoreProof = storeProof
+	return cdc.MarshalBinary(proof)
+}
+
+// proveStoreInfo returns the proof of the storeInfo of the named substore
+// in the simple merkle tree of Hash, without the proof in the substore
+func (ci commitInfo) proveStoreInfo(name string) (proof MultiStoreProof, err error) {
+	var si *storeInfo
+	for i := range ci.StoreInfos {
+		if ci.StoreInfos[i].Name == name {
+			si = &ci.StoreInfos[i]
+		}
+	}
+	if si == nil {
+		return proof, fmt.Errorf("No store %s at version %d", name, ci.Version)
+	}
+
+	// The leaves of merkle.SimpleHashFromMap are the key-value pairs of
+	// the hashes of the names and of the storeInfos, sorted by key.
+	leaves := make([]merkle.KVPair, 0, len(ci.StoreInfos))
+	for _, storeInfo := range ci.StoreInfos {
+		leaves = append(leaves, storeInfoLeaf(storeInfo))
+	}
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i].Key, leaves[j].Key) < 0 })
+	hashers := make([]merkle.Hasher, len(leaves))
+	for i, leaf := range leaves {
+		hashers[i] = leaf
+	}
+	_, proofs := merkle.SimpleProofsFromHashers(hashers)
+
+	nameHash := merkle.SimpleHashFromBytes([]byte(name))
+	for i, leaf := range leaves {
+		if bytes.Equal(leaf.Key, nameHash) {
+			proof = MultiStoreProof{
+				StoreName:     name,
+				StoreCommitID: si.Core.CommitID,
+				Index:         i,
+				Total:         len(leaves),
+				RootProof:     *proofs[i],
+			}
+		}
+	}
+	return proof, nil
+}
+
+// storeInfoLeaf returns the leaf of a storeInfo in the simple merkle tree
+// of commitInfo.Hash
+func storeInfoLeaf(si storeInfo) merkle.KVPair {
+	return merkle.KVPair{
+		Key:   merkle.SimpleHashFromBytes([]byte(si.Name)),
+		Value: si.Hash(),
+	}
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestCommitInfoProveStoreInfo(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		ci := commitInfo{Version: 1}
+		for i := 0; i < n; i++ {
+			si := storeInfo{}
+			si.Name = fmt.Sprintf("store%d", i)
+			si.Core.CommitID = CommitID{1, []byte(si.Name)}
+			ci.StoreInfos = append(ci.StoreInfos, si)
+		}
+
+		for _, si := range ci.StoreInfos {
+			proof, err := ci.proveStoreInfo(si.Name)
+			require.Nil(t, err)
+			leaf := storeInfoLeaf(si)
+			assert.True(t, proof.RootProof.Verify(proof.Index, proof.Total, leaf.Hash(), ci.Hash()),
+				"store %s of %d", si.Name, n)
+		}
+
+		_, err := ci.proveStoreInfo("unknown")
+		assert.NotNil(t, err)
+	}
+}
+
+func TestMultiStoreQueryProof(t *testing.T) {
+	db := dbm.NewMemDB()
+	multi := NewCommitMultiStore(db)
+	key1, key2 := sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("store2")
+	multi.MountStoreWithDB(key1, sdk.StoreTypeIAVL, nil)
+	multi.MountStoreWithDB(key2, sdk.StoreTypeIAVL, nil)
+	err := multi.LoadLatestVersion()
+	require.Nil(t, err)
+
+	k, v := []byte("wind"), []byte("blows")
+	multi.GetKVStore(key1).Set(k, v)
+	multi.GetKVStore(key2).Set(k, []byte("howls"))
+	cid := multi.Commit()
+
+	// a present key
+	query := abci.RequestQuery{Path: "/store1/key", Data: k, Height: cid.Version, Prove: true}
+	qres := multi.Query(query)
+	require.Equal(t, uint32(0), qres.Code)
+	assert.Equal(t, v, qres.Value)
+	assert.Nil(t, VerifyMultiStoreProof(qres.Proof, cid.Hash, "store1", k, v))
+
+	// the proof fails for another value, store or app hash
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, cid.Hash, "store1", k, []byte("howls")))
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, cid.Hash, "store2", k, v))
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, []byte("apphash"), "store1", k, v))
+
+	// an absent key
+	missing := []byte("water")
+	query.Data = missing
+	qres = multi.Query(query)
+	require.Equal(t, uint32(0), qres.Code)
+	assert.Nil(t, qres.Value)
+	assert.Nil(t, VerifyMultiStoreProof(qres.Proof, cid.Hash, "store1", missing, nil))
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, cid.Hash, "store1", missing, v))
+
+	// without Prove there is no proof
+	query.Prove = false
+	qres = multi.Query(query)
+	assert.Nil(t, qres.Proof)
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ KVStore = prefixStore{}
+
+// prefixStore is a view of the keys of its parent starting with a prefix.
+// Keys are given and returned without the prefix, so the owner of a
+// prefixStore can't reach any other key of the parent.
+type prefixStore struct {
+	parent KVStore
+	prefix []byte
+}
+
+// NewPrefixStore returns the view of the keys of parent starting with prefix
+func NewPrefixStore(parent KVStore, prefix []byte) KVStore {
+	return prefixStore{
+		parent: parent,
+		prefix: prefix,
+	}
+}
+
+func (s prefixStore) key(key []byte) []byte {
+	if key == nil {
+		panic("key is nil")
+	}
+	res := make([]byte, len(s.prefix), len(s.prefix)+len(key))
+	copy(res, s.prefix)
+	return append(res, key...)
+}
+
+// Implements Store.
+func (s prefixStore) GetStoreType() StoreType {
+	return s.parent.GetStoreType()
+}
+
+// Implements CacheWrapper.
+func (s prefixStore) CacheWrap() CacheWrap {
+	return NewCacheKVStore(s)
+}
+
+// Implements KVStore.
+func (s prefixStore) Get(key []byte) []byte {
+	return s.parent.Get(s.key(key))
+}
+
+// Implements KVStore.
+func (s prefixStore) Has(key []byte) bool {
+	return s.parent.Has(s.key(key))
+}
+
+// Implements KVStore.
+func (s prefixStore) Set(key, value []byte) {
+	s.parent.Set(s.key(key), value)
+}
+
+// Implements KVStore.
+func (s prefixStore) Delete(key []byte) {
+	s.parent.Delete(s.key(key))
+}
+
+// Implements KVStore.
+func (s prefixStore) Prefix(prefix []byte) KVStore {
+	return NewPrefixStore(s, prefix)
+}
+
+// Implements KVStore.
+func (s prefixStore) Iterator(start, end []byte) Iterator {
+	pstart, pend := s.domain(start, end)
+	return newPrefixIterator(s.prefix, start, end, s.parent.Iterator(pstart, pend))
+}
+
+// Implements KVStore.
+func (s prefixStore) ReverseIterator(start, end []byte) Iterator {
+	pstart, pend := s.domain(start, end)
+	return newPrefixIterator(s.prefix, start, end, s.parent.ReverseIterator(pstart, pend))
+}
+
+// domain returns the domain of the parent covering the domain [start, end)
+// of the prefixStore, where nil start and end are unbounded
+func (s prefixStore) domain(start, end []byte) (pstart, pend []byte) {
+	switch {
+	case start != nil:
+		pstart = s.key(start)
+	case len(s.prefix) > 0:
+		pstart = s.key([]byte{})
+	}
+	switch {
+	case end != nil:
+		pend = s.key(end)
+	case len(s.prefix) > 0:
+		pend = sdk.PrefixEndBytes(s.prefix)
+	}
+	return
+}
+
+//----------------------------------------
+
+// prefixIterator strips the prefix from the keys of its parent
+type prefixIterator struct {
+	prefix     []byte
+	start, end []byte
+	parent     Iterator
+}
+
+var _ Iterator = (*prefixIterator)(nil)
+
+func newPrefixIterator(prefix, start, end []byte, parent Iterator) *prefixIterator {
+	return &prefixIterator{
+		prefix: prefix,
+		start:  start,
+		end:    end,
+		parent: parent,
+	}
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Domain() ([]byte, []byte) {
+	return pi.start, pi.end
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Valid() bool {
+	return pi.parent.Valid()
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Next() {
+	if !pi.Valid() {
+		panic("prefixIterator invalid, cannot call Next()")
+	}
+	pi.parent.Next()
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Key() []byte {
+	if !pi.Valid() {
+		panic("prefixIterator invalid, cannot call Key()")
+	}
+	return pi.parent.Key()[len(pi.prefix):]
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Value() []byte {
+	if !pi.Valid() {
+		panic("prefixIterator invalid, cannot call Value()")
+	}
+	return pi.parent.Value()
+}
+
+// Implements Iterator.
+func (pi *prefixIterator) Close() {
+	pi.parent.Close()
+}
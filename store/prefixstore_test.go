@@ -0,0 +1,88 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/iavl"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func testPrefixStore(t *testing.T, parent KVStore, prefix []byte) {
+	// keys around the prefix stay out of reach
+	parent.Set(append([]byte{}, prefix[:len(prefix)-1]...), []byte("before"))
+	parent.Set(sdk.PrefixEndBytes(prefix), []byte("after"))
+
+	store := parent.Prefix(prefix)
+	keys := [][]byte{{}, {0x00}, []byte("a"), []byte("b"), {0xff, 0xff}}
+	for _, key := range keys {
+		store.Set(key, append([]byte("value"), key...))
+	}
+
+	for _, key := range keys {
+		assert.True(t, store.Has(key))
+		assert.Equal(t, append([]byte("value"), key...), store.Get(key))
+		assert.Equal(t, append([]byte("value"), key...), parent.Get(append(append([]byte{}, prefix...), key...)))
+	}
+	assert.False(t, store.Has([]byte("c")))
+	assert.Nil(t, store.Get([]byte("c")))
+
+	checkKeys := func(itr Iterator, expected [][]byte) {
+		var got [][]byte
+		for ; itr.Valid(); itr.Next() {
+			got = append(got, itr.Key())
+			assert.Equal(t, append([]byte("value"), itr.Key()...), itr.Value())
+		}
+		itr.Close()
+		assert.Equal(t, expected, got)
+	}
+	checkKeys(store.Iterator(nil, nil), keys)
+	checkKeys(store.Iterator([]byte("a"), []byte("b")), [][]byte{[]byte("a")})
+	checkKeys(store.ReverseIterator(nil, nil), [][]byte{{0xff, 0xff}, []byte("b"), []byte("a"), {0x00}, {}})
+	checkKeys(store.ReverseIterator([]byte{0x00}, []byte("b")), [][]byte{[]byte("a"), {0x00}})
+	checkKeys(sdk.KVStorePrefixIterator(store, []byte("a")), [][]byte{[]byte("a")})
+
+	// writes to a cache are written to the parent under the prefix
+	cache := store.CacheWrap().(CacheKVStore)
+	cache.Set([]byte("c"), []byte("valuec"))
+	cache.Delete([]byte("a"))
+	assert.Nil(t, store.Get([]byte("c")))
+	cache.Write()
+	assert.Equal(t, []byte("valuec"), parent.Get(append(append([]byte{}, prefix...), 'c')))
+	assert.False(t, store.Has([]byte("a")))
+
+	// prefixes nest
+	store.Prefix([]byte("d")).Set([]byte("e"), []byte("valuede"))
+	assert.Equal(t, []byte("valuede"), store.Get([]byte("de")))
+
+	store.Delete([]byte("b"))
+	assert.Nil(t, parent.Get(append(append([]byte{}, prefix...), 'b')))
+
+	// the keys around the prefix are untouched
+	assert.Equal(t, []byte("before"), parent.Get(append([]byte{}, prefix[:len(prefix)-1]...)))
+	assert.Equal(t, []byte("after"), parent.Get(sdk.PrefixEndBytes(prefix)))
+}
+
+func TestPrefixStoreIAVL(t *testing.T) {
+	tree := iavl.NewVersionedTree(dbm.NewMemDB(), cacheSize)
+	testPrefixStore(t, newIAVLStore(tree, numHistory), []byte("zprefix"))
+}
+
+func TestPrefixStoreCache(t *testing.T) {
+	testPrefixStore(t, newCacheKVStore(), []byte{0x01, 0xff})
+}
+
+func TestPrefixStoreGas(t *testing.T) {
+	meter := sdk.NewInfiniteGasMeter()
+	testPrefixStore(t, NewGasKVStore(meter, sdk.KVGasConfig(), newCacheKVStore()), []byte("zprefix"))
+	assert.True(t, meter.GasConsumed() > 0)
+}
+
+func TestPrefixStoreNilKey(t *testing.T) {
+	store := newCacheKVStore().Prefix([]byte("prefix"))
+	require.Panics(t, func() { store.Get(nil) })
+	require.Panics(t, func() { store.Set(nil, []byte("value")) })
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) Rollback(ver int64) error {
+	latest := getLatestVersion(rs.db)
+	if ver <= 0 || ver > latest {
+		return fmt.Errorf("Failed to rollback rootMultiStore: no version %d, latest is %d", ver, latest)
+	}
+	if !rs.hasCommitInfo(ver) {
+		return sdk.ErrPrunedHeight(fmt.Sprintf("height %d was pruned", ver))
+	}
+
+	// Make ver the latest version first, so that the newer versions are
+	// unreachable even if the rollback is interrupted. Rolling back to ver
+	// again then completes it.
+	batch := rs.db.NewBatch()
+	for v := ver + 1; v <= latest; v++ {
+		deleteCommitInfo(batch, v)
+	}
+	setLatestVersion(batch, ver)
+	batch.Write()
+
+	// The held versions released by pruning after ver are recommitted
+	// from scratch, so they mustn't be pruned later.
+	rs.heldMtx.Lock()
+	heldReleases := rs.heldReleases[:0]
+	for _, release := range rs.heldReleases {
+		if release <= ver {
+			heldReleases = append(heldReleases, release)
+		}
+	}
+	rs.heldReleases = heldReleases
+	rs.heldMtx.Unlock()
+
+	for key, params := range rs.storesParams {
+		var err error
+		switch {
+		case params.multi != nil:
+			err = params.multi.Rollback(ver)
+		case params.typ == sdk.StoreTypeIAVL:
+			err = rollbackIAVL(rs.storeDB(params), ver)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to rollback store %s: %v", key.Name(), err)
+		}
+	}
+
+	return rs.LoadVersion(ver)
+}
+
+//----------------------------------------
+// IAVL rollback
+
+// The keys of the nodes, roots and orphans in the db of an IAVL tree, as
+// written by the nodeDB of iavl.
+const (
+	iavlNodeKeyFmt   = "n/%X"    // n/<hash>
+	iavlRootKeyFmt   = "r/%010d" // r/<version>
+	iavlRootPrefix   = "r/"      // r/<version>
+	iavlOrphanPrefix = "o/"      // o/<last-version>/<first-version>/<hash>
+)
+
+// rollbackIAVL deletes the versions of the IAVL tree in db after ver: their
+// roots, and the orphan records of the nodes they replaced, which are live
+// again in ver. The nodes they added are left unreachable in db.
+func rollbackIAVL(db dbm.DB, ver int64) error {
+	batch := db.NewBatch()
+	err := iterateIAVLVersions(db, iavlRootPrefix, func(key []byte, version int64) {
+		if version > ver {
+			batch.Delete(key)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	// Nodes replaced by version v are orphans whose last version is v-1.
+	err = iterateIAVLVersions(db, iavlOrphanPrefix, func(key []byte, lastVersion int64) {
+		if lastVersion >= ver {
+			batch.Delete(key)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	batch.Write()
+	return nil
+}
+
+// iterateIAVLVersions calls fn on the keys of db with the prefix, with the
+// version which follows the prefix in the key
+func iterateIAVLVersions(db dbm.DB, prefix string, fn func(key []byte, version int64)) error {
+	itr := db.Iterator([]byte(prefix), sdk.PrefixEndBytes([]byte(prefix)))
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		key := itr.Key()
+		s := strings.TrimPrefix(string(key), prefix)
+		if i := strings.IndexByte(s, '/'); i >= 0 {
+			s = s[:i]
+		}
+		version, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid IAVL key %q", key)
+		}
+		fn(key, version)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestMultiStoreRollback(t *testing.T) {
+	db := dbm.NewMemDB()
+	key1, key2 := sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("store2")
+	newStore := func() *rootMultiStore {
+		multi := NewCommitMultiStore(db)
+		multi.MountStoreWithDB(key1, sdk.StoreTypeIAVL, nil)
+		multi.MountStoreWithDB(key2, sdk.StoreTypeIAVL, nil)
+		multi.SetPruning(sdk.PruningOptions{KeepRecent: 3, KeepEvery: 100})
+		return multi
+	}
+	multi := newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+
+	k := []byte("wind")
+	var cids []CommitID
+	for i := 1; i <= 6; i++ {
+		multi.GetKVStore(key1).Set(k, []byte(fmt.Sprintf("%d", i)))
+		multi.GetKVStore(key2).Set([]byte(fmt.Sprintf("key%d", i)), k)
+		cids = append(cids, multi.Commit())
+	}
+
+	// unknown and pruned versions are refused
+	assert.NotNil(t, multi.Rollback(7))
+	assert.NotNil(t, multi.Rollback(0))
+	err := multi.Rollback(2)
+	require.NotNil(t, err)
+	assert.Equal(t, sdk.CodePrunedHeight, err.(sdk.Error).Code())
+	assert.Equal(t, cids[5], multi.LastCommitID())
+
+	// roll back to version 4
+	require.Nil(t, multi.Rollback(4))
+	assert.Equal(t, cids[3], multi.LastCommitID())
+	assert.Equal(t, []byte("4"), multi.GetKVStore(key1).Get(k))
+	assert.Nil(t, multi.GetKVStore(key2).Get([]byte("key5")))
+	for _, key := range []StoreKey{key1, key2} {
+		tree := multi.GetCommitKVStore(key).(*iavlStore).tree
+		assert.True(t, tree.VersionExists(4))
+		assert.False(t, tree.VersionExists(5))
+	}
+	_, err = multi.CacheMultiStoreWithVersion(5)
+	assert.NotNil(t, err)
+
+	// the store is reloaded at version 4, and can commit a new version 5
+	multi = newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+	assert.Equal(t, cids[3], multi.LastCommitID())
+	multi.GetKVStore(key1).Set(k, []byte("5'"))
+	cid := multi.Commit()
+	assert.Equal(t, int64(5), cid.Version)
+	assert.NotEqual(t, cids[4].Hash, cid.Hash)
+	assert.Equal(t, getExpectedCommitID(multi, 5), cid)
+
+	// the nodes live in version 4 are not pruned with the rolled back versions
+	for i := 6; i <= 9; i++ {
+		multi.Commit()
+	}
+	assert.Equal(t, []byte("5'"), multi.GetKVStore(key1).Get(k))
+	assert.Equal(t, k, multi.GetKVStore(key2).Get([]byte("key4")))
+	_, err = multi.CacheMultiStoreWithVersion(4)
+	assert.NotNil(t, err)
+}
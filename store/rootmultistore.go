@@ -2,7 +2,9 @@ package store
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/crypto/ripemd160"
 
@@ -25,9 +27,17 @@ const (
 type rootMultiStore struct {
 	db           dbm.DB
 	lastCommitID CommitID
+	pruning      PruningOptions
+	heldMtx      sync.Mutex
+	held         map[int64]int // versions held from pruning -> number of holds
+	heldReleases []int64       // versions released by pruning while held
 	storesParams map[StoreKey]storeParams
 	stores       map[StoreKey]CommitStore
 	keysByName   map[string]StoreKey
+	gasConfigs   map[StoreKey]GasConfig
+
+	listeners    map[StoreKey][]WriteListener
+	allListeners []WriteListener // distinct, notified on Commit
 }
 
 var _ CommitMultiStore = (*rootMultiStore)(nil)
@@ -37,9 +47,13 @@ var _ Queryable = (*rootMultiStore)(nil)
 func NewCommitMultiStore(db dbm.DB) *rootMultiStore {
 	return &rootMultiStore{
 		db:           db,
+		pruning:      sdk.PruneNothing,
+		held:         make(map[int64]int),
 		storesParams: make(map[StoreKey]storeParams),
 		stores:       make(map[StoreKey]CommitStore),
 		keysByName:   make(map[string]StoreKey),
+		gasConfigs:   make(map[StoreKey]GasConfig),
+		listeners:    make(map[StoreKey][]WriteListener),
 	}
 }
 
@@ -56,11 +70,19 @@ func (rs *rootMultiStore) MountStoreWithDB(key StoreKey, typ StoreType, db dbm.D
 	if _, ok := rs.storesParams[key]; ok {
 		panic(fmt.Sprintf("rootMultiStore duplicate store key %v", key))
 	}
-	rs.storesParams[key] = storeParams{
+	params := storeParams{
 		key: key,
 		typ: typ,
 		db:  db,
 	}
+	if typ == sdk.StoreTypeMulti {
+		// A nested multistore is created on mount, so that its own stores
+		// can be mounted through GetCommitStore before loading.
+		params.multi = NewCommitMultiStore(rs.storeDB(params))
+		params.multi.pruning = rs.pruning
+		rs.stores[key] = params.multi
+	}
+	rs.storesParams[key] = params
 	rs.keysByName[key.Name()] = key
 }
 
@@ -74,6 +96,57 @@ func (rs *rootMultiStore) GetCommitKVStore(key StoreKey) CommitKVStore {
 	return rs.stores[key].(CommitKVStore)
 }
 
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) SetPruning(opts PruningOptions) {
+	rs.pruning = opts
+	for _, params := range rs.storesParams {
+		if params.multi != nil {
+			params.multi.SetPruning(opts)
+		}
+	}
+}
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) AddListeners(key StoreKey, listeners []WriteListener) {
+	params, ok := rs.storesParams[key]
+	if !ok {
+		panic(fmt.Sprintf("rootMultiStore has no store %v", key))
+	}
+	if params.typ == sdk.StoreTypeMulti {
+		panic(fmt.Sprintf("store %v is not a KVStore", key))
+	}
+	rs.listeners[key] = append(rs.listeners[key], listeners...)
+	for _, listener := range listeners {
+		if !containsListener(rs.allListeners, listener) {
+			rs.allListeners = append(rs.allListeners, listener)
+		}
+	}
+}
+
+func containsListener(listeners []WriteListener, listener WriteListener) bool {
+	for _, l := range listeners {
+		if l == listener {
+			return true
+		}
+	}
+	return false
+}
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) SetGasConfig(key StoreKey, config GasConfig) {
+	if _, ok := rs.storesParams[key]; !ok {
+		panic(fmt.Sprintf("rootMultiStore has no store %v", key))
+	}
+	rs.gasConfigs[key] = config
+}
+
+func getGasConfig(gasConfigs map[StoreKey]GasConfig, key StoreKey) GasConfig {
+	if config, ok := gasConfigs[key]; ok {
+		return config
+	}
+	return sdk.KVGasConfig()
+}
+
 // Implements CommitMultiStore.
 func (rs *rootMultiStore) LoadLatestVersion() error {
 	ver := getLatestVersion(rs.db)
@@ -117,6 +190,13 @@ func (rs *rootMultiStore) LoadVersion(ver int64) error {
 		newStores[key] = store
 	}
 
+	// Transient stores are not in the commitInfo, and start empty.
+	for key, storeParams := range rs.storesParams {
+		if storeParams.typ == sdk.StoreTypeTransient {
+			newStores[key] = newTransientStore()
+		}
+	}
+
 	// If any CommitStoreLoaders were not used, return error.
 	for key := range rs.storesParams {
 		if _, ok := newStores[key]; !ok {
@@ -130,6 +210,50 @@ func (rs *rootMultiStore) LoadVersion(ver int64) error {
 	return nil
 }
 
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) CacheMultiStoreWithVersion(ver int64) (CacheMultiStore, error) {
+	if ver > 0 && ver < rs.lastCommitID.Version && !rs.hasCommitInfo(ver) {
+		return nil, sdk.ErrPrunedHeight(fmt.Sprintf("height %d was pruned", ver))
+	}
+	cInfo, err := getCommitInfo(rs.db, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load each Store at the given version, leaving rs.stores untouched.
+	var stores = make(map[StoreKey]CommitStore)
+	for _, storeInfo := range cInfo.StoreInfos {
+		key, commitID := rs.nameToKey(storeInfo.Name), storeInfo.Core.CommitID
+		storeParams := rs.storesParams[key]
+		if storeParams.multi != nil {
+			// leave the live nested multistore untouched too
+			storeParams.multi = storeParams.multi.mountsCopy()
+		}
+		store, err := rs.loadCommitStoreFromParams(commitID, storeParams)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load rootMultiStore at version %d: %v", ver, err)
+		}
+		stores[key] = store
+	}
+	for key, storeParams := range rs.storesParams {
+		if storeParams.typ == sdk.StoreTypeTransient {
+			stores[key] = newTransientStore()
+		}
+	}
+
+	cms := newCacheMultiStoreFromStores(rs.db, stores, rs.keysByName, rs.gasConfigs)
+	return historicalMultiStore{cms}, nil
+}
+
+// historicalMultiStore is a cache-wrapped view of a previously committed
+// version.  Its Write is a no-op: writing through would reach the live db.
+type historicalMultiStore struct {
+	cacheMultiStore
+}
+
+// Implements CacheMultiStore.
+func (hms historicalMultiStore) Write() {}
+
 //----------------------------------------
 // +CommitStore
 
@@ -149,17 +273,80 @@ func (rs *rootMultiStore) Commit() CommitID {
 	batch := rs.db.NewBatch()
 	setCommitInfo(batch, version, commitInfo)
 	setLatestVersion(batch, version)
+	releases := rs.releases(rs.pruning.Releases(version))
+	for _, release := range releases {
+		deleteCommitInfo(batch, release)
+	}
 	batch.Write()
 
+	// Prune the released versions from all stores.
+	for _, release := range releases {
+		for _, store := range rs.stores {
+			if st, ok := store.(*iavlStore); ok {
+				st.deleteVersion(release)
+			}
+		}
+	}
+
 	// Prepare for next version.
 	commitID := CommitID{
 		Version: version,
 		Hash:    commitInfo.Hash(),
 	}
 	rs.lastCommitID = commitID
+
+	for _, listener := range rs.allListeners {
+		listener.OnCommit(commitID)
+	}
 	return commitID
 }
 
+// releases returns the versions to prune on commit: the version released by
+// the pruning options unless it is held, and the held versions released
+// earlier which aren't held anymore
+func (rs *rootMultiStore) releases(release int64, prune bool) (versions []int64) {
+	rs.heldMtx.Lock()
+	defer rs.heldMtx.Unlock()
+	if prune {
+		rs.heldReleases = append(rs.heldReleases, release)
+	}
+	stillHeld := rs.heldReleases[:0]
+	for _, ver := range rs.heldReleases {
+		if rs.held[ver] > 0 {
+			stillHeld = append(stillHeld, ver)
+		} else {
+			versions = append(versions, ver)
+		}
+	}
+	rs.heldReleases = stillHeld
+	return versions
+}
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) HoldVersion(ver int64) func() {
+	rs.heldMtx.Lock()
+	rs.held[ver]++
+	rs.heldMtx.Unlock()
+
+	// nested multistores prune their versions on their own
+	var unholds []func()
+	for _, params := range rs.storesParams {
+		if params.multi != nil {
+			unholds = append(unholds, params.multi.HoldVersion(ver))
+		}
+	}
+	return func() {
+		rs.heldMtx.Lock()
+		if rs.held[ver]--; rs.held[ver] == 0 {
+			delete(rs.held, ver)
+		}
+		rs.heldMtx.Unlock()
+		for _, unhold := range unholds {
+			unhold()
+		}
+	}
+}
+
 // Implements CacheWrapper/Store/CommitStore.
 func (rs *rootMultiStore) CacheWrap() CacheWrap {
 	return rs.CacheMultiStore().(CacheWrap)
@@ -173,6 +360,15 @@ func (rs *rootMultiStore) CacheMultiStore() CacheMultiStore {
 	return newCacheMultiStoreFromRMS(rs)
 }
 
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) CacheMultiStoreWithListeners() CacheMultiStore {
+	cms := newCacheMultiStoreFromRMS(rs)
+	for key, listeners := range rs.listeners {
+		cms.stores[key].(*cacheKVStore).setListeners(key, listeners)
+	}
+	return cms
+}
+
 // Implements MultiStore.
 func (rs *rootMultiStore) GetStore(key StoreKey) Store {
 	return rs.stores[key]
@@ -184,8 +380,13 @@ func (rs *rootMultiStore) GetKVStore(key StoreKey) KVStore {
 }
 
 // Implements MultiStore.
-func (rs *rootMultiStore) GetKVStoreWithGas(meter sdk.GasMeter, key StoreKey) KVStore {
-	return NewGasKVStore(meter, rs.GetKVStore(key))
+func (rs *rootMultiStore) GetKVStoreWithGas(meter sdk.GasMeter, config GasConfig, key StoreKey) KVStore {
+	return NewGasKVStore(meter, config, rs.GetKVStore(key))
+}
+
+// Implements MultiStore.
+func (rs *rootMultiStore) GetGasConfig(key StoreKey) GasConfig {
+	return getGasConfig(rs.gasConfigs, key)
 }
 
 // getStoreByName will first convert the original name to
@@ -206,7 +407,8 @@ func (rs *rootMultiStore) getStoreByName(name string) Store {
 // Query calls substore.Query with the same `req` where `req.Path` is
 // modified to remove the substore prefix.
 // Ie. `req.Path` here is `/<substore>/<path>`, and trimmed to `/<path>` for the substore.
-// TODO: add proof for `multistore -> substore`.
+// Proofs of the substore are chained with the proof of the substore in the
+// rootMultiStore, see MultiStoreProof.
 func (rs *rootMultiStore) Query(req abci.RequestQuery) abci.ResponseQuery {
 	// Query just routes this to a substore.
 	path := req.Path
@@ -229,6 +431,15 @@ func (rs *rootMultiStore) Query(req abci.RequestQuery) abci.ResponseQuery {
 	// trim the path and make the query
 	req.Path = subpath
 	res := queryable.Query(req)
+
+	if req.Prove && res.IsOK() && len(res.Proof) > 0 {
+		proof, err := rs.proveStore(storeName, res.Height, res.Proof)
+		if err != nil {
+			msg := fmt.Sprintf("failed to prove store %s: %v", storeName, err)
+			return sdk.ErrInternal(msg).QueryResult()
+		}
+		res.Proof = proof
+	}
 	return res
 }
 
@@ -250,21 +461,27 @@ func parsePath(path string) (storeName string, subpath string, err sdk.Error) {
 
 //----------------------------------------
 
-func (rs *rootMultiStore) loadCommitStoreFromParams(id CommitID, params storeParams) (store CommitStore, err error) {
-	var db dbm.DB
+// storeDB returns the db holding the data of the store with the given params
+func (rs *rootMultiStore) storeDB(params storeParams) dbm.DB {
 	if params.db != nil {
-		db = dbm.NewPrefixDB(params.db, []byte("s/_/"))
-	} else {
-		db = dbm.NewPrefixDB(rs.db, []byte("s/k:"+params.key.Name()+"/"))
+		return dbm.NewPrefixDB(params.db, []byte(storeOwnDBPrefix))
 	}
+	return dbm.NewPrefixDB(rs.db, []byte(storeKeyPrefix+params.key.Name()+"/"))
+}
+
+func (rs *rootMultiStore) loadCommitStoreFromParams(id CommitID, params storeParams) (store CommitStore, err error) {
+	db := rs.storeDB(params)
 	switch params.typ {
 	case sdk.StoreTypeMulti:
-		panic("recursive MultiStores not yet supported")
-		// TODO: id?
-		// return NewCommitMultiStore(db, id)
+		err = params.multi.LoadVersion(id.Version)
+		store = params.multi
+		return
 	case sdk.StoreTypeIAVL:
 		store, err = LoadIAVLStore(db, id)
 		return
+	case sdk.StoreTypeTransient:
+		store = newTransientStore()
+		return
 	case sdk.StoreTypeDB:
 		panic("dbm.DB is not a CommitStore")
 	default:
@@ -272,6 +489,23 @@ func (rs *rootMultiStore) loadCommitStoreFromParams(id CommitID, params storePar
 	}
 }
 
+// mountsCopy returns an unloaded rootMultiStore on the same db, with
+// the same stores mounted
+func (rs *rootMultiStore) mountsCopy() *rootMultiStore {
+	cp := NewCommitMultiStore(rs.db)
+	cp.pruning = rs.pruning
+	cp.gasConfigs = rs.gasConfigs
+	for key, params := range rs.storesParams {
+		if params.multi != nil {
+			params.multi = params.multi.mountsCopy()
+			cp.stores[key] = params.multi
+		}
+		cp.storesParams[key] = params
+		cp.keysByName[key.Name()] = key
+	}
+	return cp
+}
+
 func (rs *rootMultiStore) nameToKey(name string) StoreKey {
 	for key := range rs.storesParams {
 		if key.Name() == name {
@@ -285,9 +519,10 @@ func (rs *rootMultiStore) nameToKey(name string) StoreKey {
 // storeParams
 
 type storeParams struct {
-	key StoreKey
-	db  dbm.DB
-	typ StoreType
+	key   StoreKey
+	db    dbm.DB
+	typ   StoreType
+	multi *rootMultiStore // the nested multistore, if typ is StoreTypeMulti
 }
 
 //----------------------------------------
@@ -370,17 +605,40 @@ func setLatestVersion(batch dbm.Batch, version int64) {
 }
 
 // Commits each store and returns a new commitInfo.
+// The stores are independent, so they are committed concurrently.
 func commitStores(version int64, storeMap map[StoreKey]CommitStore) commitInfo {
-	storeInfos := make([]storeInfo, 0, len(storeMap))
+	return commitStoresWith(version, storeMap, commitParallel)
+}
 
-	for key, store := range storeMap {
-		// Commit
-		commitID := store.Commit()
+// commitStoresWith commits the stores with commit, and merges their
+// CommitIDs into a commitInfo whose storeInfos are sorted by store name,
+// whatever order the stores were committed in.
+func commitStoresWith(version int64, storeMap map[StoreKey]CommitStore,
+	commit func([]CommitStore) []CommitID) commitInfo {
+
+	keys := make([]StoreKey, 0, len(storeMap))
+	for key := range storeMap {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name() < keys[j].Name() })
+	stores := make([]CommitStore, len(keys))
+	for i, key := range keys {
+		stores[i] = storeMap[key]
+	}
+
+	commitIDs := commit(stores)
+
+	storeInfos := make([]storeInfo, 0, len(keys))
+	for i, key := range keys {
+		// Transient stores are only reset
+		if stores[i].GetStoreType() == sdk.StoreTypeTransient {
+			continue
+		}
 
 		// Record CommitID
 		si := storeInfo{}
 		si.Name = key.Name()
-		si.Core.CommitID = commitID
+		si.Core.CommitID = commitIDs[i]
 		// si.Core.StoreType = store.GetStoreType()
 		storeInfos = append(storeInfos, si)
 	}
@@ -392,6 +650,42 @@ func commitStores(version int64, storeMap map[StoreKey]CommitStore) commitInfo {
 	return ci
 }
 
+// commitSerial commits the stores one after the other.
+func commitSerial(stores []CommitStore) []CommitID {
+	commitIDs := make([]CommitID, len(stores))
+	for i, store := range stores {
+		commitIDs[i] = store.Commit()
+	}
+	return commitIDs
+}
+
+// commitParallel commits each store in its own goroutine. A panic in a
+// Commit is re-raised in the caller once all stores are done, as it would
+// be by commitSerial.
+func commitParallel(stores []CommitStore) []CommitID {
+	commitIDs := make([]CommitID, len(stores))
+	panics := make([]interface{}, len(stores))
+	var wg sync.WaitGroup
+	wg.Add(len(stores))
+	for i, store := range stores {
+		go func(i int, store CommitStore) {
+			defer wg.Done()
+			defer func() {
+				panics[i] = recover()
+			}()
+			commitIDs[i] = store.Commit()
+		}(i, store)
+	}
+	wg.Wait()
+
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+	return commitIDs
+}
+
 // Gets commitInfo from disk.
 func getCommitInfo(db dbm.DB, ver int64) (commitInfo, error) {
 
@@ -411,6 +705,11 @@ func getCommitInfo(db dbm.DB, ver int64) (commitInfo, error) {
 	return cInfo, nil
 }
 
+// Checks if the commitInfo of a version is on disk, ie. it wasn't pruned.
+func (rs *rootMultiStore) hasCommitInfo(ver int64) bool {
+	return rs.db.Has([]byte(fmt.Sprintf(commitInfoKeyFmt, ver)))
+}
+
 // Set a commitInfo for given version.
 func setCommitInfo(batch dbm.Batch, version int64, cInfo commitInfo) {
 	cInfoBytes, err := cdc.MarshalBinary(cInfo)
@@ -420,3 +719,9 @@ func setCommitInfo(batch dbm.Batch, version int64, cInfo commitInfo) {
 	cInfoKey := fmt.Sprintf(commitInfoKeyFmt, version)
 	batch.Set([]byte(cInfoKey), cInfoBytes)
 }
+
+// Delete the commitInfo of a pruned version.
+func deleteCommitInfo(batch dbm.Batch, version int64) {
+	cInfoKey := fmt.Sprintf(commitInfoKeyFmt, version)
+	batch.Delete([]byte(cInfoKey))
+}
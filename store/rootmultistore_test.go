@@ -1,9 +1,11 @@
 package store
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/abci/types"
 	dbm "github.com/tendermint/tmlibs/db"
 	"github.com/tendermint/tmlibs/merkle"
@@ -159,6 +161,318 @@ func TestMultiStoreQuery(t *testing.T) {
 	assert.Equal(t, v2, qres.Value)
 }
 
+func TestMultiStoreCacheWithVersion(t *testing.T) {
+	db := dbm.NewMemDB()
+	multi := NewCommitMultiStore(db)
+	key1, key2 := sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("store2")
+	multi.MountStoreWithDB(key1, sdk.StoreTypeIAVL, nil)
+	multi.MountStoreWithDB(key2, sdk.StoreTypeIAVL, nil)
+	err := multi.LoadLatestVersion()
+	assert.Nil(t, err)
+
+	k, v1, v2 := []byte("wind"), []byte("blows"), []byte("howls")
+
+	// Commit two versions with different values.
+	multi.GetKVStore(key1).Set(k, v1)
+	cid1 := multi.Commit()
+	multi.GetKVStore(key1).Set(k, v2)
+	multi.GetKVStore(key2).Set(k, v2)
+	multi.Commit()
+
+	// The old version sees the old state.
+	cache, err := multi.CacheMultiStoreWithVersion(cid1.Version)
+	assert.Nil(t, err)
+	assert.Equal(t, v1, cache.GetKVStore(key1).Get(k))
+	assert.Nil(t, cache.GetKVStore(key2).Get(k))
+
+	// Writing to the cache leaves the live stores untouched.
+	cache.GetKVStore(key2).Set(k, v1)
+	cache.Write()
+	assert.Equal(t, v2, multi.GetKVStore(key1).Get(k))
+	assert.Equal(t, v2, multi.GetKVStore(key2).Get(k))
+	assert.Equal(t, v1, cache.GetKVStore(key2).Get(k))
+
+	// So does writing a store cache-wrapping it.
+	cache.CacheMultiStore().Write()
+	cache.Write()
+	assert.Equal(t, v2, multi.GetKVStore(key2).Get(k))
+
+	// Unknown versions fail.
+	_, err = multi.CacheMultiStoreWithVersion(cid1.Version + 10)
+	assert.NotNil(t, err)
+}
+
+func TestMultiStorePruning(t *testing.T) {
+	db := dbm.NewMemDB()
+	multi := NewCommitMultiStore(db)
+	key1, key2 := sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("store2")
+	multi.MountStoreWithDB(key1, sdk.StoreTypeIAVL, nil)
+	multi.MountStoreWithDB(key2, sdk.StoreTypeIAVL, nil)
+	multi.SetPruning(sdk.PruningOptions{KeepRecent: 2, KeepEvery: 3})
+	err := multi.LoadLatestVersion()
+	assert.Nil(t, err)
+
+	k := []byte("wind")
+	for i := 1; i <= 10; i++ {
+		multi.GetKVStore(key1).Set(k, []byte(fmt.Sprintf("%d", i)))
+		multi.GetKVStore(key2).Set(k, []byte(fmt.Sprintf("%d", i)))
+		multi.Commit()
+	}
+
+	kept := map[int64]bool{3: true, 6: true, 8: true, 9: true, 10: true}
+	for ver := int64(1); ver <= 10; ver++ {
+		for _, key := range []StoreKey{key1, key2} {
+			tree := multi.GetCommitKVStore(key).(*iavlStore).tree
+			assert.Equal(t, kept[ver], tree.VersionExists(ver), "version %d of %s", ver, key.Name())
+		}
+
+		query := abci.RequestQuery{Path: "/store1/key", Data: k, Height: ver}
+		qres := multi.Query(query)
+		_, err := multi.CacheMultiStoreWithVersion(ver)
+		if kept[ver] {
+			assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeOK), sdk.ABCICodeType(qres.Code))
+			assert.Equal(t, []byte(fmt.Sprintf("%d", ver)), qres.Value)
+			assert.Nil(t, err)
+		} else {
+			assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodePrunedHeight), sdk.ABCICodeType(qres.Code))
+			require.NotNil(t, err)
+			assert.Equal(t, sdk.CodePrunedHeight, err.(sdk.Error).Code())
+		}
+	}
+}
+
+func TestNestedMultiStore(t *testing.T) {
+	db := dbm.NewMemDB()
+	key1, ibcKey := sdk.NewKVStoreKey("store1"), sdk.NewKVStoreKey("ibc")
+	chainA, chainB := sdk.NewKVStoreKey("chainA"), sdk.NewKVStoreKey("chainB")
+	newStore := func() *rootMultiStore {
+		multi := NewCommitMultiStore(db)
+		multi.MountStoreWithDB(key1, sdk.StoreTypeIAVL, nil)
+		multi.MountStoreWithDB(ibcKey, sdk.StoreTypeMulti, nil)
+		nested := multi.GetCommitStore(ibcKey).(CommitMultiStore)
+		nested.MountStoreWithDB(chainA, sdk.StoreTypeIAVL, nil)
+		nested.MountStoreWithDB(chainB, sdk.StoreTypeIAVL, nil)
+		return multi
+	}
+	multi := newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+
+	k, v1, v2 := []byte("wind"), []byte("blows"), []byte("howls")
+
+	// writes through caches reach the nested stores
+	cache := multi.CacheMultiStore()
+	cache.GetStore(ibcKey).(MultiStore).GetKVStore(chainA).Set(k, v1)
+	cache.Write()
+	cid1 := multi.Commit()
+	nested := multi.GetCommitStore(ibcKey).(CommitMultiStore)
+	assert.Equal(t, v1, nested.GetKVStore(chainA).Get(k))
+
+	// the nested multistore is a single leaf of the commitInfo
+	cInfo, err := getCommitInfo(db, cid1.Version)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(cInfo.StoreInfos))
+	assert.Equal(t, cid1, multi.LastCommitID())
+	assert.Equal(t, getExpectedCommitID(multi, 1), cid1)
+
+	nested.GetKVStore(chainA).Set(k, v2)
+	nested.GetKVStore(chainB).Set(k, v2)
+	cid2 := multi.Commit()
+
+	// reload
+	multi = newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+	assert.Equal(t, cid2, multi.LastCommitID())
+	nested = multi.GetCommitStore(ibcKey).(CommitMultiStore)
+	assert.Equal(t, v2, nested.GetKVStore(chainB).Get(k))
+
+	// the old version of nested stores
+	hist, err := multi.CacheMultiStoreWithVersion(cid1.Version)
+	require.Nil(t, err)
+	assert.Equal(t, v1, hist.GetStore(ibcKey).(MultiStore).GetKVStore(chainA).Get(k))
+	assert.Nil(t, hist.GetStore(ibcKey).(MultiStore).GetKVStore(chainB).Get(k))
+	assert.Equal(t, v2, nested.GetKVStore(chainA).Get(k))
+
+	// nested queries, with proofs up to the app hash
+	query := abci.RequestQuery{Path: "/ibc/chainA/key", Data: k, Height: cid2.Version, Prove: true}
+	qres := multi.Query(query)
+	require.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeOK), sdk.ABCICodeType(qres.Code))
+	assert.Equal(t, v2, qres.Value)
+	assert.Nil(t, VerifyMultiStoreProof(qres.Proof, cid2.Hash, "ibc/chainA", k, v2))
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, cid2.Hash, "ibc/chainB", k, v2))
+	assert.NotNil(t, VerifyMultiStoreProof(qres.Proof, cid2.Hash, "ibc", k, v2))
+}
+
+func TestMultiStoreGasConfig(t *testing.T) {
+	multi := newMultiStoreWithMounts(dbm.NewMemDB())
+	require.Nil(t, multi.LoadLatestVersion())
+	key1, key2 := multi.keysByName["store1"], multi.keysByName["store2"]
+	config := sdk.KVGasConfig()
+	config.HasCost = 1000
+	multi.SetGasConfig(key1, config)
+	require.Panics(t, func() { multi.SetGasConfig(sdk.NewKVStoreKey("store4"), config) })
+
+	// the config of a key is kept by caches, other keys get the default
+	assert.Equal(t, config, multi.GetGasConfig(key1))
+	assert.Equal(t, sdk.KVGasConfig(), multi.GetGasConfig(key2))
+	cache := multi.CacheMultiStore().CacheMultiStore()
+	assert.Equal(t, config, cache.GetGasConfig(key1))
+	cid := multi.Commit()
+	hist, err := multi.CacheMultiStoreWithVersion(cid.Version)
+	require.Nil(t, err)
+	assert.Equal(t, config, hist.GetGasConfig(key1))
+
+	meter := sdk.NewInfiniteGasMeter()
+	cache.GetKVStoreWithGas(meter, cache.GetGasConfig(key1), key1).Has([]byte("key"))
+	assert.Equal(t, sdk.Gas(1000), meter.GasConsumed())
+}
+
+type writeEvent struct {
+	store   string
+	key     string
+	value   string
+	deleted bool
+}
+
+// recordingListener records the writes and commits it is notified of
+type recordingListener struct {
+	writes  []writeEvent
+	commits []CommitID
+}
+
+func (rl *recordingListener) OnWrite(storeKey StoreKey, key, value []byte, deleted bool) {
+	rl.writes = append(rl.writes, writeEvent{storeKey.Name(), string(key), string(value), deleted})
+}
+
+func (rl *recordingListener) OnCommit(commitID CommitID) {
+	rl.commits = append(rl.commits, commitID)
+}
+
+func TestMultiStoreListeners(t *testing.T) {
+	multi := newMultiStoreWithMounts(dbm.NewMemDB())
+	require.Nil(t, multi.LoadLatestVersion())
+	key1, key2, key3 := multi.keysByName["store1"], multi.keysByName["store2"], multi.keysByName["store3"]
+	listener := &recordingListener{}
+	multi.AddListeners(key1, []WriteListener{listener})
+	multi.AddListeners(key2, []WriteListener{listener})
+	require.Panics(t, func() { multi.AddListeners(sdk.NewKVStoreKey("store4"), []WriteListener{listener}) })
+
+	block := multi.CacheMultiStoreWithListeners()
+
+	// direct writes are notified as they are made
+	block.GetKVStore(key2).Set([]byte("b"), []byte("1"))
+	block.GetKVStore(key3).Set([]byte("c"), []byte("1"))
+	assert.Equal(t, []writeEvent{{"store2", "b", "1", false}}, listener.writes)
+
+	// the writes of a cache are notified when it is written, ordered by
+	// store name and key, and never if it is discarded
+	tx := block.CacheMultiStore()
+	tx.GetKVStore(key2).Set([]byte("z"), []byte("2"))
+	tx.GetKVStore(key1).Set([]byte("y"), []byte("2"))
+	tx.GetKVStore(key1).Set([]byte("x"), []byte("2"))
+	tx.GetKVStore(key2).Delete([]byte("b"))
+	assert.Equal(t, 1, len(listener.writes))
+	tx.Write()
+	discarded := block.CacheMultiStore()
+	discarded.GetKVStore(key1).Set([]byte("w"), []byte("3"))
+	expected := []writeEvent{
+		{"store2", "b", "1", false},
+		{"store1", "x", "2", false},
+		{"store1", "y", "2", false},
+		{"store2", "b", "", true},
+		{"store2", "z", "2", false},
+	}
+	assert.Equal(t, expected, listener.writes)
+
+	// writing the block to the stores notifies nothing more, the commit
+	// is notified once
+	block.Write()
+	assert.Equal(t, expected, listener.writes)
+	cid := multi.Commit()
+	assert.Equal(t, []CommitID{cid}, listener.commits)
+	assert.Equal(t, []byte("2"), multi.GetKVStore(key1).Get([]byte("x")))
+
+	// caches without listeners notify nothing
+	cache := multi.CacheMultiStore()
+	cache.GetKVStore(key1).Set([]byte("v"), []byte("4"))
+	cache.Write()
+	assert.Equal(t, expected, listener.writes)
+}
+
+func TestCommitStoresParallel(t *testing.T) {
+	db := dbm.NewMemDB()
+	serial, parallel := newMultiStoreWithMounts(db), newMultiStoreWithMounts(dbm.NewMemDB())
+	require.Nil(t, serial.LoadLatestVersion())
+	require.Nil(t, parallel.LoadLatestVersion())
+	for _, multi := range []*rootMultiStore{serial, parallel} {
+		for name, key := range multi.keysByName {
+			multi.GetKVStore(key).Set([]byte("name"), []byte(name))
+		}
+	}
+
+	// both commit the same stores, sorted by name
+	ciSerial := commitStoresWith(1, serial.stores, commitSerial)
+	ciParallel := commitStoresWith(1, parallel.stores, commitParallel)
+	assert.Equal(t, ciSerial, ciParallel)
+	require.Equal(t, 3, len(ciParallel.StoreInfos))
+	for i, name := range []string{"store1", "store2", "store3"} {
+		assert.Equal(t, name, ciParallel.StoreInfos[i].Name)
+	}
+
+	// panics reach the caller
+	stores := []CommitStore{parallel.stores[parallel.keysByName["store1"]], panicCommitStore{}}
+	assert.Panics(t, func() { commitParallel(stores) })
+}
+
+// panicCommitStore panics on Commit
+type panicCommitStore struct {
+	CommitStore
+}
+
+func (panicCommitStore) Commit() CommitID {
+	panic("commit failed")
+}
+
+func BenchmarkCommitStoresSerial(b *testing.B) {
+	benchmarkCommitStores(b, commitSerial)
+}
+
+func BenchmarkCommitStoresParallel(b *testing.B) {
+	benchmarkCommitStores(b, commitParallel)
+}
+
+// benchmarkCommitStores commits blocks of writes to a multistore of five
+// populated IAVL stores, like the stores of gaia.
+func benchmarkCommitStores(b *testing.B, commit func([]CommitStore) []CommitID) {
+	const numStores, numKeys, numWrites = 5, 10000, 1000
+	db := dbm.NewMemDB()
+	multi := NewCommitMultiStore(db)
+	for i := 0; i < numStores; i++ {
+		multi.MountStoreWithDB(sdk.NewKVStoreKey(fmt.Sprintf("store%d", i)), sdk.StoreTypeIAVL, nil)
+	}
+	require.Nil(b, multi.LoadLatestVersion())
+	for _, store := range multi.stores {
+		kv := store.(KVStore)
+		for i := 0; i < numKeys; i++ {
+			kv.Set([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+		}
+	}
+	commitStoresWith(1, multi.stores, commitSerial)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		for _, store := range multi.stores {
+			kv := store.(KVStore)
+			for i := 0; i < numWrites; i++ {
+				key := fmt.Sprintf("key%d", (n*numWrites+i)%numKeys)
+				kv.Set([]byte(key), []byte(fmt.Sprintf("value%d-%d", n, i)))
+			}
+		}
+		b.StartTimer()
+		commitStoresWith(int64(n+2), multi.stores, commit)
+	}
+}
+
 //-----------------------------------------------------------------------
 // utils
 
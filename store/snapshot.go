@@ -0,0 +1,523 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/tendermint/go-amino"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A snapshot of the rootMultiStore at version H is the directory <dir>/<H>,
+// holding a manifest and a series of chunk files. Concatenated, the chunks
+// are a stream of length-prefixed snapshotItems: the raw key/value pairs of
+// the commitInfo of H and of the IAVL trees of the substores at H, ie. their
+// root and the nodes reachable from it. The nodes of other versions and the
+// orphan records are left out, so the snapshot doesn't grow with history.
+// The chunks are hashed, so corrupted chunks are detected while they are
+// read. The manifest itself isn't trusted: the restored trees are verified,
+// node contents included, against a trusted app hash.
+
+const (
+	snapshotManifestFile = "manifest.json"
+	snapshotChunkFmt     = "chunk-%06d" // chunk-<index>
+
+	// Items bigger than this are rejected on restore
+	maxSnapshotItemSize = 64 << 20
+)
+
+// snapshotItem is a key/value pair of the db of the named substore,
+// or of the rootMultiStore itself if Store is empty.
+type snapshotItem struct {
+	Store string
+	Key   []byte
+	Value []byte
+}
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) Snapshot(dir string, ver int64, chunkSize int) (manifest SnapshotManifest, err error) {
+	if ver <= 0 {
+		return manifest, fmt.Errorf("Failed to snapshot rootMultiStore: invalid version %d", ver)
+	}
+	cInfo, err := getCommitInfo(rs.db, ver)
+	if err != nil {
+		return manifest, fmt.Errorf("Failed to snapshot rootMultiStore: %v", err)
+	}
+	commitID := cInfo.CommitID()
+	if chunkSize <= 0 {
+		return manifest, fmt.Errorf("Failed to snapshot rootMultiStore: invalid chunk size %d", chunkSize)
+	}
+
+	// Write to a temporary directory, so that an incomplete snapshot is
+	// never mistaken for a complete one.
+	snapshotDir := filepath.Join(dir, strconv.FormatInt(commitID.Version, 10))
+	tmpDir := snapshotDir + ".tmp"
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return
+	}
+	if err = os.MkdirAll(tmpDir, 0755); err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	w := &snapshotChunkWriter{dir: tmpDir, chunkSize: chunkSize}
+
+	err = rs.writeSnapshotVersion(w, "", nil, commitID.Version)
+	if err != nil {
+		return
+	}
+	hashes, err := w.Close()
+	if err != nil {
+		return
+	}
+	manifest = SnapshotManifest{
+		CommitID:    commitID,
+		ChunkHashes: hashes,
+	}
+	bz, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(filepath.Join(tmpDir, snapshotManifestFile), bz, 0644); err != nil {
+		return
+	}
+
+	// Replace any previous snapshot of the version.
+	if err = os.RemoveAll(snapshotDir); err != nil {
+		return
+	}
+	err = os.Rename(tmpDir, snapshotDir)
+	return
+}
+
+// Implements CommitMultiStore.
+func (rs *rootMultiStore) Restore(dir string, ver int64, appHash []byte) error {
+	if latest := getLatestVersion(rs.db); latest != 0 {
+		return fmt.Errorf("Failed to restore rootMultiStore: already at version %d", latest)
+	}
+
+	snapshotDir := filepath.Join(dir, strconv.FormatInt(ver, 10))
+	manifest, err := readSnapshotManifest(snapshotDir)
+	if err != nil {
+		return err
+	}
+	if manifest.CommitID.Version != ver {
+		return fmt.Errorf("Failed to restore rootMultiStore: snapshot is of version %d, expected %d",
+			manifest.CommitID.Version, ver)
+	}
+	if !bytes.Equal(manifest.CommitID.Hash, appHash) {
+		return fmt.Errorf("Failed to restore rootMultiStore: snapshot has app hash %X, expected %X",
+			manifest.CommitID.Hash, appHash)
+	}
+
+	// Restore into a staging db first, so that the live dbs are only
+	// written once the restored stores match the trusted app hash.
+	stagingDir, err := ioutil.TempDir(dir, "restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+	stagingDB := dbm.NewDB("staging", dbm.GoLevelDBBackend, stagingDir)
+	defer stagingDB.Close()
+	staging := rs.mountsCopyOn(stagingDB)
+
+	// Write the items to the staging dbs of the stores they were read from.
+	r := bufio.NewReader(&snapshotChunkReader{dir: snapshotDir, hashes: manifest.ChunkHashes})
+	for {
+		item, err := readSnapshotItem(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to restore rootMultiStore: %v", err)
+		}
+		if item.Store == "" {
+			staging.db.Set(item.Key, item.Value)
+			continue
+		}
+		key, ok := staging.keysByName[item.Store]
+		if !ok {
+			return fmt.Errorf("Failed to restore rootMultiStore: unknown store %s", item.Store)
+		}
+		staging.storeDB(staging.storesParams[key]).Set(item.Key, item.Value)
+	}
+
+	// Verify the loaded stores, rather than the restored commitInfo. Their
+	// root hashes are only the keys of the restored root nodes, so the
+	// contents of the trees are then verified against them.
+	if err := staging.LoadVersion(ver); err != nil {
+		return fmt.Errorf("Failed to restore rootMultiStore: %v", err)
+	}
+	restored := staging.loadedCommitInfo(ver).CommitID()
+	if !bytes.Equal(restored.Hash, appHash) {
+		return fmt.Errorf("Failed to restore rootMultiStore: restored app hash %X, expected %X",
+			restored.Hash, appHash)
+	}
+	if err := staging.verifyIAVLStores(ver); err != nil {
+		return fmt.Errorf("Failed to restore rootMultiStore: %v", err)
+	}
+
+	// Copy the verified data to the live dbs, and only then make the
+	// restored version the latest.
+	cInfoKey := []byte(fmt.Sprintf(commitInfoKeyFmt, ver))
+	rs.db.Set(cInfoKey, staging.db.Get(cInfoKey))
+	for name, key := range rs.keysByName {
+		params := rs.storesParams[key]
+		if params.typ == sdk.StoreTypeTransient {
+			continue
+		}
+		copyDB(rs.storeDB(params), staging.storeDB(staging.storesParams[staging.keysByName[name]]))
+	}
+	if err := rs.LoadVersion(ver); err != nil {
+		return err
+	}
+	batch := rs.db.NewBatch()
+	setLatestVersion(batch, ver)
+	batch.Write()
+	return nil
+}
+
+// mountsCopyOn returns an unloaded rootMultiStore on db, with the same
+// stores mounted, all of them on db
+func (rs *rootMultiStore) mountsCopyOn(db dbm.DB) *rootMultiStore {
+	cp := NewCommitMultiStore(db)
+	for key, params := range rs.storesParams {
+		cp.MountStoreWithDB(key, params.typ, nil)
+		if params.multi != nil {
+			cpParams := cp.storesParams[key]
+			cpParams.multi = params.multi.mountsCopyOn(cp.storeDB(cpParams))
+			cp.storesParams[key] = cpParams
+			cp.stores[key] = cpParams.multi
+		}
+	}
+	return cp
+}
+
+// loadedCommitInfo returns the commitInfo of the loaded stores at version ver
+func (rs *rootMultiStore) loadedCommitInfo(ver int64) commitInfo {
+	storeInfos := make([]storeInfo, 0, len(rs.stores))
+	for key, store := range rs.stores {
+		if store.GetStoreType() == sdk.StoreTypeTransient {
+			continue
+		}
+		si := storeInfo{}
+		si.Name = key.Name()
+		si.Core.CommitID = store.LastCommitID()
+		storeInfos = append(storeInfos, si)
+	}
+	return commitInfo{Version: ver, StoreInfos: storeInfos}
+}
+
+// verifyIAVLStores verifies the IAVL stores loaded at version ver, including
+// those of nested multistores, against their root hashes
+func (rs *rootMultiStore) verifyIAVLStores(ver int64) error {
+	for key, store := range rs.stores {
+		var err error
+		switch store := store.(type) {
+		case *iavlStore:
+			err = verifyIAVLStore(store, ver)
+		case *rootMultiStore:
+			err = store.verifyIAVLStores(ver)
+		}
+		if err != nil {
+			return fmt.Errorf("store %s: %v", key.Name(), err)
+		}
+	}
+	return nil
+}
+
+// verifyIAVLStore checks the proof of every key of the store against its
+// root hash, and that they are all the keys counted by the root. The nodes
+// are loaded by their hash without being rehashed, so this is what ties
+// their contents, which come from the untrusted snapshot, to the root hash.
+func verifyIAVLStore(st *iavlStore, ver int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("corrupted tree: %v", r)
+		}
+	}()
+
+	root := st.LastCommitID().Hash
+	var count int64
+	itr := st.Iterator(nil, nil)
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		value, proof, err := st.tree.GetVersionedWithProof(itr.Key(), ver)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(value, itr.Value()) {
+			return fmt.Errorf("inconsistent value of key %X", itr.Key())
+		}
+		if err = proof.Verify(itr.Key(), value, root); err != nil {
+			return fmt.Errorf("invalid key %X: %v", itr.Key(), err)
+		}
+		count++
+	}
+	if size := int64(st.tree.Tree().Size()); count != size {
+		return fmt.Errorf("%d keys in a tree of size %d", count, size)
+	}
+	return nil
+}
+
+// copyDB copies the key/value pairs of src to dst
+func copyDB(dst, src dbm.DB) {
+	itr := src.Iterator(nil, nil)
+	defer itr.Close()
+	batch := dst.NewBatch()
+	for ; itr.Valid(); itr.Next() {
+		batch.Set(itr.Key(), itr.Value())
+	}
+	batch.Write()
+}
+
+//----------------------------------------
+// Snapshot items
+
+// writeSnapshotVersion writes the items of version ver of rs: its commitInfo
+// and the IAVL trees of its substores, sorted by name for determinism. The
+// items of a nested rootMultiStore are those of the store it is mounted as,
+// named name, with its keys under prefix.
+func (rs *rootMultiStore) writeSnapshotVersion(w io.Writer, name string, prefix []byte, ver int64) error {
+	cInfoKey := []byte(fmt.Sprintf(commitInfoKeyFmt, ver))
+	cInfoBz := rs.db.Get(cInfoKey)
+	if cInfoBz == nil {
+		return fmt.Errorf("Failed to snapshot rootMultiStore: no commitInfo of version %d", ver)
+	}
+	err := writeSnapshotItem(w, snapshotItem{Store: name, Key: prefixed(prefix, cInfoKey), Value: cInfoBz})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(rs.storesParams))
+	for key, params := range rs.storesParams {
+		if params.typ == sdk.StoreTypeTransient {
+			continue
+		}
+		names = append(names, key.Name())
+	}
+	sort.Strings(names)
+	for _, subName := range names {
+		params := rs.storesParams[rs.keysByName[subName]]
+		itemStore, itemPrefix := subName, []byte(nil)
+		if name != "" {
+			if params.db != nil {
+				return fmt.Errorf("Failed to snapshot store %s: nested stores can't have their own db", subName)
+			}
+			itemStore, itemPrefix = name, prefixed(prefix, []byte(storeKeyPrefix+subName+"/"))
+		}
+		switch params.typ {
+		case sdk.StoreTypeMulti:
+			err = params.multi.writeSnapshotVersion(w, itemStore, itemPrefix, ver)
+		case sdk.StoreTypeIAVL:
+			err = writeSnapshotIAVL(w, itemStore, itemPrefix, rs.storeDB(params), ver)
+		default:
+			err = fmt.Errorf("Failed to snapshot store %s: unsupported store type %v", subName, params.typ)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSnapshotIAVL writes the root of version ver of the IAVL tree in db,
+// and the nodes reachable from it, in depth-first order
+func writeSnapshotIAVL(w io.Writer, name string, prefix []byte, db dbm.DB, ver int64) error {
+	rootKey := []byte(fmt.Sprintf(iavlRootKeyFmt, ver))
+	if !db.Has(rootKey) {
+		return fmt.Errorf("Failed to snapshot store %s: no IAVL root of version %d", name, ver)
+	}
+	rootHash := db.Get(rootKey)
+	err := writeSnapshotItem(w, snapshotItem{Store: name, Key: prefixed(prefix, rootKey), Value: rootHash})
+	if err != nil {
+		return err
+	}
+	if len(rootHash) == 0 {
+		return nil // empty tree
+	}
+
+	hashes := [][]byte{rootHash}
+	for len(hashes) > 0 {
+		hash := hashes[len(hashes)-1]
+		hashes = hashes[:len(hashes)-1]
+		nodeKey := []byte(fmt.Sprintf(iavlNodeKeyFmt, hash))
+		node := db.Get(nodeKey)
+		if node == nil {
+			return fmt.Errorf("Failed to snapshot store %s: missing IAVL node %X", name, hash)
+		}
+		err = writeSnapshotItem(w, snapshotItem{Store: name, Key: prefixed(prefix, nodeKey), Value: node})
+		if err != nil {
+			return err
+		}
+		left, right, err := iavlNodeChildren(node)
+		if err != nil {
+			return fmt.Errorf("Failed to snapshot store %s: IAVL node %X: %v", name, hash, err)
+		}
+		if left != nil {
+			hashes = append(hashes, right, left)
+		}
+	}
+	return nil
+}
+
+// iavlNodeChildren decodes the hashes of the children of an IAVL node, as
+// serialized by iavl: height, size, version, key, then the value of a leaf
+// or the hashes of the children of an inner node. They are nil for a leaf.
+func iavlNodeChildren(bz []byte) (left, right []byte, err error) {
+	height, n, err := amino.DecodeInt8(bz)
+	if err != nil || height == 0 {
+		return nil, nil, err
+	}
+	bz = bz[n:]
+	for i := 0; i < 2; i++ { // size, version
+		if _, n, err = amino.DecodeVarint(bz); err != nil {
+			return nil, nil, err
+		}
+		bz = bz[n:]
+	}
+	if _, n, err = amino.DecodeByteSlice(bz); err != nil { // key
+		return nil, nil, err
+	}
+	bz = bz[n:]
+	if left, n, err = amino.DecodeByteSlice(bz); err != nil {
+		return nil, nil, err
+	}
+	bz = bz[n:]
+	if right, _, err = amino.DecodeByteSlice(bz); err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+func prefixed(prefix, key []byte) []byte {
+	return append(append([]byte{}, prefix...), key...)
+}
+
+func writeSnapshotItem(w io.Writer, item snapshotItem) error {
+	bz, err := cdc.MarshalBinaryBare(item)
+	if err != nil {
+		return err
+	}
+	var lenBz [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBz[:], uint64(len(bz)))
+	if _, err = w.Write(lenBz[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(bz)
+	return err
+}
+
+// readSnapshotItem returns io.EOF only if r ends before the item
+func readSnapshotItem(r *bufio.Reader) (item snapshotItem, err error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return
+	}
+	if size > maxSnapshotItemSize {
+		return item, fmt.Errorf("snapshot item too big: %d bytes", size)
+	}
+	bz := make([]byte, size)
+	if _, err = io.ReadFull(r, bz); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	err = cdc.UnmarshalBinaryBare(bz, &item)
+	return
+}
+
+func readSnapshotManifest(snapshotDir string) (manifest SnapshotManifest, err error) {
+	bz, err := ioutil.ReadFile(filepath.Join(snapshotDir, snapshotManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("Failed to read snapshot manifest: %v", err)
+	}
+	if err = json.Unmarshal(bz, &manifest); err != nil {
+		return manifest, fmt.Errorf("Failed to read snapshot manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+//----------------------------------------
+// Snapshot chunks
+
+// snapshotChunkWriter splits what is written to it into chunk files
+type snapshotChunkWriter struct {
+	dir       string
+	chunkSize int
+	buf       bytes.Buffer
+	hashes    [][]byte
+}
+
+// Implements io.Writer.
+func (w *snapshotChunkWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for w.buf.Len() >= w.chunkSize {
+		if err := w.writeChunk(w.buf.Next(w.chunkSize)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes the remaining bytes and returns the hashes of all chunks
+func (w *snapshotChunkWriter) Close() ([][]byte, error) {
+	if w.buf.Len() > 0 {
+		if err := w.writeChunk(w.buf.Next(w.buf.Len())); err != nil {
+			return nil, err
+		}
+	}
+	return w.hashes, nil
+}
+
+func (w *snapshotChunkWriter) writeChunk(chunk []byte) error {
+	path := filepath.Join(w.dir, fmt.Sprintf(snapshotChunkFmt, len(w.hashes)))
+	if err := ioutil.WriteFile(path, chunk, 0644); err != nil {
+		return err
+	}
+	hash := sha256.Sum256(chunk)
+	w.hashes = append(w.hashes, hash[:])
+	return nil
+}
+
+// snapshotChunkReader reads the chunk files in order, verifying each
+// against its hash before returning any of its bytes
+type snapshotChunkReader struct {
+	dir    string
+	hashes [][]byte
+	next   int
+	chunk  *bytes.Reader
+}
+
+// Implements io.Reader.
+func (r *snapshotChunkReader) Read(p []byte) (int, error) {
+	for r.chunk == nil || r.chunk.Len() == 0 {
+		if r.next == len(r.hashes) {
+			return 0, io.EOF
+		}
+		chunk, err := ioutil.ReadFile(filepath.Join(r.dir, fmt.Sprintf(snapshotChunkFmt, r.next)))
+		if err != nil {
+			return 0, err
+		}
+		hash := sha256.Sum256(chunk)
+		if !bytes.Equal(hash[:], r.hashes[r.next]) {
+			return 0, fmt.Errorf("snapshot chunk %d does not match its hash", r.next)
+		}
+		r.chunk = bytes.NewReader(chunk)
+		r.next++
+	}
+	return r.chunk.Read(p)
+}
@@ -0,0 +1,262 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func newSnapshotMultiStore(t *testing.T, db dbm.DB) *rootMultiStore {
+	store := NewCommitMultiStore(db)
+	store.MountStoreWithDB(sdk.NewKVStoreKey("store1"), sdk.StoreTypeIAVL, nil)
+	store.MountStoreWithDB(sdk.NewKVStoreKey("store2"), sdk.StoreTypeIAVL, nil)
+	require.Nil(t, store.LoadLatestVersion())
+	return store
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+
+	// nothing to snapshot before the first commit
+	_, err = store.Snapshot(dir, 1, 100)
+	assert.NotNil(t, err)
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 20; j++ {
+			key := []byte(fmt.Sprintf("key-%d-%d", i, j))
+			store.getStoreByName("store1").(KVStore).Set(key, []byte("value1"))
+			store.getStoreByName("store2").(KVStore).Set(key, []byte("value2"))
+		}
+		store.Commit()
+	}
+	commitID := store.LastCommitID()
+
+	manifest, err := store.Snapshot(dir, commitID.Version, 100)
+	require.Nil(t, err)
+	assert.Equal(t, commitID, manifest.CommitID)
+	assert.True(t, len(manifest.ChunkHashes) > 1)
+
+	// older versions can still be snapshotted
+	old, err := store.Snapshot(dir, commitID.Version-1, 100)
+	require.Nil(t, err)
+	assert.Equal(t, commitID.Version-1, old.CommitID.Version)
+
+	// restore into an empty store
+	restored := newSnapshotMultiStore(t, dbm.NewMemDB())
+	require.Nil(t, restored.Restore(dir, commitID.Version, commitID.Hash))
+	assert.Equal(t, commitID, restored.LastCommitID())
+	assert.Equal(t, commitID.Version, getLatestVersion(restored.db))
+	store2 := restored.getStoreByName("store2").(KVStore)
+	assert.Equal(t, []byte("value2"), store2.Get([]byte("key-2-19")))
+
+	// only the tree of the version is restored, without older versions or orphans
+	db1 := restored.storeDB(restored.storesParams[restored.keysByName["store1"]])
+	assert.Equal(t, 1, countKeys(db1, iavlRootPrefix))
+	assert.Equal(t, 0, countKeys(db1, iavlOrphanPrefix))
+	_, err = restored.CacheMultiStoreWithVersion(commitID.Version - 1)
+	assert.NotNil(t, err)
+
+	// the restored store keeps committing from there
+	assert.Equal(t, store.Commit(), restored.Commit())
+
+	// a store with state can't be restored into
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, commitID.Hash))
+
+	// no snapshot of the version
+	assert.NotNil(t, newSnapshotMultiStore(t, dbm.NewMemDB()).Restore(dir, commitID.Version+1, commitID.Hash))
+}
+
+func countKeys(db dbm.DB, prefix string) (n int) {
+	itr := db.Iterator([]byte(prefix), sdk.PrefixEndBytes([]byte(prefix)))
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		n++
+	}
+	return
+}
+
+func TestSnapshotSizeIndependentOfHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// two stores with the same state, one with a long history of overwrites
+	snapshotSize := func(versions int) int {
+		store := newSnapshotMultiStore(t, dbm.NewMemDB())
+		for v := 0; v < versions; v++ {
+			for j := 0; j < 10; j++ {
+				value := []byte(fmt.Sprintf("value-%d", versions-1-v))
+				store.getStoreByName("store1").(KVStore).Set([]byte(fmt.Sprintf("key-%d", j)), value)
+			}
+			store.Commit()
+		}
+		manifest, err := store.Snapshot(dir, store.LastCommitID().Version, 1<<20)
+		require.Nil(t, err)
+		chunk, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf("%d", manifest.CommitID.Version), fmt.Sprintf(snapshotChunkFmt, 0)))
+		require.Nil(t, err)
+		return len(chunk)
+	}
+	short, long := snapshotSize(2), snapshotSize(20)
+	assert.InDelta(t, short, long, float64(short)/10)
+}
+
+func TestSnapshotRestoreCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+	store.getStoreByName("store1").(KVStore).Set([]byte("key"), []byte("value"))
+	commitID := store.Commit()
+
+	_, err = store.Snapshot(dir, commitID.Version, 32)
+	require.Nil(t, err)
+
+	// corrupt a chunk
+	chunkPath := filepath.Join(dir, fmt.Sprintf("%d", commitID.Version), fmt.Sprintf(snapshotChunkFmt, 1))
+	chunk, err := ioutil.ReadFile(chunkPath)
+	require.Nil(t, err)
+	chunk[0] ^= 0xff
+	require.Nil(t, ioutil.WriteFile(chunkPath, chunk, 0644))
+
+	restored := newSnapshotMultiStore(t, dbm.NewMemDB())
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, commitID.Hash))
+	assert.Equal(t, int64(0), getLatestVersion(restored.db))
+}
+
+func TestSnapshotRestoreMismatchedStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+	store.getStoreByName("store1").(KVStore).Set([]byte("key"), []byte("value"))
+	store.getStoreByName("store2").(KVStore).Set([]byte("key"), []byte("value"))
+	commitID := store.Commit()
+	_, err = store.Snapshot(dir, commitID.Version, 1024)
+	require.Nil(t, err)
+
+	// a store which doesn't mount store2 can't restore its data
+	restored := NewCommitMultiStore(dbm.NewMemDB())
+	restored.MountStoreWithDB(sdk.NewKVStoreKey("store1"), sdk.StoreTypeIAVL, nil)
+	require.Nil(t, restored.LoadLatestVersion())
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, commitID.Hash))
+	assert.Equal(t, int64(0), getLatestVersion(restored.db))
+}
+
+func TestSnapshotRestoreUntrustedHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+	store.getStoreByName("store1").(KVStore).Set([]byte("key"), []byte("value"))
+	commitID := store.Commit()
+	_, err = store.Snapshot(dir, commitID.Version, 1024)
+	require.Nil(t, err)
+	trusted := []byte("trusted app hash")
+
+	// the snapshot doesn't match the trusted app hash
+	restored := newSnapshotMultiStore(t, dbm.NewMemDB())
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, trusted))
+
+	// nor does its data, even if its manifest claims so
+	manifestPath := filepath.Join(dir, fmt.Sprintf("%d", commitID.Version), snapshotManifestFile)
+	bz, err := ioutil.ReadFile(manifestPath)
+	require.Nil(t, err)
+	var manifest SnapshotManifest
+	require.Nil(t, json.Unmarshal(bz, &manifest))
+	manifest.CommitID.Hash = trusted
+	bz, err = json.Marshal(manifest)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(manifestPath, bz, 0644))
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, trusted))
+
+	// and nothing is written to the live dbs
+	assert.Equal(t, int64(0), getLatestVersion(restored.db))
+	assert.Nil(t, restored.db.Get([]byte(fmt.Sprintf(commitInfoKeyFmt, commitID.Version))))
+	db1 := restored.storeDB(restored.storesParams[restored.keysByName["store1"]])
+	assert.Equal(t, 0, countKeys(db1, iavlRootPrefix))
+}
+
+func TestSnapshotRestoreTamperedNodes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+	store.getStoreByName("store1").(KVStore).Set([]byte("key1"), []byte("value1"))
+	store.getStoreByName("store1").(KVStore).Set([]byte("key2"), []byte("value2"))
+	commitID := store.Commit()
+	_, err = store.Snapshot(dir, commitID.Version, 1<<20)
+	require.Nil(t, err)
+
+	// tamper with the value of a leaf, keeping the key of its node, and
+	// rewrite the untrusted chunk hashes of the manifest to match
+	snapshotDir := filepath.Join(dir, fmt.Sprintf("%d", commitID.Version))
+	chunkPath := filepath.Join(snapshotDir, fmt.Sprintf(snapshotChunkFmt, 0))
+	chunk, err := ioutil.ReadFile(chunkPath)
+	require.Nil(t, err)
+	require.Equal(t, 1, bytes.Count(chunk, []byte("value1")))
+	chunk = bytes.Replace(chunk, []byte("value1"), []byte("forged"), 1)
+	require.Nil(t, ioutil.WriteFile(chunkPath, chunk, 0644))
+	manifest, err := readSnapshotManifest(snapshotDir)
+	require.Nil(t, err)
+	hash := sha256.Sum256(chunk)
+	manifest.ChunkHashes = [][]byte{hash[:]}
+	bz, err := json.Marshal(manifest)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(snapshotDir, snapshotManifestFile), bz, 0644))
+
+	// the root hashes match the app hash, but the tree doesn't
+	restored := newSnapshotMultiStore(t, dbm.NewMemDB())
+	assert.NotNil(t, restored.Restore(dir, commitID.Version, commitID.Hash))
+	assert.Equal(t, int64(0), getLatestVersion(restored.db))
+	db1 := restored.storeDB(restored.storesParams[restored.keysByName["store1"]])
+	assert.Equal(t, 0, countKeys(db1, iavlRootPrefix))
+}
+
+func TestSnapshotHeldVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newSnapshotMultiStore(t, dbm.NewMemDB())
+	store.SetPruning(sdk.PruneEverything)
+	commit := func() CommitID {
+		store.getStoreByName("store1").(KVStore).Set([]byte("key"), []byte(fmt.Sprintf("%d", store.LastCommitID().Version)))
+		return store.Commit()
+	}
+	commitID := commit()
+
+	// a held version isn't pruned by later commits, and can be snapshotted
+	unhold := store.HoldVersion(commitID.Version)
+	commit()
+	commit()
+	assert.True(t, store.hasCommitInfo(commitID.Version))
+	manifest, err := store.Snapshot(dir, commitID.Version, 100)
+	require.Nil(t, err)
+	assert.Equal(t, commitID, manifest.CommitID)
+
+	// it is pruned by the first commit once it isn't held anymore
+	unhold()
+	commit()
+	assert.False(t, store.hasCommitInfo(commitID.Version))
+	_, err = store.CacheMultiStoreWithVersion(commitID.Version)
+	assert.NotNil(t, err)
+}
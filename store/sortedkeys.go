@@ -0,0 +1,82 @@
+package store
+
+import (
+	"sort"
+)
+
+// Chunks of a sortedKeys are split in two when they grow past this size.
+const maxSortedKeysChunk = 512
+
+// sortedKeys is a set of keys kept sorted as keys are added, so that the
+// keys of a range are found without sorting the whole set. It is a b+tree
+// of height two: a sorted list of sorted chunks of keys.
+type sortedKeys struct {
+	chunks [][]string
+}
+
+// add adds a key to the set, if it isn't in the set already
+func (sk *sortedKeys) add(key string) {
+	if len(sk.chunks) == 0 {
+		sk.chunks = [][]string{{key}}
+		return
+	}
+
+	// The key goes to the first chunk ending at or after it, or to the last.
+	c := sk.searchChunk(key)
+	if c == len(sk.chunks) {
+		c--
+	}
+	chunk := sk.chunks[c]
+	i := sort.SearchStrings(chunk, key)
+	if i < len(chunk) && chunk[i] == key {
+		return
+	}
+	chunk = append(chunk, "")
+	copy(chunk[i+1:], chunk[i:])
+	chunk[i] = key
+	sk.chunks[c] = chunk
+	if len(chunk) <= maxSortedKeysChunk {
+		return
+	}
+
+	// Split the chunk, the second half in a new chunk after it.
+	half := len(chunk) / 2
+	right := append(make([]string, 0, maxSortedKeysChunk), chunk[half:]...)
+	sk.chunks[c] = chunk[:half]
+	sk.chunks = append(sk.chunks, nil)
+	copy(sk.chunks[c+2:], sk.chunks[c+1:])
+	sk.chunks[c+1] = right
+}
+
+// keys returns the sorted keys from start to end, both inclusive. A nil
+// start or end leaves the range open on that side.
+func (sk *sortedKeys) keys(start, end []byte) []string {
+	c, i := 0, 0
+	if start != nil {
+		c = sk.searchChunk(string(start))
+		if c < len(sk.chunks) {
+			i = sort.SearchStrings(sk.chunks[c], string(start))
+		}
+	}
+
+	var keys []string
+	for ; c < len(sk.chunks); c, i = c+1, 0 {
+		chunk := sk.chunks[c]
+		for ; i < len(chunk); i++ {
+			if end != nil && chunk[i] > string(end) {
+				return keys
+			}
+			keys = append(keys, chunk[i])
+		}
+	}
+	return keys
+}
+
+// searchChunk returns the index of the first chunk whose last key is not
+// less than key, or the number of chunks if there is none
+func (sk *sortedKeys) searchChunk(key string) int {
+	return sort.Search(len(sk.chunks), func(c int) bool {
+		chunk := sk.chunks[c]
+		return chunk[len(chunk)-1] >= key
+	})
+}
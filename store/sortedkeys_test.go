@@ -0,0 +1,45 @@
+package store
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedKeys(t *testing.T) {
+	var sk sortedKeys
+	assert.Nil(t, sk.keys(nil, nil))
+
+	// add keys in random order, some twice, across several chunks
+	set := make(map[string]bool)
+	for i := 0; i < 5*maxSortedKeysChunk; i++ {
+		key := string(keyFmt(randInt(4 * maxSortedKeysChunk)))
+		sk.add(key)
+		set[key] = true
+	}
+	expected := make([]string, 0, len(set))
+	for key := range set {
+		expected = append(expected, key)
+	}
+	sort.Strings(expected)
+	assert.Equal(t, expected, sk.keys(nil, nil))
+	assert.True(t, len(sk.chunks) > 1)
+	for _, chunk := range sk.chunks {
+		assert.True(t, len(chunk) <= maxSortedKeysChunk)
+	}
+
+	// ranges are inclusive of both bounds
+	for i := 0; i < 100; i++ {
+		start, end := randRange(4 * maxSortedKeysChunk)
+		var inRange []string
+		for _, key := range expected {
+			if (start == nil || key >= string(start)) && (end == nil || key <= string(end)) {
+				inRange = append(inRange, key)
+			}
+		}
+		assert.Equal(t, inRange, sk.keys(start, end))
+	}
+	assert.Equal(t, []string{expected[0]}, sk.keys(nil, []byte(expected[0])))
+	assert.Nil(t, sk.keys([]byte("z"), nil))
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ KVStore = (*transientStore)(nil)
+var _ CommitStore = (*transientStore)(nil)
+
+// transientStore is an in-memory KVStore which is emptied on every Commit.
+// It holds no history and is left out of the commitInfo, so its content
+// never reaches the disk nor the app hash.
+type transientStore struct {
+	dbStoreAdapter
+}
+
+func newTransientStore() *transientStore {
+	return &transientStore{dbStoreAdapter{dbm.NewMemDB()}}
+}
+
+// Implements Committer. Empties the store.
+func (ts *transientStore) Commit() CommitID {
+	ts.dbStoreAdapter = dbStoreAdapter{dbm.NewMemDB()}
+	return CommitID{}
+}
+
+// Implements Committer.
+func (ts *transientStore) LastCommitID() CommitID {
+	return CommitID{}
+}
+
+// Implements Store.
+func (ts *transientStore) GetStoreType() StoreType {
+	return sdk.StoreTypeTransient
+}
+
+// Implements Store.
+func (ts *transientStore) CacheWrap() CacheWrap {
+	return NewCacheKVStore(ts)
+}
+
+// Implements KVStore.
+func (ts *transientStore) Prefix(prefix []byte) KVStore {
+	return NewPrefixStore(ts, prefix)
+}
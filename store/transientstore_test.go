@@ -0,0 +1,75 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestTransientStore(t *testing.T) {
+	tstore := newTransientStore()
+	k, v := []byte("key"), []byte("value")
+
+	tstore.Set(k, v)
+	assert.Equal(t, v, tstore.Get(k))
+
+	// cached writes go to the current content
+	cache := tstore.CacheWrap().(CacheKVStore)
+	cache.Set([]byte("key2"), v)
+	cache.Write()
+	assert.Equal(t, v, tstore.Get([]byte("key2")))
+
+	// Commit empties the store
+	assert.Equal(t, CommitID{}, tstore.Commit())
+	assert.Nil(t, tstore.Get(k))
+	assert.Nil(t, tstore.Get([]byte("key2")))
+	assert.Equal(t, CommitID{}, tstore.LastCommitID())
+}
+
+func TestMultiStoreTransient(t *testing.T) {
+	db := dbm.NewMemDB()
+	newStore := func() *rootMultiStore {
+		multi := NewCommitMultiStore(db)
+		multi.MountStoreWithDB(sdk.NewKVStoreKey("store1"), sdk.StoreTypeIAVL, nil)
+		multi.MountStoreWithDB(sdk.NewKVStoreKey("transient"), sdk.StoreTypeTransient, nil)
+		return multi
+	}
+	multi := newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+	key1 := multi.keysByName["store1"]
+	tkey := multi.keysByName["transient"]
+
+	k, v := []byte("key"), []byte("value")
+	multi.GetKVStore(key1).Set(k, v)
+	tstore := multi.GetKVStore(tkey)
+	assert.Equal(t, sdk.StoreTypeTransient, tstore.GetStoreType())
+	tstore.Set(k, v)
+
+	// visible through a cache of the multistore
+	cache := multi.CacheMultiStore()
+	assert.Equal(t, v, cache.GetKVStore(tkey).Get(k))
+	cache.GetKVStore(tkey).Set([]byte("key2"), v)
+	cache.Write()
+	assert.Equal(t, v, tstore.Get([]byte("key2")))
+
+	// the transient store is reset on Commit, and doesn't affect the hash
+	cid := multi.Commit()
+	assert.Nil(t, multi.GetKVStore(tkey).Get(k))
+	cInfo, err := getCommitInfo(db, cid.Version)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(cInfo.StoreInfos))
+	assert.Equal(t, "store1", cInfo.StoreInfos[0].Name)
+
+	// the transient store is mounted again on load
+	multi = newStore()
+	require.Nil(t, multi.LoadLatestVersion())
+	assert.Equal(t, cid, multi.LastCommitID())
+	assert.NotNil(t, multi.GetKVStore(multi.keysByName["transient"]))
+	hist, err := multi.CacheMultiStoreWithVersion(cid.Version)
+	require.Nil(t, err)
+	assert.Nil(t, hist.GetKVStore(multi.keysByName["transient"]).Get(k))
+}
@@ -20,6 +20,10 @@ type CommitKVStore = types.CommitKVStore
 type CacheWrapper = types.CacheWrapper
 type CacheWrap = types.CacheWrap
 type CommitID = types.CommitID
+type SnapshotManifest = types.SnapshotManifest
+type PruningOptions = types.PruningOptions
+type GasConfig = types.GasConfig
+type WriteListener = types.WriteListener
 type StoreKey = types.StoreKey
 type StoreType = types.StoreType
 type Queryable = types.Queryable
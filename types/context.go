@@ -46,6 +46,8 @@ func NewContext(ms MultiStore, header abci.Header, isCheckTx bool, txBytes []byt
 	c = c.WithLogger(logger)
 	c = c.WithSigningValidators(nil)
 	c = c.WithGasMeter(NewInfiniteGasMeter())
+	c = c.WithBlockGasMeter(NewInfiniteGasMeter())
+	c = c.WithMinimumGasPrices(GasPrices(nil))
 	return c
 }
 
@@ -71,7 +73,15 @@ func (c Context) Value(key interface{}) interface{} {
 
 // KVStore fetches a KVStore from the MultiStore.
 func (c Context) KVStore(key StoreKey) KVStore {
-	return c.multiStore().GetKVStoreWithGas(c.GasMeter(), key)
+	ms := c.multiStore()
+	return ms.GetKVStoreWithGas(c.GasMeter(), ms.GetGasConfig(key), key)
+}
+
+// NestedKVStore fetches a KVStore from the MultiStore nested in the
+// MultiStore at key.
+func (c Context) NestedKVStore(key, nestedKey StoreKey) KVStore {
+	nested := c.multiStore().GetStore(key).(MultiStore)
+	return nested.GetKVStoreWithGas(c.GasMeter(), nested.GetGasConfig(nestedKey), nestedKey)
 }
 
 //----------------------------------------
@@ -132,6 +142,8 @@ const (
 	contextKeyLogger
 	contextKeySigningValidators
 	contextKeyGasMeter
+	contextKeyBlockGasMeter
+	contextKeyMinimumGasPrices
 )
 
 // NOTE: Do not expose MultiStore.
@@ -166,6 +178,12 @@ func (c Context) SigningValidators() []abci.SigningValidator {
 func (c Context) GasMeter() GasMeter {
 	return c.Value(contextKeyGasMeter).(GasMeter)
 }
+func (c Context) BlockGasMeter() GasMeter {
+	return c.Value(contextKeyBlockGasMeter).(GasMeter)
+}
+func (c Context) MinimumGasPrices() GasPrices {
+	return c.Value(contextKeyMinimumGasPrices).(GasPrices)
+}
 func (c Context) WithMultiStore(ms MultiStore) Context {
 	return c.withValue(contextKeyMultiStore, ms)
 }
@@ -194,6 +212,12 @@ func (c Context) WithSigningValidators(SigningValidators []abci.SigningValidator
 func (c Context) WithGasMeter(meter GasMeter) Context {
 	return c.withValue(contextKeyGasMeter, meter)
 }
+func (c Context) WithBlockGasMeter(meter GasMeter) Context {
+	return c.withValue(contextKeyBlockGasMeter, meter)
+}
+func (c Context) WithMinimumGasPrices(prices GasPrices) Context {
+	return c.withValue(contextKeyMinimumGasPrices, prices)
+}
 
 // Cache the multistore and return a new cached context. The cached context is
 // written to the context when writeCache is called.
@@ -53,6 +53,8 @@ const (
 	CodeInsufficientCoins CodeType = 10
 	CodeInvalidCoins      CodeType = 11
 	CodeOutOfGas          CodeType = 12
+	CodeInsufficientFee   CodeType = 13
+	CodePrunedHeight      CodeType = 14
 
 	// CodespaceRoot is a codespace for error codes in this file only.
 	// Notice that 0 is an "unset" codespace, which can be overridden with
@@ -91,6 +93,10 @@ func CodeToDefaultMsg(code CodeType) string {
 		return "Invalid coins"
 	case CodeOutOfGas:
 		return "Out of gas"
+	case CodeInsufficientFee:
+		return "Insufficient fee"
+	case CodePrunedHeight:
+		return "Height was pruned"
 	default:
 		return fmt.Sprintf("Unknown code %d", code)
 	}
@@ -137,6 +143,12 @@ func ErrInvalidCoins(msg string) Error {
 func ErrOutOfGas(msg string) Error {
 	return newErrorWithRootCodespace(CodeOutOfGas, msg)
 }
+func ErrInsufficientFee(msg string) Error {
+	return newErrorWithRootCodespace(CodeInsufficientFee, msg)
+}
+func ErrPrunedHeight(msg string) Error {
+	return newErrorWithRootCodespace(CodePrunedHeight, msg)
+}
 
 //----------------------------------------
 // Error & sdkError
@@ -16,6 +16,8 @@ var codeTypes = []CodeType{
 	CodeUnknownRequest,
 	CodeUnknownAddress,
 	CodeInvalidPubKey,
+	CodeInsufficientFee,
+	CodePrunedHeight,
 }
 
 type errFn func(msg string) Error
@@ -29,6 +31,8 @@ var errFns = []errFn{
 	ErrUnknownRequest,
 	ErrUnknownAddress,
 	ErrInvalidPubKey,
+	ErrInsufficientFee,
+	ErrPrunedHeight,
 }
 
 func TestCodeType(t *testing.T) {
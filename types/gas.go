@@ -10,10 +10,40 @@ type ErrorOutOfGas struct {
 	Descriptor string
 }
 
+// GasConfig is the gas schedule of a KVStore. Reads and writes are charged
+// a flat cost plus a cost per byte of the key and value.
+type GasConfig struct {
+	HasCost          Gas
+	DeleteCost       Gas
+	ReadCostFlat     Gas
+	ReadCostPerByte  Gas
+	WriteCostFlat    Gas
+	WriteCostPerByte Gas
+	IterNextCostFlat Gas
+}
+
+// KVGasConfig returns the default gas schedule of KVStores
+func KVGasConfig() GasConfig {
+	return GasConfig{
+		HasCost:          10,
+		DeleteCost:       10,
+		ReadCostFlat:     10,
+		ReadCostPerByte:  1,
+		WriteCostFlat:    10,
+		WriteCostPerByte: 10,
+		IterNextCostFlat: 15,
+	}
+}
+
 // GasMeter interface to track gas consumption
 type GasMeter interface {
 	GasConsumed() Gas
 	ConsumeGas(amount Gas, descriptor string)
+
+	// Limit returns the gas limit, or 0 if there is none
+	Limit() Gas
+	// IsOutOfGas returns true if no gas is left to consume
+	IsOutOfGas() bool
 }
 
 type basicGasMeter struct {
@@ -39,6 +69,14 @@ func (g *basicGasMeter) ConsumeGas(amount Gas, descriptor string) {
 	}
 }
 
+func (g *basicGasMeter) Limit() Gas {
+	return g.limit
+}
+
+func (g *basicGasMeter) IsOutOfGas() bool {
+	return g.consumed >= g.limit
+}
+
 type infiniteGasMeter struct {
 	consumed Gas
 }
@@ -56,3 +94,11 @@ func (g *infiniteGasMeter) GasConsumed() Gas {
 func (g *infiniteGasMeter) ConsumeGas(amount Gas, descriptor string) {
 	g.consumed += amount
 }
+
+func (g *infiniteGasMeter) Limit() Gas {
+	return 0
+}
+
+func (g *infiniteGasMeter) IsOutOfGas() bool {
+	return false
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasMeter(t *testing.T) {
+	meter := NewGasMeter(10)
+	assert.Equal(t, Gas(10), meter.Limit())
+	assert.False(t, meter.IsOutOfGas())
+
+	meter.ConsumeGas(4, "first")
+	assert.Equal(t, Gas(4), meter.GasConsumed())
+	assert.False(t, meter.IsOutOfGas())
+
+	meter.ConsumeGas(6, "second")
+	assert.Equal(t, Gas(10), meter.GasConsumed())
+	assert.True(t, meter.IsOutOfGas())
+
+	assert.Panics(t, func() { meter.ConsumeGas(1, "third") })
+}
+
+func TestInfiniteGasMeter(t *testing.T) {
+	meter := NewInfiniteGasMeter()
+	assert.Equal(t, Gas(0), meter.Limit())
+
+	meter.ConsumeGas(1<<40, "lots")
+	assert.Equal(t, Gas(1<<40), meter.GasConsumed())
+	assert.False(t, meter.IsOutOfGas())
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GasPrice is the price of one unit of gas in a coin denomination
+type GasPrice struct {
+	Denom  string `json:"denom"`
+	Amount Rat    `json:"amount"`
+}
+
+// String provides a human-readable representation of a gas price
+func (price GasPrice) String() string {
+	return fmt.Sprintf("%v%v", price.Amount.RatString(), price.Denom)
+}
+
+// GasPrices are the accepted prices of gas, at most one per denomination
+type GasPrices []GasPrice
+
+func (prices GasPrices) String() string {
+	if len(prices) == 0 {
+		return ""
+	}
+
+	out := ""
+	for _, price := range prices {
+		out += fmt.Sprintf("%v,", price.String())
+	}
+	return out[:len(out)-1]
+}
+
+// IsAcceptedFee returns true if fee pays for gas at one of the prices or
+// above, ie. fee holds at least price * gas of one of the denominations.
+// Any fee is accepted if there are no prices.
+func (prices GasPrices) IsAcceptedFee(fee Coins, gas Gas) bool {
+	if len(prices) == 0 {
+		return true
+	}
+	for _, price := range prices {
+		required := price.Amount.Mul(NewRat(gas))
+		if !NewRat(fee.AmountOf(price.Denom)).LT(required) {
+			return true
+		}
+	}
+	return false
+}
+
+//----------------------------------------
+// Parsing
+
+var reGasPrice = regexp.MustCompile(fmt.Sprintf(`^(%s(?:\.%s)?)%s(%s)$`, reAmt, reAmt, reSpc, reDnm))
+
+// ParseGasPrices parses a list of gas prices separated by commas,
+// eg. "0.025steak,1photino". If nothing is provided, it returns nil
+// GasPrices. Returned prices are sorted by denomination.
+func ParseGasPrices(pricesStr string) (prices GasPrices, err error) {
+	pricesStr = strings.TrimSpace(pricesStr)
+	if len(pricesStr) == 0 {
+		return nil, nil
+	}
+
+	for _, priceStr := range strings.Split(pricesStr, ",") {
+		priceStr = strings.TrimSpace(priceStr)
+		matches := reGasPrice.FindStringSubmatch(priceStr)
+		if matches == nil {
+			return nil, fmt.Errorf("Invalid gas price expression: %s", priceStr)
+		}
+		amount, ratErr := NewRatFromDecimal(matches[1])
+		if ratErr != nil {
+			return nil, fmt.Errorf("Invalid gas price expression: %s", priceStr)
+		}
+		prices = append(prices, GasPrice{matches[2], amount})
+	}
+
+	// Sort prices for determinism, and reject duplicate denominations.
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Denom < prices[j].Denom })
+	for i := 1; i < len(prices); i++ {
+		if prices[i].Denom == prices[i-1].Denom {
+			return nil, fmt.Errorf("Duplicate gas price denomination: %s", prices[i].Denom)
+		}
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGasPrices(t *testing.T) {
+	cases := []struct {
+		input    string
+		valid    bool
+		expected string
+	}{
+		{"", true, ""},
+		{"1atom", true, "1atom"},
+		{"0.025steak", true, "1/40steak"},
+		{" 2 photon , 0.5atom ", true, "1/2atom,2photon"},
+		{"-1atom", false, ""},
+		{"1.atom", false, ""},
+		{"1atom,2atom", false, ""},
+		{"atom", false, ""},
+		{"1a", false, ""},
+	}
+
+	for i, tc := range cases {
+		prices, err := ParseGasPrices(tc.input)
+		if !tc.valid {
+			assert.NotNil(t, err, "%d: %s", i, tc.input)
+			continue
+		}
+		require.Nil(t, err, "%d: %s", i, tc.input)
+		assert.Equal(t, tc.expected, prices.String(), "%d: %s", i, tc.input)
+	}
+}
+
+func TestGasPricesIsAcceptedFee(t *testing.T) {
+	prices, err := ParseGasPrices("0.5atom,2photon")
+	require.Nil(t, err)
+
+	cases := []struct {
+		fee      Coins
+		gas      Gas
+		accepted bool
+	}{
+		{Coins{}, 0, true},
+		{Coins{}, 10, false},
+		{Coins{{"atom", 5}}, 10, true},
+		{Coins{{"atom", 4}}, 10, false},
+		{Coins{{"atom", 4}, {"photon", 20}}, 10, true},
+		{Coins{{"photon", 19}}, 10, false},
+		{Coins{{"steak", 1000}}, 10, false},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(t, tc.accepted, prices.IsAcceptedFee(tc.fee, tc.gas), "%d", i)
+	}
+
+	// without prices any fee is accepted
+	assert.True(t, GasPrices(nil).IsAcceptedFee(Coins{}, 10))
+}
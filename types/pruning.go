@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// PruningOptions specify which versions of the state a CommitMultiStore
+// keeps: besides the latest one, the KeepRecent versions before it, and
+// forever every version which is a multiple of KeepEvery.
+// Other versions are deleted as new versions are committed.
+type PruningOptions struct {
+	KeepRecent int64 // number of versions kept before the latest
+	KeepEvery  int64 // versions kept forever are multiples of this, none if 0
+}
+
+// nolint - named pruning strategies
+var (
+	PruneNothing    = PruningOptions{KeepEvery: 1}
+	PruneEverything = PruningOptions{}
+)
+
+// Releases returns the version which may be deleted once version is
+// committed, if any
+func (opts PruningOptions) Releases(version int64) (release int64, ok bool) {
+	release = version - 1 - opts.KeepRecent
+	if release < 1 {
+		return 0, false
+	}
+	if opts.KeepEvery > 0 && release%opts.KeepEvery == 0 {
+		return 0, false
+	}
+	return release, true
+}
+
+func (opts PruningOptions) String() string {
+	switch opts {
+	case PruneNothing:
+		return "nothing"
+	case PruneEverything:
+		return "everything"
+	default:
+		return fmt.Sprintf("keep-every-%d-plus-recent-%d", opts.KeepEvery, opts.KeepRecent)
+	}
+}
+
+var rePruningKeepEvery = regexp.MustCompile(`^keep-every-([0-9]+)-plus-recent-([0-9]+)$`)
+
+// ParsePruningOptions parses a named pruning strategy: "nothing",
+// "everything", or "keep-every-N-plus-recent-M", eg. "keep-every-1000-plus-recent-100"
+func ParsePruningOptions(strategy string) (opts PruningOptions, err error) {
+	switch strategy {
+	case "nothing":
+		return PruneNothing, nil
+	case "everything":
+		return PruneEverything, nil
+	}
+
+	matches := rePruningKeepEvery.FindStringSubmatch(strategy)
+	if matches == nil {
+		return opts, fmt.Errorf("Invalid pruning strategy: %s", strategy)
+	}
+	opts.KeepEvery, err = strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return opts, fmt.Errorf("Invalid pruning strategy: %s", strategy)
+	}
+	opts.KeepRecent, err = strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return opts, fmt.Errorf("Invalid pruning strategy: %s", strategy)
+	}
+	return opts, nil
+}
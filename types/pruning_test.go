@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePruningOptions(t *testing.T) {
+	cases := []struct {
+		input    string
+		valid    bool
+		expected PruningOptions
+	}{
+		{"nothing", true, PruneNothing},
+		{"everything", true, PruneEverything},
+		{"keep-every-100-plus-recent-10", true, PruningOptions{KeepRecent: 10, KeepEvery: 100}},
+		{"keep-every-0-plus-recent-5", true, PruningOptions{KeepRecent: 5}},
+		{"keep-every-100", false, PruningOptions{}},
+		{"keep-every--1-plus-recent-10", false, PruningOptions{}},
+		{"syncable", false, PruningOptions{}},
+		{"", false, PruningOptions{}},
+	}
+
+	for i, tc := range cases {
+		opts, err := ParsePruningOptions(tc.input)
+		if !tc.valid {
+			assert.NotNil(t, err, "%d: %s", i, tc.input)
+			continue
+		}
+		require.Nil(t, err, "%d: %s", i, tc.input)
+		assert.Equal(t, tc.expected, opts, "%d: %s", i, tc.input)
+		assert.Equal(t, tc.input, opts.String(), "%d: %s", i, tc.input)
+	}
+}
+
+func TestPruningOptionsReleases(t *testing.T) {
+	released := func(opts PruningOptions, upTo int64) (versions []int64) {
+		for version := int64(1); version <= upTo; version++ {
+			if release, ok := opts.Releases(version); ok {
+				versions = append(versions, release)
+			}
+		}
+		return
+	}
+
+	assert.Nil(t, released(PruneNothing, 10))
+	assert.Equal(t, []int64{1, 2, 3, 4}, released(PruneEverything, 5))
+	assert.Equal(t, []int64{1, 2, 4, 5, 7}, released(PruningOptions{KeepRecent: 2, KeepEvery: 3}, 10))
+}
@@ -0,0 +1,10 @@
+package types
+
+import (
+	abci "github.com/tendermint/abci/types"
+)
+
+// Querier is the function a module registers to answer custom queries.
+// The path holds the remaining elements of the query path after the
+// module route, ie. for "/custom/stake/validator" it is []string{"validator"}.
+type Querier func(ctx Context, path []string, req abci.RequestQuery) (res []byte, err Error)
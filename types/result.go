@@ -26,6 +26,9 @@ type Result struct {
 	FeeAmount int64
 	FeeDenom  string
 
+	// Priority of the tx in the mempool, higher first. Set by CheckTx.
+	Priority int64
+
 	// Changes to the validator set.
 	ValidatorUpdates []abci.Validator
 
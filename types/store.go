@@ -49,7 +49,10 @@ type MultiStore interface { //nolint
 	// Convenience for fetching substores.
 	GetStore(StoreKey) Store
 	GetKVStore(StoreKey) KVStore
-	GetKVStoreWithGas(GasMeter, StoreKey) KVStore
+	GetKVStoreWithGas(GasMeter, GasConfig, StoreKey) KVStore
+
+	// The gas schedule of the store of key, KVGasConfig by default.
+	GetGasConfig(StoreKey) GasConfig
 }
 
 // From MultiStore.CacheMultiStore()....
@@ -65,6 +68,8 @@ type CommitMultiStore interface {
 
 	// Mount a store of type using the given db.
 	// If db == nil, the new store will use the CommitMultiStore db.
+	// A store of StoreTypeMulti is a nested CommitMultiStore, which
+	// GetCommitStore returns right away so its own stores can be mounted.
 	MountStoreWithDB(key StoreKey, typ StoreType, db dbm.DB)
 
 	// Panics on a nil key.
@@ -73,6 +78,18 @@ type CommitMultiStore interface {
 	// Panics on a nil key.
 	GetCommitKVStore(key StoreKey) CommitKVStore
 
+	// Set the pruning options applied to all stores on Commit.
+	SetPruning(opts PruningOptions)
+
+	// Register listeners of the writes to the KVStore of key.  They
+	// are notified of the writes to the store made through
+	// CacheMultiStoreWithListeners, and of every Commit.
+	AddListeners(key StoreKey, listeners []WriteListener)
+
+	// Set the gas schedule of the store of key, returned by
+	// GetGasConfig of the MultiStore and of its caches.
+	SetGasConfig(key StoreKey, config GasConfig)
+
 	// Load the latest persisted version.  Called once after all
 	// calls to Mount*Store() are complete.
 	LoadLatestVersion() error
@@ -82,6 +99,57 @@ type CommitMultiStore interface {
 	// the next commit after loading must be idempotent (return the
 	// same commit id).  Otherwise the behavior is undefined.
 	LoadVersion(ver int64) error
+
+	// Cache wrap the MultiStore as it was at a previously committed
+	// version.  Writes to the returned store are dropped: its Write is
+	// a no-op, so it serves as a read-only view of historical state.
+	CacheMultiStoreWithVersion(ver int64) (CacheMultiStore, error)
+
+	// Cache wrap the MultiStore like CacheMultiStore, notifying the
+	// registered listeners of every write to the returned store.  The
+	// writes to a cache of the returned store are notified when the
+	// cache is written, in the order of their keys.
+	CacheMultiStoreWithListeners() CacheMultiStore
+
+	// Write the state of the given committed version to a snapshot
+	// in dir, split into chunks of at most chunkSize bytes.  It only
+	// reads the data of the version, so it may run concurrently with
+	// later commits, if the version is held with HoldVersion.
+	Snapshot(dir string, ver int64, chunkSize int) (SnapshotManifest, error)
+
+	// Keep the given committed version from being pruned until the
+	// returned function is called.  The version is pruned by the
+	// first Commit after that if it was released meanwhile.
+	HoldVersion(ver int64) (unhold func())
+
+	// Restore the snapshot of the given version found in dir.  The
+	// store must be empty.  The snapshot is restored aside, and only
+	// written to the store, which is loaded at the restored version,
+	// if its commit hash matches appHash, obtained from a trusted
+	// source such as the header of the next block.
+	Restore(dir string, ver int64, appHash []byte) error
+
+	// Roll every store back to the given committed version, which
+	// becomes the latest: the newer versions are deleted.  Fails if
+	// the version was pruned.  The store is loaded at the version.
+	Rollback(ver int64) error
+}
+
+// WriteListener is notified of the writes to the stores of a
+// CommitMultiStore it is registered with, in the order they are made.
+type WriteListener interface {
+	// OnWrite is called for every write.  The value of a delete is nil.
+	OnWrite(storeKey StoreKey, key, value []byte, deleted bool)
+
+	// OnCommit is called once the writes notified before are committed.
+	OnCommit(commitID CommitID)
+}
+
+// SnapshotManifest describes a snapshot of a CommitMultiStore:
+// the CommitID it restores to, and the hashes of its chunks.
+type SnapshotManifest struct {
+	CommitID    CommitID `json:"commit_id"`
+	ChunkHashes [][]byte `json:"chunk_hashes"`
 }
 
 //---------subsp-------------------------------
@@ -113,11 +181,9 @@ type KVStore interface {
 	// CONTRACT: No writes may happen within a domain while an iterator exists over it.
 	ReverseIterator(start, end []byte) Iterator
 
-	// TODO Not yet implemented.
-	// CreateSubKVStore(key *storeKey) (KVStore, error)
-
-	// TODO Not yet implemented.
-	// GetSubKVStore(key *storeKey) KVStore
+	// Prefix returns a view of the keys starting with prefix, which
+	// are given and returned without the prefix.
+	Prefix(prefix []byte) KVStore
 }
 
 // Alias iterator to db's Iterator for convenience.
@@ -201,6 +267,7 @@ const (
 	StoreTypeMulti StoreType = iota
 	StoreTypeDB
 	StoreTypeIAVL
+	StoreTypeTransient
 )
 
 //----------------------------------------
@@ -7,8 +7,9 @@ import (
 // Transactions messages must fulfill the Msg
 type Msg interface {
 
-	// Return the message type.
-	// Must be alphanumeric or empty.
+	// Return the message type, used to route the message to its handler.
+	// Must be one or more alphabetic segments separated by "/",
+	// eg. "bank/send", where the first segment names the module.
 	Type() string
 
 	// Get the canonical byte representation of the Msg.
@@ -29,8 +30,8 @@ type Msg interface {
 // Transactions objects must fulfill the Tx
 type Tx interface {
 
-	// Gets the Msg.
-	GetMsg() Msg
+	// Gets the all the transaction's messages.
+	GetMsgs() []Msg
 }
 
 //__________________________________________________________
@@ -38,6 +39,9 @@ type Tx interface {
 // TxDecoder unmarshals transaction bytes
 type TxDecoder func(txBytes []byte) (Tx, Error)
 
+// TxEncoder marshals a transaction to bytes
+type TxEncoder func(tx Tx) ([]byte, error)
+
 //__________________________________________________________
 
 var _ Msg = (*TestMsg)(nil)
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+)
+
+// Version bytes of the standard transaction encodings.
+// Legacy amino binary transactions carry no version byte. They start with
+// the uvarint length of an amino prefixed struct, which is at least 4, so
+// version bytes below 0x04 can never be mistaken for a legacy transaction.
+const (
+	TxVersionAmino byte = 0x01
+	TxVersionJSON  byte = 0x02
+	TxVersionProto byte = 0x03
+)
+
+// TxCodecRegistry tracks the transaction encodings accepted by an app.
+// Encoded transactions are prefixed with the version byte of their
+// encoding, which selects the decoder.
+type TxCodecRegistry struct {
+	encoders map[byte]TxEncoder
+	decoders map[byte]TxDecoder
+	legacy   TxDecoder
+}
+
+// NewTxCodecRegistry creates an empty TxCodecRegistry
+func NewTxCodecRegistry() *TxCodecRegistry {
+	return &TxCodecRegistry{
+		encoders: make(map[byte]TxEncoder),
+		decoders: make(map[byte]TxDecoder),
+	}
+}
+
+// Register adds an encoding under a version byte, and panics if the version
+// is zero or already registered
+func (r *TxCodecRegistry) Register(version byte, enc TxEncoder, dec TxDecoder) *TxCodecRegistry {
+	if version == 0 {
+		panic("tx encoding version 0 is reserved")
+	}
+	if _, ok := r.decoders[version]; ok {
+		panic(fmt.Sprintf("tx encoding version %d has already been registered", version))
+	}
+	r.encoders[version] = enc
+	r.decoders[version] = dec
+	return r
+}
+
+// SetLegacyDecoder sets the decoder used for transactions whose first byte
+// is not a registered version, eg. amino binary from older clients
+func (r *TxCodecRegistry) SetLegacyDecoder(dec TxDecoder) *TxCodecRegistry {
+	r.legacy = dec
+	return r
+}
+
+// Encode encodes tx with the encoding registered under version,
+// prefixed with the version byte
+func (r *TxCodecRegistry) Encode(version byte, tx Tx) ([]byte, error) {
+	enc, ok := r.encoders[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown tx encoding version %d", version)
+	}
+	bz, err := enc(tx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{version}, bz...), nil
+}
+
+// TxDecoder returns a TxDecoder which selects the decoder by the leading
+// version byte, falling back to the legacy decoder if one is set
+func (r *TxCodecRegistry) TxDecoder() TxDecoder {
+	return func(txBytes []byte) (Tx, Error) {
+		if len(txBytes) == 0 {
+			return nil, ErrTxDecode("txBytes are empty")
+		}
+		if dec, ok := r.decoders[txBytes[0]]; ok {
+			return dec(txBytes[1:])
+		}
+		if r.legacy != nil {
+			return r.legacy(txBytes)
+		}
+		return nil, ErrTxDecode(fmt.Sprintf("unknown tx encoding version %d", txBytes[0]))
+	}
+}
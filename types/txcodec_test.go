@@ -0,0 +1,76 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCodecTx struct {
+	encoding string
+}
+
+func (tx testCodecTx) GetMsgs() []Msg { return nil }
+
+func testCodec(name string) (TxEncoder, TxDecoder) {
+	enc := func(tx Tx) ([]byte, error) {
+		ctx, ok := tx.(testCodecTx)
+		if !ok {
+			return nil, errors.New("unexpected tx type")
+		}
+		return []byte(name + ":" + ctx.encoding), nil
+	}
+	dec := func(txBytes []byte) (Tx, Error) {
+		return testCodecTx{encoding: name + "/" + string(txBytes)}, nil
+	}
+	return enc, dec
+}
+
+func TestTxCodecRegistry(t *testing.T) {
+	encA, decA := testCodec("a")
+	encB, decB := testCodec("b")
+	_, decLegacy := testCodec("legacy")
+
+	r := NewTxCodecRegistry().
+		Register(TxVersionAmino, encA, decA).
+		Register(TxVersionJSON, encB, decB)
+
+	// encoding prefixes the version byte
+	bz, err := r.Encode(TxVersionJSON, testCodecTx{"x"})
+	require.Nil(t, err)
+	assert.Equal(t, append([]byte{TxVersionJSON}, []byte("b:x")...), bz)
+
+	_, err = r.Encode(TxVersionProto, testCodecTx{"x"})
+	assert.NotNil(t, err)
+
+	// decoding selects by the version byte
+	decode := r.TxDecoder()
+	tx, sdkErr := decode(bz)
+	require.Nil(t, sdkErr)
+	assert.Equal(t, testCodecTx{"b/b:x"}, tx)
+
+	tx, sdkErr = decode(append([]byte{TxVersionAmino}, 'y'))
+	require.Nil(t, sdkErr)
+	assert.Equal(t, testCodecTx{"a/y"}, tx)
+
+	// unknown versions fail without a legacy decoder
+	_, sdkErr = decode([]byte{0x42, 'z'})
+	require.NotNil(t, sdkErr)
+	assert.Equal(t, CodeTxDecode, sdkErr.Code())
+
+	_, sdkErr = decode(nil)
+	require.NotNil(t, sdkErr)
+	assert.Equal(t, CodeTxDecode, sdkErr.Code())
+
+	// and are passed whole to the legacy decoder otherwise
+	r.SetLegacyDecoder(decLegacy)
+	tx, sdkErr = decode([]byte{0x42, 'z'})
+	require.Nil(t, sdkErr)
+	assert.Equal(t, testCodecTx{"legacy/\x42z"}, tx)
+
+	// versions cannot be zero or registered twice
+	assert.Panics(t, func() { r.Register(0, encA, decA) })
+	assert.Panics(t, func() { r.Register(TxVersionAmino, encA, decA) })
+}
@@ -117,5 +117,8 @@ func (acc *BaseAccount) SetSequence(seq int64) error {
 func RegisterBaseAccount(cdc *wire.Codec) {
 	cdc.RegisterInterface((*Account)(nil), nil)
 	cdc.RegisterConcrete(&BaseAccount{}, "cosmos-sdk/BaseAccount", nil)
+	cdc.RegisterConcrete(&ContinuousVestingAccount{}, "cosmos-sdk/ContinuousVestingAccount", nil)
+	cdc.RegisterConcrete(&DelayedVestingAccount{}, "cosmos-sdk/DelayedVestingAccount", nil)
 	wire.RegisterCrypto(cdc)
+	RegisterMultisig(cdc)
 }
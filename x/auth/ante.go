@@ -15,7 +15,8 @@ const (
 
 // NewAnteHandler returns an AnteHandler that checks
 // and increments sequence numbers, checks signatures & account numbers,
-// and deducts fees from the first signer.
+// and deducts fees from the first signer, or from the payer of the fee
+// if it granted the first signer a fee allowance.
 func NewAnteHandler(am AccountMapper, fck FeeCollectionKeeper) sdk.AnteHandler {
 
 	return func(
@@ -36,16 +37,42 @@ func NewAnteHandler(am AccountMapper, fck FeeCollectionKeeper) sdk.AnteHandler {
 				true
 		}
 
-		msg := tx.GetMsg()
+		// Assert that multisig keys and signatures are bounded, before
+		// their addresses are derived.
+		for _, sig := range sigs {
+			if res := checkMultisigBounds(sig); !res.IsOK() {
+				return ctx, res, true
+			}
+		}
+
+		msgs := tx.GetMsgs()
 
 		// Assert that number of signatures is correct.
-		var signerAddrs = msg.GetSigners()
+		var signerAddrs = stdTx.GetSigners()
 		if len(sigs) != len(signerAddrs) {
 			return ctx,
 				sdk.ErrUnauthorized("wrong number of signers").Result(),
 				true
 		}
 
+		// Assert that the fee pays for the gas at the minimum gas prices:
+		// the node's own prices in CheckTx, and the chain's in any case.
+		fee := stdTx.Fee
+		if ctx.IsCheckTx() {
+			minPrices := ctx.MinimumGasPrices()
+			if !minPrices.IsAcceptedFee(fee.Amount, fee.Gas) {
+				return ctx,
+					sdk.ErrInsufficientFee(fmt.Sprintf("fee %v for %d gas is below the node's minimum gas prices %v", fee.Amount, fee.Gas, minPrices)).Result(),
+					true
+			}
+		}
+		minPrices := fck.GetMinimumGasPrices(ctx)
+		if !minPrices.IsAcceptedFee(fee.Amount, fee.Gas) {
+			return ctx,
+				sdk.ErrInsufficientFee(fmt.Sprintf("fee %v for %d gas is below the chain's minimum gas prices %v", fee.Amount, fee.Gas, minPrices)).Result(),
+				true
+		}
+
 		// Get the sign bytes (requires all account & sequence numbers and the fee)
 		sequences := make([]int64, len(signerAddrs))
 		for i := 0; i < len(signerAddrs); i++ {
@@ -55,41 +82,69 @@ func NewAnteHandler(am AccountMapper, fck FeeCollectionKeeper) sdk.AnteHandler {
 		for i := 0; i < len(signerAddrs); i++ {
 			accNums[i] = sigs[i].AccountNumber
 		}
-		fee := stdTx.Fee
 		chainID := ctx.ChainID()
 		// XXX: major hack; need to get ChainID
 		// into the app right away (#565)
 		if chainID == "" {
 			chainID = viper.GetString("chain-id")
 		}
-		signBytes := StdSignBytes(ctx.ChainID(), accNums, sequences, fee, msg)
+		signBytes := StdSignBytes(ctx.ChainID(), accNums, sequences, fee, msgs)
+
+		// The fees are paid by the first signer, or by the payer of the fee
+		// out of a fee allowance granted to the first signer.
+		feePayer := FeePayer(stdTx)
+		sponsored := !bytes.Equal(feePayer, signerAddrs[0])
+
+		// In CheckTx, a tx with the sequence of a pending tx of the first
+		// signer replaces it if it pays a higher gas price.
+		pending, replacing := pendingTx{}, false
+		if ctx.IsCheckTx() {
+			pending, replacing = fck.getPendingTx(ctx, signerAddrs[0], sigs[0].Sequence)
+			if replacing {
+				res := checkReplacement(pending, fee, sponsored)
+				if !res.IsOK() {
+					return ctx, res, true
+				}
+			}
+		}
 
 		// Check sig and nonce and collect signer accounts.
 		var signerAccs = make([]Account, len(signerAddrs))
 		for i := 0; i < len(sigs); i++ {
 			signerAddr, sig := signerAddrs[i], sigs[i]
 
-			// check signature, return account with incremented nonce
+			// check signature, return account with incremented nonce;
+			// only the sequence of the first signer is reused by a replacement
 			signerAcc, res := processSig(
 				ctx, am,
-				signerAddr, sig, signBytes,
+				signerAddr, sig, signBytes, replacing && i == 0,
 			)
 			if !res.IsOK() {
 				return ctx, res, true
 			}
 
-			// first sig pays the fees
-			if i == 0 {
-				// TODO: min fee
+			// first sig pays the fees, unless they are sponsored
+			if i == 0 && !sponsored {
+				// refund the fee of the replaced tx
+				if replacing && !pending.Fee.IsZero() {
+					signerAcc.SetCoins(signerAcc.GetCoins().Plus(pending.Fee))
+					fck.setCollectedFees(ctx, fck.GetCollectedFees(ctx).Minus(pending.Fee))
+				}
 				if !fee.Amount.IsZero() {
 					ctx.GasMeter().ConsumeGas(deductFeesCost, "deductFees")
-					signerAcc, res = deductFees(signerAcc, fee)
+					signerAcc, res = deductFees(signerAcc, fee, ctx.BlockHeader().Time)
 					if !res.IsOK() {
 						return ctx, res, true
 					}
 					fck.addCollectedFees(ctx, fee.Amount)
 				}
 			}
+			if i == 0 && sponsored {
+				res = payFeesFromAllowance(ctx, am, fck, feePayer, signerAddr, fee)
+				if !res.IsOK() {
+					return ctx, res, true
+				}
+			}
 
 			// Save the account.
 			am.SetAccount(ctx, signerAcc)
@@ -99,20 +154,38 @@ func NewAnteHandler(am AccountMapper, fck FeeCollectionKeeper) sdk.AnteHandler {
 		// cache the signer accounts in the context
 		ctx = WithSigners(ctx, signerAccs)
 
+		// record the tx, so that it can be replaced
+		denom, gasPrice := effectiveGasPrice(fee)
+		if ctx.IsCheckTx() {
+			fck.setPendingTx(ctx, signerAddrs[0], sigs[0].Sequence, pendingTx{denom, gasPrice, fee.Amount, sponsored})
+		}
+
 		// set the gas meter
 		ctx = ctx.WithGasMeter(sdk.NewGasMeter(stdTx.Fee.Gas))
 
 		// TODO: tx tags (?)
 
-		return ctx, sdk.Result{}, false // continue...
+		feeCoin := sdk.Coin{}
+		if len(fee.Amount) > 0 {
+			feeCoin = fee.Amount[0]
+		}
+		return ctx, sdk.Result{
+			GasWanted: stdTx.Fee.Gas,
+			FeeAmount: feeCoin.Amount,
+			FeeDenom:  feeCoin.Denom,
+			Priority:  txPriority(fee),
+			Tags:      sdk.NewTags("fee_payer", []byte(feePayer.String())),
+		}, false // continue...
 	}
 }
 
 // verify the signature and increment the sequence.
 // if the account doesn't have a pubkey, set it.
+// if the tx replaces a pending tx, the sequence was already incremented
+// by the pending tx, so it is checked but not incremented again.
 func processSig(
 	ctx sdk.Context, am AccountMapper,
-	addr sdk.Address, sig StdSignature, signBytes []byte) (
+	addr sdk.Address, sig StdSignature, signBytes []byte, replacing bool) (
 	acc Account, res sdk.Result) {
 
 	// Get the account.
@@ -130,6 +203,9 @@ func processSig(
 
 	// Check and increment sequence number.
 	seq := acc.GetSequence()
+	if replacing {
+		seq--
+	}
 	if seq != sig.Sequence {
 		return nil, sdk.ErrInvalidSequence(
 			fmt.Sprintf("Invalid sequence. Got %d, expected %d", sig.Sequence, seq)).Result()
@@ -154,8 +230,12 @@ func processSig(
 		}
 	}
 
-	// Check sig.
-	ctx.GasMeter().ConsumeGas(verifyCost, "ante verify")
+	// Check sig, paying for every member signature of a multisig.
+	verifyGas := sdk.Gas(verifyCost)
+	if multiSig, ok := sig.Signature.(MultiSignature); ok {
+		verifyGas *= sdk.Gas(len(multiSig.Sigs))
+	}
+	ctx.GasMeter().ConsumeGas(verifyGas, "ante verify")
 	if !pubKey.VerifyBytes(signBytes, sig.Signature) {
 		return nil, sdk.ErrUnauthorized("signature verification failed").Result()
 	}
@@ -163,19 +243,61 @@ func processSig(
 	return
 }
 
+// checkMultisigBounds checks that the multisig key and signature of sig, if
+// any, have at most MaxMultisigKeys members and signatures.
+func checkMultisigBounds(sig StdSignature) sdk.Result {
+	if pk, ok := sig.PubKey.(MultisigThresholdPubKey); ok {
+		if err := pk.ValidateBasic(); err != nil {
+			return sdk.ErrInvalidPubKey(err.Error()).Result()
+		}
+	}
+	if multiSig, ok := sig.Signature.(MultiSignature); ok && len(multiSig.Sigs) > MaxMultisigKeys {
+		return sdk.ErrUnauthorized(fmt.Sprintf(
+			"multisignature has %d signatures, at most %d are allowed", len(multiSig.Sigs), MaxMultisigKeys)).Result()
+	}
+	return sdk.Result{}
+}
+
+// Deduct the fee from the account of the payer, spending the fee allowance
+// the payer granted to the grantee, the first signer.
+func payFeesFromAllowance(ctx sdk.Context, am AccountMapper, fck FeeCollectionKeeper,
+	payer, grantee sdk.Address, fee StdFee) sdk.Result {
+
+	res := fck.useFeeAllowance(ctx, payer, grantee, fee.Amount)
+	if !res.IsOK() {
+		return res
+	}
+	if fee.Amount.IsZero() {
+		return sdk.Result{}
+	}
+	payerAcc := am.GetAccount(ctx, payer)
+	if payerAcc == nil {
+		return sdk.ErrUnknownAddress(payer.String()).Result()
+	}
+	ctx.GasMeter().ConsumeGas(deductFeesCost, "deductFees")
+	payerAcc, res = deductFees(payerAcc, fee, ctx.BlockHeader().Time)
+	if !res.IsOK() {
+		return res
+	}
+	am.SetAccount(ctx, payerAcc)
+	fck.addCollectedFees(ctx, fee.Amount)
+	return sdk.Result{}
+}
+
 // Deduct the fee from the account.
 // We could use the CoinKeeper (in addition to the AccountMapper,
 // because the CoinKeeper doesn't give us accounts), but it seems easier to do this.
-func deductFees(acc Account, fee StdFee) (Account, sdk.Result) {
+func deductFees(acc Account, fee StdFee, blockTime int64) (Account, sdk.Result) {
 	coins := acc.GetCoins()
 	feeAmount := fee.Amount
 
-	newCoins := coins.Minus(feeAmount)
-	if !newCoins.IsNotNegative() {
-		errMsg := fmt.Sprintf("%s < %s", coins, feeAmount)
+	// Locked coins of vesting accounts can't pay fees.
+	spendable := SpendableCoins(acc, blockTime)
+	if !spendable.Minus(feeAmount).IsNotNegative() {
+		errMsg := fmt.Sprintf("%s < %s", spendable, feeAmount)
 		return nil, sdk.ErrInsufficientFunds(errMsg).Result()
 	}
-	acc.SetCoins(newCoins)
+	acc.SetCoins(coins.Minus(feeAmount))
 	return acc, sdk.Result{}
 }
 
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,17 +53,17 @@ func checkInvalidTx(t *testing.T, anteHandler sdk.AnteHandler, ctx sdk.Context,
 	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, code), result.Code)
 }
 
-func newTestTx(ctx sdk.Context, msg sdk.Msg, privs []crypto.PrivKey, accNums []int64, seqs []int64, fee StdFee) sdk.Tx {
-	signBytes := StdSignBytes(ctx.ChainID(), accNums, seqs, fee, msg)
-	return newTestTxWithSignBytes(msg, privs, accNums, seqs, fee, signBytes)
+func newTestTx(ctx sdk.Context, msgs []sdk.Msg, privs []crypto.PrivKey, accNums []int64, seqs []int64, fee StdFee) sdk.Tx {
+	signBytes := StdSignBytes(ctx.ChainID(), accNums, seqs, fee, msgs)
+	return newTestTxWithSignBytes(msgs, privs, accNums, seqs, fee, signBytes)
 }
 
-func newTestTxWithSignBytes(msg sdk.Msg, privs []crypto.PrivKey, accNums []int64, seqs []int64, fee StdFee, signBytes []byte) sdk.Tx {
+func newTestTxWithSignBytes(msgs []sdk.Msg, privs []crypto.PrivKey, accNums []int64, seqs []int64, fee StdFee, signBytes []byte) sdk.Tx {
 	sigs := make([]StdSignature, len(privs))
 	for i, priv := range privs {
 		sigs[i] = StdSignature{PubKey: priv.PubKey(), Signature: priv.Sign(signBytes), AccountNumber: accNums[i], Sequence: seqs[i]}
 	}
-	tx := NewStdTx(msg, fee, sigs)
+	tx := NewStdTx(msgs, fee, sigs)
 	return tx
 }
 
@@ -88,17 +89,17 @@ func TestAnteHandlerSigErrors(t *testing.T) {
 
 	// test no signatures
 	privs, accNums, seqs := []crypto.PrivKey{}, []int64{}, []int64{}
-	tx = newTestTx(ctx, msg, privs, accNums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accNums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
 
 	// test num sigs dont match GetSigners
 	privs, accNums, seqs = []crypto.PrivKey{priv1}, []int64{0}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accNums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accNums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
 
 	// test an unrecognized account
 	privs, accNums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{0, 0}
-	tx = newTestTx(ctx, msg, privs, accNums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accNums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnknownAddress)
 
 	// save the first account, but second is still unrecognized
@@ -138,28 +139,28 @@ func TestAnteHandlerAccountNumbers(t *testing.T) {
 
 	// test good tx from one signer
 	privs, accnums, seqs := []crypto.PrivKey{priv1}, []int64{0}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// new tx from wrong account number
 	seqs = []int64{1}
-	tx = newTestTx(ctx, msg, privs, []int64{1}, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, []int64{1}, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidSequence)
 
 	// from correct account number
 	seqs = []int64{1}
-	tx = newTestTx(ctx, msg, privs, []int64{0}, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, []int64{0}, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// new tx with another signer and incorrect account numbers
 	msg = newTestMsg(addr1, addr2)
 	privs, accnums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{1, 0}, []int64{2, 0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidSequence)
 
 	// correct account numbers
 	privs, accnums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{2, 0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 }
 
@@ -193,7 +194,7 @@ func TestAnteHandlerSequences(t *testing.T) {
 
 	// test good tx from one signer
 	privs, accnums, seqs := []crypto.PrivKey{priv1}, []int64{0}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// test sending it again fails (replay protection)
@@ -201,13 +202,13 @@ func TestAnteHandlerSequences(t *testing.T) {
 
 	// fix sequence, should pass
 	seqs = []int64{1}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// new tx with another signer and correct sequences
 	msg = newTestMsg(addr1, addr2)
 	privs, accnums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{2, 0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// replay fails
@@ -216,17 +217,74 @@ func TestAnteHandlerSequences(t *testing.T) {
 	// tx from just second signer with incorrect sequence fails
 	msg = newTestMsg(addr2)
 	privs, accnums, seqs = []crypto.PrivKey{priv2}, []int64{1}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidSequence)
 
 	// fix the sequence and it passes
-	tx = newTestTx(ctx, msg, []crypto.PrivKey{priv2}, []int64{1}, []int64{1}, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, []crypto.PrivKey{priv2}, []int64{1}, []int64{1}, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	// another tx from both of them that passes
 	msg = newTestMsg(addr1, addr2)
 	privs, accnums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{3, 2}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
+	checkValidTx(t, anteHandler, ctx, tx)
+}
+
+// Test logic around multiple msgs with overlapping signers.
+func TestAnteHandlerMultiSigner(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, false, nil, log.NewNopLogger())
+
+	// keys and addresses
+	priv1, addr1 := privAndAddr()
+	priv2, addr2 := privAndAddr()
+	priv3, addr3 := privAndAddr()
+
+	// set the accounts
+	acc1 := mapper.NewAccountWithAddress(ctx, addr1)
+	acc1.SetCoins(newCoins())
+	mapper.SetAccount(ctx, acc1)
+	acc2 := mapper.NewAccountWithAddress(ctx, addr2)
+	acc2.SetCoins(newCoins())
+	mapper.SetAccount(ctx, acc2)
+	acc3 := mapper.NewAccountWithAddress(ctx, addr3)
+	acc3.SetCoins(newCoins())
+	mapper.SetAccount(ctx, acc3)
+
+	// set up msgs and fee
+	var tx sdk.Tx
+	msg1 := newTestMsg(addr1, addr2)
+	msg2 := newTestMsg(addr3, addr1)
+	msg3 := newTestMsg(addr2, addr3)
+	msgs := []sdk.Msg{msg1, msg2, msg3}
+	fee := newStdFee()
+
+	// signers in order
+	privs, accnums, seqs := []crypto.PrivKey{priv1, priv2, priv3}, []int64{0, 1, 2}, []int64{0, 0, 0}
+	tx = newTestTx(ctx, msgs, privs, accnums, seqs, fee)
+	checkValidTx(t, anteHandler, ctx, tx)
+
+	// signers out of order fail
+	privs, accnums, seqs = []crypto.PrivKey{priv2, priv1, priv3}, []int64{0, 1, 2}, []int64{1, 1, 1}
+	tx = newTestTx(ctx, msgs, privs, accnums, seqs, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+
+	// missing signer fails
+	privs, accnums, seqs = []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{1, 1}
+	tx = newTestTx(ctx, msgs, privs, accnums, seqs, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+
+	// change sequence numbers
+	tx = newTestTx(ctx, []sdk.Msg{msg1}, []crypto.PrivKey{priv1, priv2}, []int64{0, 1}, []int64{1, 1}, fee)
+	checkValidTx(t, anteHandler, ctx, tx)
+	tx = newTestTx(ctx, []sdk.Msg{msg2}, []crypto.PrivKey{priv3, priv1}, []int64{2, 0}, []int64{1, 2}, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 }
 
@@ -257,7 +315,7 @@ func TestAnteHandlerFees(t *testing.T) {
 	)
 
 	// signer does not have enough funds to pay the fee
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInsufficientFunds)
 
 	acc1.SetCoins(sdk.Coins{{"atom", 149}})
@@ -273,6 +331,170 @@ func TestAnteHandlerFees(t *testing.T) {
 	assert.True(t, feeCollector.GetCollectedFees(ctx).IsEqual(sdk.Coins{{"atom", 150}}))
 }
 
+// Test that fees must pay for the gas at the minimum gas prices.
+func TestAnteHandlerMinimumGasPrices(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, false, nil, log.NewNopLogger())
+
+	// keys and addresses
+	priv1, addr1 := privAndAddr()
+
+	// set the accounts
+	acc1 := mapper.NewAccountWithAddress(ctx, addr1)
+	acc1.SetCoins(sdk.Coins{{"atom", 1000}, {"photon", 1000}})
+	mapper.SetAccount(ctx, acc1)
+
+	// msg and signatures, paying 1.5atom per gas
+	var tx sdk.Tx
+	msg := newTestMsg(addr1)
+	privs, accnums := []crypto.PrivKey{priv1}, []int64{0}
+	fee := NewStdFee(100,
+		sdk.Coin{"atom", 150},
+	)
+
+	// the node's minimum gas prices are only enforced in CheckTx
+	nodePrices, err := sdk.ParseGasPrices("2atom")
+	require.Nil(t, err)
+	ctx = ctx.WithMinimumGasPrices(nodePrices)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, fee)
+	checkValidTx(t, anteHandler, ctx, tx)
+
+	checkCtx := ctx.WithIsCheckTx(true)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{1}, fee)
+	checkInvalidTx(t, anteHandler, checkCtx, tx, sdk.CodeInsufficientFee)
+
+	nodePrices, err = sdk.ParseGasPrices("1.5atom,1photon")
+	require.Nil(t, err)
+	checkCtx = checkCtx.WithMinimumGasPrices(nodePrices)
+	checkValidTx(t, anteHandler, checkCtx, tx)
+
+	// the chain's minimum gas prices are enforced in any case
+	chainPrices, err := sdk.ParseGasPrices("1photon")
+	require.Nil(t, err)
+	feeCollector.SetMinimumGasPrices(ctx, chainPrices)
+	require.Equal(t, chainPrices.String(), feeCollector.GetMinimumGasPrices(ctx).String())
+
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{2}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInsufficientFee)
+
+	fee = NewStdFee(100,
+		sdk.Coin{"photon", 100},
+	)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{2}, fee)
+	checkValidTx(t, anteHandler, ctx, tx)
+}
+
+func TestTxPriorityOverflow(t *testing.T) {
+	// gas prices whose scaled priority overflows an int64 get the highest one
+	assert.Equal(t, int64(math.MaxInt64), txPriority(NewStdFee(1, sdk.Coin{"atom", math.MaxInt64})))
+	assert.Equal(t, int64(math.MaxInt64), txPriority(NewStdFee(10, sdk.Coin{"atom", math.MaxInt64 / 10})))
+	assert.Equal(t, int64(10*priorityPrecision), txPriority(NewStdFee(10, sdk.Coin{"atom", 100})))
+}
+
+// Test the priority of txs and their replacement in CheckTx.
+func TestAnteHandlerReplaceByFee(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, true, nil, log.NewNopLogger())
+
+	// keys and addresses
+	priv1, addr1 := privAndAddr()
+
+	// set the accounts
+	acc1 := mapper.NewAccountWithAddress(ctx, addr1)
+	acc1.SetCoins(sdk.Coins{{"atom", 1000}})
+	mapper.SetAccount(ctx, acc1)
+
+	// msg and signatures
+	var tx sdk.Tx
+	msg := newTestMsg(addr1)
+	privs, accnums := []crypto.PrivKey{priv1}, []int64{0}
+
+	// the priority is the gas price, scaled
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, NewStdFee(100, sdk.Coin{"atom", 100}))
+	_, res, abort := anteHandler(ctx, tx)
+	require.False(t, abort, res.Log)
+	assert.Equal(t, int64(priorityPrecision), res.Priority)
+	assert.Equal(t, sdk.Tags{sdk.MakeTag("fee_payer", []byte(addr1.String()))}, res.Tags)
+
+	// a replacement must pay a higher gas price
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, NewStdFee(200, sdk.Coin{"atom", 200}))
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInsufficientFee)
+
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, NewStdFee(100, sdk.Coin{"atom", 200}))
+	_, res, abort = anteHandler(ctx, tx)
+	require.False(t, abort, res.Log)
+	assert.Equal(t, int64(2*priorityPrecision), res.Priority)
+
+	// the fee of the replaced tx is refunded, and the sequence is unchanged
+	acc1 = mapper.GetAccount(ctx, addr1)
+	assert.Equal(t, sdk.Coins{{"atom", 800}}, acc1.GetCoins())
+	assert.Equal(t, int64(1), acc1.GetSequence())
+	assert.True(t, feeCollector.GetCollectedFees(ctx).IsEqual(sdk.Coins{{"atom", 200}}))
+
+	// txs are not replaced in DeliverTx
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, NewStdFee(100, sdk.Coin{"atom", 300}))
+	checkInvalidTx(t, anteHandler, ctx.WithIsCheckTx(false), tx, sdk.CodeInvalidSequence)
+
+	// only the latest pending tx can be replaced
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{1}, NewStdFee(100, sdk.Coin{"atom", 100}))
+	checkValidTx(t, anteHandler, ctx, tx)
+
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0}, NewStdFee(100, sdk.Coin{"atom", 300}))
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidSequence)
+}
+
+func TestAnteHandlerReplaceByFeeMultiSigner(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, true, nil, log.NewNopLogger())
+
+	// keys and addresses
+	priv1, addr1 := privAndAddr()
+	priv2, addr2 := privAndAddr()
+
+	// set the accounts
+	acc1 := mapper.NewAccountWithAddress(ctx, addr1)
+	acc1.SetCoins(sdk.Coins{{"atom", 1000}})
+	mapper.SetAccount(ctx, acc1)
+	acc2 := mapper.NewAccountWithAddress(ctx, addr2)
+	mapper.SetAccount(ctx, acc2)
+
+	// msg and signatures
+	var tx sdk.Tx
+	msg := newTestMsg(addr1, addr2)
+	privs, accnums := []crypto.PrivKey{priv1, priv2}, []int64{0, 1}
+
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0, 0}, NewStdFee(100, sdk.Coin{"atom", 100}))
+	checkValidTx(t, anteHandler, ctx, tx)
+
+	// the co-signer can't reuse its sequence in a replacement
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0, 0}, NewStdFee(100, sdk.Coin{"atom", 200}))
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidSequence)
+
+	// it signs the replacement with its next sequence
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, []int64{0, 1}, NewStdFee(100, sdk.Coin{"atom", 200}))
+	checkValidTx(t, anteHandler, ctx, tx)
+	assert.Equal(t, int64(1), mapper.GetAccount(ctx, addr1).GetSequence())
+	assert.Equal(t, int64(2), mapper.GetAccount(ctx, addr2).GetSequence())
+}
+
 func TestAnteHandlerBadSignBytes(t *testing.T) {
 	// setup
 	ms, capKey, capKey2 := setupMultiStore()
@@ -305,7 +527,7 @@ func TestAnteHandlerBadSignBytes(t *testing.T) {
 
 	// test good tx and signBytes
 	privs, accnums, seqs := []crypto.PrivKey{priv1}, []int64{0}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	chainID := ctx.ChainID()
@@ -332,21 +554,21 @@ func TestAnteHandlerBadSignBytes(t *testing.T) {
 	privs, seqs = []crypto.PrivKey{priv1}, []int64{1}
 	for _, cs := range cases {
 		tx := newTestTxWithSignBytes(
-			msg, privs, accnums, seqs, fee,
-			StdSignBytes(cs.chainID, cs.accnums, cs.seqs, cs.fee, cs.msg),
+			[]sdk.Msg{msg}, privs, accnums, seqs, fee,
+			StdSignBytes(cs.chainID, cs.accnums, cs.seqs, cs.fee, []sdk.Msg{cs.msg}),
 		)
 		checkInvalidTx(t, anteHandler, ctx, tx, cs.code)
 	}
 
 	// test wrong signer if public key exist
 	privs, accnums, seqs = []crypto.PrivKey{priv2}, []int64{0}, []int64{1}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
 
 	// test wrong signer if public doesn't exist
 	msg = newTestMsg(addr2)
 	privs, accnums, seqs = []crypto.PrivKey{priv1}, []int64{1}, []int64{0}
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidPubKey)
 
 }
@@ -379,7 +601,7 @@ func TestAnteHandlerSetPubKey(t *testing.T) {
 	msg := newTestMsg(addr1)
 	privs, accnums, seqs := []crypto.PrivKey{priv1}, []int64{0}, []int64{0}
 	fee := newStdFee()
-	tx = newTestTx(ctx, msg, privs, accnums, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, accnums, seqs, fee)
 	checkValidTx(t, anteHandler, ctx, tx)
 
 	acc1 = mapper.GetAccount(ctx, addr1)
@@ -387,7 +609,7 @@ func TestAnteHandlerSetPubKey(t *testing.T) {
 
 	// test public key not found
 	msg = newTestMsg(addr2)
-	tx = newTestTx(ctx, msg, privs, []int64{1}, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, []int64{1}, seqs, fee)
 	sigs := tx.(StdTx).GetSignatures()
 	sigs[0].PubKey = nil
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidPubKey)
@@ -396,7 +618,7 @@ func TestAnteHandlerSetPubKey(t *testing.T) {
 	assert.Nil(t, acc2.GetPubKey())
 
 	// test invalid signature and public key
-	tx = newTestTx(ctx, msg, privs, []int64{1}, seqs, fee)
+	tx = newTestTx(ctx, []sdk.Msg{msg}, privs, []int64{1}, seqs, fee)
 	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInvalidPubKey)
 
 	acc2 = mapper.GetAccount(ctx, addr2)
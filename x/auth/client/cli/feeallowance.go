@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+const (
+	flagSpendLimit = "spend-limit"
+	flagExpiration = "expiration"
+)
+
+// GrantFeeAllowanceCmd grants a fee allowance from the key of --name
+func GrantFeeAllowanceCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant-fee-allowance <grantee>",
+		Short: "Pay the fees of the txs of the grantee",
+		Long: `Grant the grantee an allowance to pay the fees of its txs, which name the
+key of --name with --fee-payer, up to --spend-limit in total and until the
+block time --expiration. The allowance replaces any previous one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grantee, err := sdk.GetAccAddressBech32(args[0])
+			if err != nil {
+				return err
+			}
+			var limit sdk.Coins
+			if spendLimit := viper.GetString(flagSpendLimit); spendLimit != "" {
+				limit, err = sdk.ParseCoins(spendLimit)
+				if err != nil {
+					return err
+				}
+			}
+			allowance := auth.FeeAllowance{SpendLimit: limit, Expiration: viper.GetInt64(flagExpiration)}
+
+			return sendFeeAllowanceMsg(cdc, func(granter sdk.Address) sdk.Msg {
+				return auth.NewMsgGrantFeeAllowance(granter, grantee, allowance)
+			})
+		},
+	}
+	cmd.Flags().String(flagSpendLimit, "", "Total fees the allowance pays, unlimited if empty")
+	cmd.Flags().Int64(flagExpiration, 0, "Block time (unix seconds) at which the allowance expires, never if zero")
+	return cmd
+}
+
+// RevokeFeeAllowanceCmd revokes a fee allowance of the key of --name
+func RevokeFeeAllowanceCmd(cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke-fee-allowance <grantee>",
+		Short: "Revoke the fee allowance of the grantee",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grantee, err := sdk.GetAccAddressBech32(args[0])
+			if err != nil {
+				return err
+			}
+			return sendFeeAllowanceMsg(cdc, func(granter sdk.Address) sdk.Msg {
+				return auth.NewMsgRevokeFeeAllowance(granter, grantee)
+			})
+		},
+	}
+}
+
+// build the msg of the granter, the key of --name, and sign and broadcast it
+func sendFeeAllowanceMsg(cdc *wire.Codec, buildMsg func(granter sdk.Address) sdk.Msg) error {
+	ctx := context.NewCoreContextFromViper().WithDecoder(GetAccountDecoder(cdc))
+	granter, err := ctx.GetFromAddress()
+	if err != nil {
+		return err
+	}
+	msg := buildMsg(granter)
+	if ctx.GenerateOnly {
+		return ctx.PrintUnsignedTx([]sdk.Msg{msg}, cdc)
+	}
+
+	res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, []sdk.Msg{msg}, cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
+
+// GetFeeAllowanceCmd queries the fee allowance of a granter for a grantee
+func GetFeeAllowanceCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "fee-allowance <granter> <grantee>",
+		Short: "Query the fee allowance of a granter for a grantee",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			granter, err := sdk.GetAccAddressBech32(args[0])
+			if err != nil {
+				return err
+			}
+			grantee, err := sdk.GetAccAddressBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			ctx := context.NewCoreContextFromViper()
+			res, err := ctx.Query(auth.FeeAllowanceKey(granter, grantee), storeName)
+			if err != nil {
+				return err
+			}
+			if res == nil {
+				return fmt.Errorf("No fee allowance of %s for %s", args[0], args[1])
+			}
+
+			var allowance auth.FeeAllowance
+			if err = cdc.UnmarshalBinary(res, &allowance); err != nil {
+				return err
+			}
+			output, err := wire.MarshalJSONIndent(cdc, allowance)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+const (
+	flagMultisig  = "multisig"
+	flagBroadcast = "broadcast"
+)
+
+// SignPartialCmd signs a tx of a multisig key with the key of one member
+func SignPartialCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-partial <file>",
+		Short: "Sign a tx of a multisig key as one of its members",
+		Long: `Sign the tx in the file, an unsigned StdSignMsg in JSON, with the key of
+--name, a member of the multisig key of --multisig which signs the tx.
+The partial signature is printed as JSON, for the multisign command to
+combine it with those of the other members.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			signMsg, err := readStdSignMsg(cdc, args[0])
+			if err != nil {
+				return err
+			}
+			multisig, err := getMultisigKey(viper.GetString(flagMultisig))
+			if err != nil {
+				return err
+			}
+			accnum, sequence, err := multisigSignerNumbers(signMsg, multisig)
+			if err != nil {
+				return err
+			}
+
+			name := viper.GetString(client.FlagName)
+			keybase, err := keys.GetKeyBase()
+			if err != nil {
+				return err
+			}
+			info, err := keybase.Get(name)
+			if err != nil {
+				return errors.Errorf("No key for: %s", name)
+			}
+			if multisig.MemberIndex(info.PubKey) < 0 {
+				return errors.Errorf("%s is not a member of the multisig key", name)
+			}
+
+			passphrase, err := context.NewCoreContextFromViper().GetPassphraseFromStdin(name)
+			if err != nil {
+				return err
+			}
+			sig, pubKey, err := keybase.Sign(name, passphrase, signMsg.Bytes())
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, auth.StdSignature{
+				PubKey:        pubKey,
+				Signature:     sig,
+				AccountNumber: accnum,
+				Sequence:      sequence,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().String(client.FlagName, "", "Name of the member key with which to sign")
+	cmd.Flags().String(flagMultisig, "", "Name of the multisig key")
+	return cmd
+}
+
+// MultiSignCmd combines the partial signatures of the members of a multisig
+// key into the signature of a tx
+func MultiSignCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multisign <file> <multisig-name> <signature-file>...",
+		Short: "Combine the partial signatures of a tx of a multisig key",
+		Long: `Combine the partial signatures of the members of the multisig key, as
+printed by sign-partial, into the signature of the tx in the file, an
+unsigned StdSignMsg in JSON whose only signer is the multisig key. The signed
+StdTx is printed as JSON, or broadcast with --broadcast.`,
+		Args: cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			signMsg, err := readStdSignMsg(cdc, args[0])
+			if err != nil {
+				return err
+			}
+			multisig, err := getMultisigKey(args[1])
+			if err != nil {
+				return err
+			}
+			accnum, sequence, err := multisigSignerNumbers(signMsg, multisig)
+			if err != nil {
+				return err
+			}
+			if len(auth.StdTx{Msgs: signMsg.Msgs}.GetSigners()) != 1 {
+				return errors.New("txs with other signers than the multisig key are not supported")
+			}
+
+			signBytes := signMsg.Bytes()
+			multiSig := auth.NewMultiSignature(len(multisig.PubKeys))
+			for _, file := range args[2:] {
+				var sig auth.StdSignature
+				bz, err := ioutil.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if err = cdc.UnmarshalJSON(bz, &sig); err != nil {
+					return errors.Errorf("Invalid signature in %s: %v", file, err)
+				}
+				if sig.PubKey == nil || !sig.PubKey.VerifyBytes(signBytes, sig.Signature) {
+					return errors.Errorf("Signature in %s is not of the tx", file)
+				}
+				if err = multiSig.AddSignature(multisig, sig.PubKey, sig.Signature); err != nil {
+					return errors.Errorf("Signature in %s: %v", file, err)
+				}
+			}
+			if !multisig.VerifyBytes(signBytes, multiSig) {
+				return errors.Errorf("%d of the %d members signed, %d must sign",
+					len(multiSig.Sigs), len(multisig.PubKeys), multisig.K)
+			}
+
+			tx := auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{{
+				PubKey:        multisig,
+				Signature:     multiSig,
+				AccountNumber: accnum,
+				Sequence:      sequence,
+			}})
+
+			if viper.GetBool(flagBroadcast) {
+				txBytes, err := cdc.MarshalBinary(tx)
+				if err != nil {
+					return err
+				}
+				res, err := context.NewCoreContextFromViper().BroadcastTx(txBytes)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+				return nil
+			}
+			output, err := wire.MarshalJSONIndent(cdc, tx)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagBroadcast, false, "Broadcast the signed tx rather than print it")
+	cmd.Flags().String(client.FlagNode, "tcp://localhost:46657", "<host>:<port> to tendermint rpc interface for this chain")
+	return cmd
+}
+
+func readStdSignMsg(cdc *wire.Codec, file string) (signMsg auth.StdSignMsg, err error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	if err = cdc.UnmarshalJSON(bz, &signMsg); err != nil {
+		return signMsg, errors.Errorf("Invalid tx in %s: %v", file, err)
+	}
+	return
+}
+
+func getMultisigKey(name string) (auth.MultisigThresholdPubKey, error) {
+	info, err := keys.GetMultisigKey(name)
+	if err != nil {
+		return auth.MultisigThresholdPubKey{}, err
+	}
+	return info.PubKey.(auth.MultisigThresholdPubKey), nil
+}
+
+// multisigSignerNumbers returns the account number and sequence of the
+// signature of the multisig key in the tx
+func multisigSignerNumbers(signMsg auth.StdSignMsg, multisig auth.MultisigThresholdPubKey) (accnum, sequence int64, err error) {
+	signers := auth.StdTx{Msgs: signMsg.Msgs}.GetSigners()
+	if len(signMsg.AccountNumbers) != len(signers) || len(signMsg.Sequences) != len(signers) {
+		return 0, 0, errors.New("Invalid tx: one account number and sequence per signer required")
+	}
+	for i, signer := range signers {
+		if bytes.Equal(signer, multisig.Address()) {
+			return signMsg.AccountNumbers[i], signMsg.Sequences[i], nil
+		}
+	}
+	return 0, 0, errors.New("the multisig key is not a signer of the tx")
+}
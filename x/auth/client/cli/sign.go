@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// SignTxCmd signs an unsigned tx offline
+func SignTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign <file>",
+		Short: "Sign a tx generated with --generate-only",
+		Long: `Sign the tx in the file, an unsigned StdSignMsg in JSON as printed by tx
+commands with --generate-only, with the key of --name. No node is needed:
+the account number and sequence of the tx, which --account-number and
+--sequence override, must be those of the account when the tx is broadcast.
+The signed StdTx is printed as JSON, for the broadcast command.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			signMsg, err := readStdSignMsg(cdc, args[0])
+			if err != nil {
+				return err
+			}
+			if len(signMsg.AccountNumbers) == 1 && len(signMsg.Sequences) == 1 {
+				if cmd.Flags().Changed(client.FlagAccountNumber) {
+					signMsg.AccountNumbers[0] = viper.GetInt64(client.FlagAccountNumber)
+				}
+				if cmd.Flags().Changed(client.FlagSequence) {
+					signMsg.Sequences[0] = viper.GetInt64(client.FlagSequence)
+				}
+			}
+
+			ctx := context.NewCoreContextFromViper()
+			passphrase, err := ctx.GetPassphraseFromStdin(ctx.FromAddressName)
+			if err != nil {
+				return err
+			}
+			tx, err := ctx.SignStdTx(ctx.FromAddressName, passphrase, signMsg)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, tx)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().String(client.FlagName, "", "Name of private key with which to sign")
+	cmd.Flags().Int64(client.FlagAccountNumber, 0, "AccountNumber number to sign the tx")
+	cmd.Flags().Int64(client.FlagSequence, 0, "Sequence number to sign the tx")
+	return cmd
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// A granter gives a grantee a fee allowance to let the grantee's txs name
+// the granter as their fee payer. The allowances are kept in the store of
+// the FeeCollectionKeeper, which the ante handler already has.
+
+var feeAllowanceKeyPrefix = []byte("feeAllowance:")
+
+// FeeAllowance is the allowance of fees a granter pays for a grantee:
+// up to SpendLimit in total, unless it is empty, and until the block time
+// Expiration, unless it is zero.
+type FeeAllowance struct {
+	SpendLimit sdk.Coins `json:"spend_limit"`
+	Expiration int64     `json:"expiration"`
+}
+
+// IsExpired returns whether the allowance has expired at the block time
+func (fa FeeAllowance) IsExpired(blockTime int64) bool {
+	return fa.Expiration != 0 && blockTime >= fa.Expiration
+}
+
+// FeeAllowanceKey returns the store key of the allowance of the granter
+// for the grantee
+func FeeAllowanceKey(granter, grantee sdk.Address) []byte {
+	key := append(append([]byte{}, feeAllowanceKeyPrefix...), byte(len(granter)))
+	return append(append(key, granter.Bytes()...), grantee.Bytes()...)
+}
+
+// GetFeeAllowance returns the allowance of the granter for the grantee
+func (fck FeeCollectionKeeper) GetFeeAllowance(ctx sdk.Context, granter, grantee sdk.Address) (allowance FeeAllowance, ok bool) {
+	store := ctx.KVStore(fck.key)
+	bz := store.Get(FeeAllowanceKey(granter, grantee))
+	if bz == nil {
+		return allowance, false
+	}
+	fck.cdc.MustUnmarshalBinary(bz, &allowance)
+	return allowance, true
+}
+
+// SetFeeAllowance sets the allowance of the granter for the grantee,
+// replacing any previous one
+func (fck FeeCollectionKeeper) SetFeeAllowance(ctx sdk.Context, granter, grantee sdk.Address, allowance FeeAllowance) {
+	bz := fck.cdc.MustMarshalBinary(allowance)
+	store := ctx.KVStore(fck.key)
+	store.Set(FeeAllowanceKey(granter, grantee), bz)
+}
+
+// RevokeFeeAllowance deletes the allowance of the granter for the grantee
+func (fck FeeCollectionKeeper) RevokeFeeAllowance(ctx sdk.Context, granter, grantee sdk.Address) {
+	store := ctx.KVStore(fck.key)
+	store.Delete(FeeAllowanceKey(granter, grantee))
+}
+
+// useFeeAllowance spends fee from the allowance of the granter for the
+// grantee, which is deleted once spent. An expired allowance is left in
+// the store, as the rejected tx mustn't write to the state, until the
+// granter revokes or replaces it.
+func (fck FeeCollectionKeeper) useFeeAllowance(ctx sdk.Context, granter, grantee sdk.Address, fee sdk.Coins) sdk.Result {
+	allowance, ok := fck.GetFeeAllowance(ctx, granter, grantee)
+	if !ok {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%v has no fee allowance from %v", grantee, granter)).Result()
+	}
+	if allowance.IsExpired(ctx.BlockHeader().Time) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("the fee allowance of %v from %v has expired", grantee, granter)).Result()
+	}
+	if len(allowance.SpendLimit) == 0 || fee.IsZero() {
+		return sdk.Result{}
+	}
+
+	left := allowance.SpendLimit.Minus(fee)
+	if !left.IsNotNegative() {
+		return sdk.ErrInsufficientFunds(fmt.Sprintf("fee allowance %s < %s", allowance.SpendLimit, fee)).Result()
+	}
+	if left.IsZero() {
+		fck.RevokeFeeAllowance(ctx, granter, grantee)
+		return sdk.Result{}
+	}
+	allowance.SpendLimit = left
+	fck.SetFeeAllowance(ctx, granter, grantee, allowance)
+	return sdk.Result{}
+}
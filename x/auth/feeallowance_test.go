@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tmlibs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+func TestFeeAllowanceGetSetRevoke(t *testing.T) {
+	ms, _, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+	fck := NewFeeCollectionKeeper(cdc, capKey2)
+	_, granter := privAndAddr()
+	_, grantee := privAndAddr()
+
+	_, ok := fck.GetFeeAllowance(ctx, granter, grantee)
+	assert.False(t, ok)
+
+	allowance := FeeAllowance{SpendLimit: sdk.Coins{{"atom", 100}}, Expiration: 10}
+	fck.SetFeeAllowance(ctx, granter, grantee, allowance)
+	got, ok := fck.GetFeeAllowance(ctx, granter, grantee)
+	require.True(t, ok)
+	assert.Equal(t, allowance, got)
+
+	// the allowance is directed
+	_, ok = fck.GetFeeAllowance(ctx, grantee, granter)
+	assert.False(t, ok)
+
+	fck.RevokeFeeAllowance(ctx, granter, grantee)
+	_, ok = fck.GetFeeAllowance(ctx, granter, grantee)
+	assert.False(t, ok)
+}
+
+func TestMsgGrantFeeAllowanceValidation(t *testing.T) {
+	granter, grantee := sdk.Address([]byte("granter")), sdk.Address([]byte("grantee"))
+
+	cases := []struct {
+		valid bool
+		msg   MsgGrantFeeAllowance
+	}{
+		{true, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{})},
+		{true, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{sdk.Coins{{"atom", 10}}, 100})},
+		{false, NewMsgGrantFeeAllowance(nil, grantee, FeeAllowance{})},
+		{false, NewMsgGrantFeeAllowance(granter, nil, FeeAllowance{})},
+		{false, NewMsgGrantFeeAllowance(granter, granter, FeeAllowance{})},
+		{false, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{sdk.Coins{{"atom", -10}}, 0})},
+		{false, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{sdk.Coins{{"b", 1}, {"a", 1}}, 0})},
+		{false, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{nil, -1})},
+	}
+
+	for i, tc := range cases {
+		err := tc.msg.ValidateBasic()
+		if tc.valid {
+			assert.Nil(t, err, "%d: %v", i, err)
+		} else {
+			assert.NotNil(t, err, "%d", i)
+		}
+	}
+}
+
+func TestAnteHandlerFeePayer(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	handler := NewFeeAllowanceHandler(feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid", Time: 100}, false, nil, log.NewNopLogger())
+
+	// a granter with coins and a grantee without any
+	_, granter := privAndAddr()
+	priv, grantee := privAndAddr()
+	granterAcc := mapper.NewAccountWithAddress(ctx, granter)
+	granterAcc.SetCoins(newCoins())
+	mapper.SetAccount(ctx, granterAcc)
+	mapper.SetAccount(ctx, mapper.NewAccountWithAddress(ctx, grantee))
+
+	msgs := []sdk.Msg{newTestMsg(grantee)}
+	privs, accnums := []crypto.PrivKey{priv}, []int64{1}
+	fee := newStdFee()
+	fee.Payer = granter
+
+	// the payer must have granted an allowance
+	tx := newTestTx(ctx, msgs, privs, accnums, []int64{0}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+
+	// the allowance pays for the fee of the tx
+	res := handler(ctx, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{sdk.Coins{{"atom", 250}}, 200}))
+	require.True(t, res.IsOK())
+	checkValidTx(t, anteHandler, ctx, tx)
+	assert.Equal(t, newCoins().Minus(fee.Amount), mapper.GetAccount(ctx, granter).GetCoins())
+	assert.True(t, mapper.GetAccount(ctx, grantee).GetCoins().IsZero())
+	assert.True(t, feeCollector.GetCollectedFees(ctx).IsEqual(fee.Amount))
+	allowance, _ := feeCollector.GetFeeAllowance(ctx, granter, grantee)
+	assert.Equal(t, sdk.Coins{{"atom", 100}}, allowance.SpendLimit)
+
+	// fees over the spend limit left aren't paid
+	tx = newTestTx(ctx, msgs, privs, accnums, []int64{1}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInsufficientFunds)
+
+	// the allowance expires
+	handler(ctx, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{nil, 200}))
+	checkValidTx(t, anteHandler, ctx, tx)
+	ctx = ctx.WithBlockHeader(abci.Header{ChainID: "mychainid", Time: 200})
+	tx = newTestTx(ctx, msgs, privs, accnums, []int64{2}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+
+	// the rejected tx doesn't revoke the expired allowance
+	_, ok := feeCollector.GetFeeAllowance(ctx, granter, grantee)
+	assert.True(t, ok)
+
+	// and can be revoked
+	handler(ctx, NewMsgGrantFeeAllowance(granter, grantee, FeeAllowance{}))
+	checkValidTx(t, anteHandler, ctx, tx)
+	handler(ctx, NewMsgRevokeFeeAllowance(granter, grantee))
+	tx = newTestTx(ctx, msgs, privs, accnums, []int64{3}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+}
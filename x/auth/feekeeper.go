@@ -6,7 +6,8 @@ import (
 )
 
 var (
-	collectedFeesKey = []byte("collectedFees")
+	collectedFeesKey    = []byte("collectedFees")
+	minimumGasPricesKey = []byte("minimumGasPrices")
 )
 
 // This FeeCollectionKeeper handles collection of fees in the anteHandler
@@ -60,3 +61,23 @@ func (fck FeeCollectionKeeper) addCollectedFees(ctx sdk.Context, coins sdk.Coins
 func (fck FeeCollectionKeeper) ClearCollectedFees(ctx sdk.Context) {
 	fck.setCollectedFees(ctx, sdk.Coins{})
 }
+
+// Gets the chain-wide minimum gas prices, which every tx must pay
+func (fck FeeCollectionKeeper) GetMinimumGasPrices(ctx sdk.Context) sdk.GasPrices {
+	store := ctx.KVStore(fck.key)
+	bz := store.Get(minimumGasPricesKey)
+	if bz == nil {
+		return nil
+	}
+
+	prices := sdk.GasPrices{}
+	fck.cdc.MustUnmarshalBinary(bz, &prices)
+	return prices
+}
+
+// Sets the chain-wide minimum gas prices
+func (fck FeeCollectionKeeper) SetMinimumGasPrices(ctx sdk.Context, prices sdk.GasPrices) {
+	bz := fck.cdc.MustMarshalBinary(prices)
+	store := ctx.KVStore(fck.key)
+	store.Set(minimumGasPricesKey, bz)
+}
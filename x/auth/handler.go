@@ -32,3 +32,24 @@ func handleMsgChangeKey(ctx sdk.Context, am AccountMapper, msg MsgChangeKey) sdk
 		Tags: sdk.NewTags("action", []byte("changePubkey"), "address", msg.Address.Bytes(), "pubkey", msg.NewPubKey.Bytes()),
 	}
 }
+
+// NewFeeAllowanceHandler returns a handler for "auth/feeAllowance" type messages.
+func NewFeeAllowanceHandler(fck FeeCollectionKeeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgGrantFeeAllowance:
+			fck.SetFeeAllowance(ctx, msg.Granter, msg.Grantee, msg.Allowance)
+			return sdk.Result{
+				Tags: sdk.NewTags("action", []byte("grantFeeAllowance"), "granter", msg.Granter.Bytes(), "grantee", msg.Grantee.Bytes()),
+			}
+		case MsgRevokeFeeAllowance:
+			fck.RevokeFeeAllowance(ctx, msg.Granter, msg.Grantee)
+			return sdk.Result{
+				Tags: sdk.NewTags("action", []byte("revokeFeeAllowance"), "granter", msg.Granter.Bytes(), "grantee", msg.Grantee.Bytes()),
+			}
+		default:
+			errMsg := "Unrecognized feeAllowance Msg type: " + reflect.TypeOf(msg).Name()
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
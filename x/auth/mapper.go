@@ -72,6 +72,16 @@ func (am AccountMapper) SetAccount(ctx sdk.Context, acc Account) {
 	store := ctx.KVStore(am.key)
 	bz := am.encodeAccount(acc)
 	store.Set(AddressStoreKey(addr), bz)
+	am.updateInactiveIndex(ctx, acc)
+}
+
+// RemoveAccount removes the account at address from the store. The global
+// account number isn't decremented, so if the address gets an account again
+// it has a new account number, and the sigs for the removed account are invalid.
+func (am AccountMapper) RemoveAccount(ctx sdk.Context, addr sdk.Address) {
+	store := ctx.KVStore(am.key)
+	store.Delete(AddressStoreKey(addr))
+	am.removeFromInactiveIndex(ctx, addr)
 }
 
 // Implements sdk.AccountMapper.
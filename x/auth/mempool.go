@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// In CheckTx, txs are given a priority from their effective gas price, ie.
+// the amount of the first fee coin per unit of gas. A tx may replace the
+// pending tx of its first signer with the same sequence by paying a higher
+// gas price in the same denomination ("replace-by-fee"). Pending txs are
+// keyed by their first signer rather than their fee payer: txs paid out of
+// a fee allowance can't be replaced, so the first signer is the fee payer
+// of all the txs which can.
+// Pending txs are only recorded in the CheckTx state, so they are forgotten
+// on every Commit, once Tendermint rechecks its mempool.
+// A replacement reuses the sequence of the first signer only: other signers
+// sign it with their next sequence. Only the fee of the replaced tx is
+// refunded, the effects of its msgs stay in the CheckTx state until the
+// next Commit, so the replacement is checked against them.
+
+// Gas prices are scaled by priorityPrecision to become integer priorities
+const priorityPrecision = 1000000
+
+var pendingTxKeyPrefix = []byte("pendingTx:")
+
+// pendingTx is the record of a tx accepted by CheckTx
+type pendingTx struct {
+	Denom     string    `json:"denom"`
+	GasPrice  sdk.Rat   `json:"gas_price"`
+	Fee       sdk.Coins `json:"fee"`
+	Sponsored bool      `json:"sponsored"`
+}
+
+// effectiveGasPrice returns the amount of the first coin of the fee per unit
+// of gas, and the denomination of that coin
+func effectiveGasPrice(fee StdFee) (denom string, price sdk.Rat) {
+	if len(fee.Amount) == 0 || fee.Gas <= 0 {
+		return "", sdk.ZeroRat()
+	}
+	coin := fee.Amount[0]
+	return coin.Denom, sdk.NewRat(coin.Amount, fee.Gas)
+}
+
+// txPriority returns the mempool priority of a tx paying fee:
+// its effective gas price, scaled by priorityPrecision, up to math.MaxInt64
+func txPriority(fee StdFee) int64 {
+	_, price := effectiveGasPrice(fee)
+	priority := price.Mul(sdk.NewRat(priorityPrecision)).EvaluateBig()
+	if priority.Cmp(big.NewInt(math.MaxInt64)) > 0 {
+		return math.MaxInt64
+	}
+	return priority.Int64()
+}
+
+// checkReplacement returns an error unless a tx paying fee may replace
+// the pending tx, ie. it pays a higher gas price in the same denomination.
+// The fees of txs paid out of a fee allowance aren't refunded, so those
+// txs can't replace or be replaced.
+func checkReplacement(pending pendingTx, fee StdFee, sponsored bool) sdk.Result {
+	if sponsored || pending.Sponsored {
+		return sdk.ErrInsufficientFee("txs paid out of a fee allowance can't be replaced").Result()
+	}
+	price := sdk.ZeroRat()
+	if fee.Gas > 0 {
+		price = sdk.NewRat(fee.Amount.AmountOf(pending.Denom), fee.Gas)
+	}
+	if !price.GT(pending.GasPrice) {
+		return sdk.ErrInsufficientFee(fmt.Sprintf(
+			"replacement tx must pay a gas price above %v%s, got %v%s",
+			pending.GasPrice.RatString(), pending.Denom, price.RatString(), pending.Denom)).Result()
+	}
+	return sdk.Result{}
+}
+
+func pendingTxKey(addr sdk.Address, sequence int64) []byte {
+	key := append(append([]byte{}, pendingTxKeyPrefix...), addr.Bytes()...)
+	return append(key, []byte(fmt.Sprintf(":%d", sequence))...)
+}
+
+// Gets the pending tx of the first signer with the given sequence
+func (fck FeeCollectionKeeper) getPendingTx(ctx sdk.Context, addr sdk.Address, sequence int64) (pending pendingTx, ok bool) {
+	store := ctx.KVStore(fck.key)
+	bz := store.Get(pendingTxKey(addr, sequence))
+	if bz == nil {
+		return pending, false
+	}
+	fck.cdc.MustUnmarshalBinary(bz, &pending)
+	return pending, true
+}
+
+// Sets the pending tx of the first signer with the given sequence
+func (fck FeeCollectionKeeper) setPendingTx(ctx sdk.Context, addr sdk.Address, sequence int64, pending pendingTx) {
+	bz := fck.cdc.MustMarshalBinary(pending)
+	store := ctx.KVStore(fck.key)
+	store.Set(pendingTxKey(addr, sequence), bz)
+}
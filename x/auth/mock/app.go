@@ -22,6 +22,7 @@ type App struct {
 	Cdc        *wire.Codec // public since the codec is passed into the module anyways.
 	KeyMain    *sdk.KVStoreKey
 	KeyAccount *sdk.KVStoreKey
+	KeyFee     *sdk.KVStoreKey
 
 	// TODO: Abstract this out from not needing to be auth specifically
 	AccountMapper       auth.AccountMapper
@@ -47,6 +48,7 @@ func NewApp() *App {
 		Cdc:        cdc,
 		KeyMain:    sdk.NewKVStoreKey("main"),
 		KeyAccount: sdk.NewKVStoreKey("acc"),
+		KeyFee:     sdk.NewKVStoreKey("fee"),
 	}
 
 	// define the accountMapper
@@ -56,6 +58,8 @@ func NewApp() *App {
 		&auth.BaseAccount{}, // prototype
 	)
 
+	app.FeeCollectionKeeper = auth.NewFeeCollectionKeeper(app.Cdc, app.KeyFee)
+
 	// initialize the app, the chainers and blockers can be overwritten before calling complete setup
 	app.SetInitChainer(app.InitChainer)
 
@@ -69,6 +73,7 @@ func (app *App) CompleteSetup(t *testing.T, newKeys []*sdk.KVStoreKey) {
 
 	newKeys = append(newKeys, app.KeyMain)
 	newKeys = append(newKeys, app.KeyAccount)
+	newKeys = append(newKeys, app.KeyFee)
 	app.MountStoresIAVL(newKeys...)
 	err := app.LoadLatestVersion(app.KeyMain)
 	require.NoError(t, err)
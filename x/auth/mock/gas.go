@@ -0,0 +1,14 @@
+package mock
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MeasureGas runs handler on msg with a fresh gas meter and returns its
+// result and the gas it consumed, so that tests can track the gas used by
+// handlers against a given state
+func MeasureGas(ctx sdk.Context, handler sdk.Handler, msg sdk.Msg) (sdk.Result, sdk.Gas) {
+	meter := sdk.NewInfiniteGasMeter()
+	res := handler(ctx.WithGasMeter(meter), msg)
+	return res, meter.GasConsumed()
+}
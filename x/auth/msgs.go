@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"bytes"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	crypto "github.com/tendermint/go-crypto"
 )
@@ -39,3 +41,95 @@ func (msg MsgChangeKey) GetSignBytes() []byte {
 func (msg MsgChangeKey) GetSigners() []sdk.Address {
 	return []sdk.Address{msg.Address}
 }
+
+//__________________________________________________________________
+
+// MsgGrantFeeAllowance - grant a fee allowance to the grantee, replacing
+// any previous one, to let its txs be paid by the granter
+type MsgGrantFeeAllowance struct {
+	Granter   sdk.Address  `json:"granter"`
+	Grantee   sdk.Address  `json:"grantee"`
+	Allowance FeeAllowance `json:"allowance"`
+}
+
+var _ sdk.Msg = MsgGrantFeeAllowance{}
+
+// NewMsgGrantFeeAllowance - msg to grant a fee allowance
+func NewMsgGrantFeeAllowance(granter, grantee sdk.Address, allowance FeeAllowance) MsgGrantFeeAllowance {
+	return MsgGrantFeeAllowance{Granter: granter, Grantee: grantee, Allowance: allowance}
+}
+
+// Implements Msg.
+func (msg MsgGrantFeeAllowance) Type() string { return "auth/feeAllowance/grant" }
+
+// Implements Msg.
+func (msg MsgGrantFeeAllowance) ValidateBasic() sdk.Error {
+	if len(msg.Granter) == 0 || len(msg.Grantee) == 0 {
+		return sdk.ErrInvalidAddress("missing granter or grantee")
+	}
+	if bytes.Equal(msg.Granter, msg.Grantee) {
+		return sdk.ErrInvalidAddress("the granter can't be the grantee")
+	}
+	limit := msg.Allowance.SpendLimit
+	if len(limit) != 0 && (!limit.IsValid() || !limit.IsPositive()) {
+		return sdk.ErrInvalidCoins(limit.String())
+	}
+	if msg.Allowance.Expiration < 0 {
+		return sdk.ErrUnknownRequest("negative expiration")
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgGrantFeeAllowance) GetSignBytes() []byte {
+	b, err := msgCdc.MarshalJSON(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Implements Msg.
+func (msg MsgGrantFeeAllowance) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.Granter}
+}
+
+//__________________________________________________________________
+
+// MsgRevokeFeeAllowance - revoke the fee allowance of the grantee
+type MsgRevokeFeeAllowance struct {
+	Granter sdk.Address `json:"granter"`
+	Grantee sdk.Address `json:"grantee"`
+}
+
+var _ sdk.Msg = MsgRevokeFeeAllowance{}
+
+// NewMsgRevokeFeeAllowance - msg to revoke a fee allowance
+func NewMsgRevokeFeeAllowance(granter, grantee sdk.Address) MsgRevokeFeeAllowance {
+	return MsgRevokeFeeAllowance{Granter: granter, Grantee: grantee}
+}
+
+// Implements Msg.
+func (msg MsgRevokeFeeAllowance) Type() string { return "auth/feeAllowance/revoke" }
+
+// Implements Msg.
+func (msg MsgRevokeFeeAllowance) ValidateBasic() sdk.Error {
+	if len(msg.Granter) == 0 || len(msg.Grantee) == 0 {
+		return sdk.ErrInvalidAddress("missing granter or grantee")
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgRevokeFeeAllowance) GetSignBytes() []byte {
+	b, err := msgCdc.MarshalJSON(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Implements Msg.
+func (msg MsgRevokeFeeAllowance) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.Granter}
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	crypto "github.com/tendermint/go-crypto"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// MaxMultisigKeys bounds the member keys of a multisig key: decoding the
+// key and deriving its address grow with its members, before any gas is
+// charged for its signatures.
+const MaxMultisigKeys = 20
+
+var _ crypto.PubKey = MultisigThresholdPubKey{}
+var _ crypto.Signature = MultiSignature{}
+
+// Register the multisig key and signature types, which implement the
+// go-crypto interfaces, on the codec
+func RegisterMultisig(cdc *wire.Codec) {
+	cdc.RegisterConcrete(MultisigThresholdPubKey{}, "cosmos-sdk/MultisigThresholdPubKey", nil)
+	cdc.RegisterConcrete(MultiSignature{}, "cosmos-sdk/MultiSignature", nil)
+}
+
+// MultisigThresholdPubKey is a K-of-N multisig public key: it verifies a
+// MultiSignature holding the signatures of at least K of its N member keys.
+// Its address is derived from K and the member keys, so an account with
+// the address is controlled by the members together.
+type MultisigThresholdPubKey struct {
+	K       int             `json:"threshold"`
+	PubKeys []crypto.PubKey `json:"pubkeys"`
+}
+
+// NewMultisigThresholdPubKey returns the multisig key of threshold k over
+// the member keys, which are sorted by address so that the same members
+// always make the same key
+func NewMultisigThresholdPubKey(k int, pubKeys []crypto.PubKey) (MultisigThresholdPubKey, error) {
+	if err := (MultisigThresholdPubKey{K: k, PubKeys: pubKeys}).ValidateBasic(); err != nil {
+		return MultisigThresholdPubKey{}, err
+	}
+	sorted := make([]crypto.PubKey, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Address(), sorted[j].Address()) < 0
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Equals(sorted[i-1]) {
+			return MultisigThresholdPubKey{}, fmt.Errorf("duplicate member key %v", sorted[i].Address())
+		}
+	}
+	return MultisigThresholdPubKey{K: k, PubKeys: sorted}, nil
+}
+
+// ValidateBasic checks the threshold, and that there are at most
+// MaxMultisigKeys members, none of which is a multisig key itself
+func (pk MultisigThresholdPubKey) ValidateBasic() error {
+	n := len(pk.PubKeys)
+	if n > MaxMultisigKeys {
+		return fmt.Errorf("multisig key has %d members, at most %d are allowed", n, MaxMultisigKeys)
+	}
+	if pk.K <= 0 || pk.K > n {
+		return fmt.Errorf("threshold must be between 1 and %d, got %d", n, pk.K)
+	}
+	for _, member := range pk.PubKeys {
+		if _, ok := member.(MultisigThresholdPubKey); ok {
+			return errors.New("multisig keys can't be members of a multisig key")
+		}
+	}
+	return nil
+}
+
+// Implements crypto.PubKey.
+func (pk MultisigThresholdPubKey) Address() crypto.Address {
+	hasher := ripemd160.New()
+	hasher.Write(pk.Bytes()) // does not error
+	return crypto.Address(hasher.Sum(nil))
+}
+
+// Implements crypto.PubKey.
+func (pk MultisigThresholdPubKey) Bytes() []byte {
+	bz, err := msgCdc.MarshalBinaryBare(pk)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Implements crypto.PubKey. The signature must be a MultiSignature with
+// the valid signatures of at least K members.
+func (pk MultisigThresholdPubKey) VerifyBytes(msg []byte, sig crypto.Signature) bool {
+	multiSig, ok := sig.(MultiSignature)
+	if !ok {
+		return false
+	}
+	n := len(pk.PubKeys)
+	if n > MaxMultisigKeys ||
+		len(multiSig.Signers) != compactBitArraySize(n) ||
+		multiSig.Signers.NumTrueBits() != len(multiSig.Sigs) ||
+		len(multiSig.Sigs) < pk.K {
+		return false
+	}
+	j := 0
+	for i := 0; i < n; i++ {
+		if !multiSig.Signers.GetIndex(i) {
+			continue
+		}
+		if !pk.PubKeys[i].VerifyBytes(msg, multiSig.Sigs[j]) {
+			return false
+		}
+		j++
+	}
+	// Any other bit set is past the members.
+	return j == len(multiSig.Sigs)
+}
+
+// Implements crypto.PubKey.
+func (pk MultisigThresholdPubKey) Equals(other crypto.PubKey) bool {
+	return bytes.Equal(pk.Bytes(), other.Bytes())
+}
+
+// MemberIndex returns the index of a member key, or -1 if it isn't one
+func (pk MultisigThresholdPubKey) MemberIndex(member crypto.PubKey) int {
+	for i, pubKey := range pk.PubKeys {
+		if pubKey.Equals(member) {
+			return i
+		}
+	}
+	return -1
+}
+
+//__________________________________________________________
+
+// MultiSignature is the signature of a MultisigThresholdPubKey: the
+// signatures of the members who signed, in the order of the members, and
+// the bits of those members in a compact bit array.
+type MultiSignature struct {
+	Signers CompactBitArray    `json:"signers"`
+	Sigs    []crypto.Signature `json:"sigs"`
+}
+
+// NewMultiSignature returns an empty signature of a multisig key with n
+// members
+func NewMultiSignature(n int) MultiSignature {
+	return MultiSignature{Signers: NewCompactBitArray(n)}
+}
+
+// AddSignature adds the signature of a member of the multisig key,
+// replacing any previous signature of the member
+func (ms *MultiSignature) AddSignature(pk MultisigThresholdPubKey, member crypto.PubKey, sig crypto.Signature) error {
+	i := pk.MemberIndex(member)
+	if i < 0 {
+		return errors.New("not a member of the multisig key")
+	}
+	if len(ms.Signers) != compactBitArraySize(len(pk.PubKeys)) {
+		return errors.New("signature is not of the multisig key")
+	}
+
+	// The signatures are in the order of the members.
+	j := 0
+	for k := 0; k < i; k++ {
+		if ms.Signers.GetIndex(k) {
+			j++
+		}
+	}
+	if ms.Signers.GetIndex(i) {
+		ms.Sigs[j] = sig
+		return nil
+	}
+	ms.Signers.SetIndex(i, true)
+	ms.Sigs = append(ms.Sigs, nil)
+	copy(ms.Sigs[j+1:], ms.Sigs[j:])
+	ms.Sigs[j] = sig
+	return nil
+}
+
+// Implements crypto.Signature.
+func (ms MultiSignature) Bytes() []byte {
+	bz, err := msgCdc.MarshalBinaryBare(ms)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Implements crypto.Signature.
+func (ms MultiSignature) IsZero() bool {
+	return len(ms.Sigs) == 0
+}
+
+// Implements crypto.Signature.
+func (ms MultiSignature) Equals(other crypto.Signature) bool {
+	return bytes.Equal(ms.Bytes(), other.Bytes())
+}
+
+//__________________________________________________________
+
+// CompactBitArray is an array of bits packed in bytes, bit i being the
+// (i%8)th most significant bit of byte i/8
+type CompactBitArray []byte
+
+// NewCompactBitArray returns an array of n unset bits
+func NewCompactBitArray(n int) CompactBitArray {
+	return make(CompactBitArray, compactBitArraySize(n))
+}
+
+func compactBitArraySize(n int) int {
+	return (n + 7) / 8
+}
+
+// GetIndex returns bit i, which is unset if past the array
+func (ba CompactBitArray) GetIndex(i int) bool {
+	if i < 0 || i/8 >= len(ba) {
+		return false
+	}
+	return ba[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+// SetIndex sets bit i, which must be in the array
+func (ba CompactBitArray) SetIndex(i int, v bool) {
+	if v {
+		ba[i/8] |= 0x80 >> uint(i%8)
+	} else {
+		ba[i/8] &^= 0x80 >> uint(i%8)
+	}
+}
+
+// NumTrueBits returns the number of bits set
+func (ba CompactBitArray) NumTrueBits() int {
+	n := 0
+	for _, b := range ba {
+		for ; b != 0; b &= b - 1 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tmlibs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+// generate n priv keys and their k-of-n multisig key
+func privsAndMultisig(t *testing.T, k, n int) ([]crypto.PrivKey, MultisigThresholdPubKey) {
+	privs := make([]crypto.PrivKey, n)
+	pubKeys := make([]crypto.PubKey, n)
+	for i := range privs {
+		privs[i] = crypto.GenPrivKeyEd25519()
+		pubKeys[i] = privs[i].PubKey()
+	}
+	pk, err := NewMultisigThresholdPubKey(k, pubKeys)
+	require.Nil(t, err)
+	return privs, pk
+}
+
+// the multisignature of the privs
+func multiSign(t *testing.T, pk MultisigThresholdPubKey, privs []crypto.PrivKey, msg []byte) MultiSignature {
+	multiSig := NewMultiSignature(len(pk.PubKeys))
+	for _, priv := range privs {
+		require.Nil(t, multiSig.AddSignature(pk, priv.PubKey(), priv.Sign(msg)))
+	}
+	return multiSig
+}
+
+func TestMultisigThresholdPubKey(t *testing.T) {
+	privs, pk := privsAndMultisig(t, 2, 3)
+	msg := []byte("treasury")
+
+	// k of the n members must sign, in any order
+	assert.False(t, pk.VerifyBytes(msg, multiSign(t, pk, privs[:1], msg)))
+	assert.True(t, pk.VerifyBytes(msg, multiSign(t, pk, privs[:2], msg)))
+	assert.True(t, pk.VerifyBytes(msg, multiSign(t, pk, []crypto.PrivKey{privs[2], privs[0]}, msg)))
+	assert.True(t, pk.VerifyBytes(msg, multiSign(t, pk, privs, msg)))
+
+	// signatures are of the members, of the msg
+	multiSig := multiSign(t, pk, privs[:2], []byte("other"))
+	assert.False(t, pk.VerifyBytes(msg, multiSig))
+	multiSig = multiSign(t, pk, privs[:1], msg)
+	assert.NotNil(t, multiSig.AddSignature(pk, crypto.GenPrivKeyEd25519().PubKey(), privs[1].Sign(msg)))
+	assert.False(t, pk.VerifyBytes(msg, privs[0].Sign(msg)))
+
+	// the bits must match the signatures
+	multiSig = multiSign(t, pk, privs[:2], msg)
+	multiSig.Signers.SetIndex(7, true)
+	assert.False(t, pk.VerifyBytes(msg, multiSig))
+	multiSig = multiSign(t, pk, privs[:2], msg)
+	multiSig.Sigs = multiSig.Sigs[:1]
+	assert.False(t, pk.VerifyBytes(msg, multiSig))
+
+	// signing again replaces the signature of the member
+	multiSig = multiSign(t, pk, privs[:2], msg)
+	require.Nil(t, multiSig.AddSignature(pk, privs[0].PubKey(), privs[0].Sign(msg)))
+	assert.Equal(t, 2, len(multiSig.Sigs))
+	assert.True(t, pk.VerifyBytes(msg, multiSig))
+
+	// the key and the signature survive the codec
+	cdc := wire.NewCodec()
+	wire.RegisterCrypto(cdc)
+	RegisterMultisig(cdc)
+	var pubKey crypto.PubKey
+	require.Nil(t, cdc.UnmarshalBinaryBare(pk.Bytes(), &pubKey))
+	assert.True(t, pk.Equals(pubKey))
+	var sig crypto.Signature
+	require.Nil(t, cdc.UnmarshalBinaryBare(multiSig.Bytes(), &sig))
+	assert.True(t, pubKey.VerifyBytes(msg, sig))
+}
+
+func TestMultisigThresholdPubKeyAddress(t *testing.T) {
+	privs, pk := privsAndMultisig(t, 2, 3)
+	pubKeys := []crypto.PubKey{privs[2].PubKey(), privs[0].PubKey(), privs[1].PubKey()}
+
+	// the address depends on the members and the threshold, not their order
+	same, err := NewMultisigThresholdPubKey(2, pubKeys)
+	require.Nil(t, err)
+	assert.Equal(t, pk.Address(), same.Address())
+	other, err := NewMultisigThresholdPubKey(3, pubKeys)
+	require.Nil(t, err)
+	assert.NotEqual(t, pk.Address(), other.Address())
+	other, err = NewMultisigThresholdPubKey(2, pubKeys[:2])
+	require.Nil(t, err)
+	assert.NotEqual(t, pk.Address(), other.Address())
+
+	// invalid thresholds and duplicate members are refused
+	_, err = NewMultisigThresholdPubKey(0, pubKeys)
+	assert.NotNil(t, err)
+	_, err = NewMultisigThresholdPubKey(4, pubKeys)
+	assert.NotNil(t, err)
+	_, err = NewMultisigThresholdPubKey(2, append(pubKeys, pubKeys[0]))
+	assert.NotNil(t, err)
+}
+
+func TestCompactBitArray(t *testing.T) {
+	ba := NewCompactBitArray(10)
+	assert.Equal(t, 2, len(ba))
+	ba.SetIndex(0, true)
+	ba.SetIndex(9, true)
+	assert.Equal(t, CompactBitArray{0x80, 0x40}, ba)
+	assert.True(t, ba.GetIndex(9))
+	assert.False(t, ba.GetIndex(8))
+	assert.False(t, ba.GetIndex(16))
+	assert.Equal(t, 2, ba.NumTrueBits())
+	ba.SetIndex(0, false)
+	assert.Equal(t, 1, ba.NumTrueBits())
+}
+
+// Test a multisig account signing through the AnteHandler.
+func TestAnteHandlerMultisig(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, false, nil, log.NewNopLogger())
+
+	// a 2-of-3 multisig account
+	privs, pk := privsAndMultisig(t, 2, 3)
+	addr := pk.Address()
+	acc := mapper.NewAccountWithAddress(ctx, addr)
+	acc.SetCoins(newCoins())
+	mapper.SetAccount(ctx, acc)
+
+	msgs := []sdk.Msg{newTestMsg(addr)}
+	fee := newStdFee()
+	newMultisigTx := func(privs []crypto.PrivKey, seq int64) sdk.Tx {
+		signBytes := StdSignBytes(ctx.ChainID(), []int64{0}, []int64{seq}, fee, msgs)
+		sig := StdSignature{PubKey: pk, Signature: multiSign(t, pk, privs, signBytes), AccountNumber: 0, Sequence: seq}
+		return NewStdTx(msgs, fee, []StdSignature{sig})
+	}
+
+	// one signature is not enough
+	checkInvalidTx(t, anteHandler, ctx, newMultisigTx(privs[:1], 0), sdk.CodeUnauthorized)
+
+	// two are, and the multisig key is set on the account
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	checkValidTx(t, anteHandler, ctx, newMultisigTx(privs[1:], 0))
+	assert.True(t, pk.Equals(mapper.GetAccount(ctx, addr).GetPubKey()))
+	assert.True(t, ctx.GasMeter().GasConsumed() >= 2*verifyCost)
+
+	// a single member can't sign for the account
+	tx := newTestTx(ctx, msgs, privs[:1], []int64{0}, []int64{1}, fee)
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeUnauthorized)
+	checkValidTx(t, anteHandler, ctx, newMultisigTx([]crypto.PrivKey{privs[2], privs[0]}, 1))
+}
+
+func TestMultisigMaxKeys(t *testing.T) {
+	pubKeys := make([]crypto.PubKey, MaxMultisigKeys+1)
+	for i := range pubKeys {
+		pubKeys[i] = crypto.GenPrivKeyEd25519().PubKey()
+	}
+	_, err := NewMultisigThresholdPubKey(1, pubKeys[:MaxMultisigKeys])
+	assert.Nil(t, err)
+	_, err = NewMultisigThresholdPubKey(1, pubKeys)
+	assert.NotNil(t, err)
+
+	// nor can multisig keys be nested
+	_, nested := privsAndMultisig(t, 1, 2)
+	_, err = NewMultisigThresholdPubKey(1, []crypto.PubKey{nested, pubKeys[0]})
+	assert.NotNil(t, err)
+
+	// the ante handler rejects bigger keys built without the constructor
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	anteHandler := NewAnteHandler(mapper, NewFeeCollectionKeeper(cdc, capKey2))
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid"}, false, nil, log.NewNopLogger())
+
+	pk := MultisigThresholdPubKey{K: 1, PubKeys: pubKeys}
+	acc := mapper.NewAccountWithAddress(ctx, pk.Address())
+	acc.SetCoins(newCoins())
+	mapper.SetAccount(ctx, acc)
+	msgs := []sdk.Msg{newTestMsg(pk.Address())}
+	fee := newStdFee()
+	sig := StdSignature{PubKey: pk, Signature: NewMultiSignature(len(pubKeys)), AccountNumber: 0, Sequence: 0}
+	checkInvalidTx(t, anteHandler, ctx, NewStdTx(msgs, fee, []StdSignature{sig}), sdk.CodeInvalidPubKey)
+}
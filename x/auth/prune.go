@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"encoding/binary"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Accounts without coins or pubkey, such as the dust left by faucets, are
+// removed once they haven't been written to for the inactive period.
+// Accounts without pubkey have never signed a tx, and a removed account that
+// receives coins again gets a new account number, so the sigs for the
+// removed account can't be replayed.
+
+var (
+	pruningParamsKey   = []byte("pruningParams")
+	inactiveKeyPrefix  = []byte("inactive:")  // (height, address) -> nil, for the accounts which can be pruned
+	lastWriteKeyPrefix = []byte("lastWrite:") // address -> height, for the accounts in the inactive index
+)
+
+// PruningParams are the chain parameters of account pruning: accounts are
+// pruned after InactivePeriod blocks without writes, unless it is zero,
+// and at most MaxPerBlock accounts are pruned per block.
+type PruningParams struct {
+	InactivePeriod int64 `json:"inactive_period"`
+	MaxPerBlock    int64 `json:"max_per_block"`
+}
+
+// GetPruningParams returns the account pruning parameters
+func (am AccountMapper) GetPruningParams(ctx sdk.Context) (params PruningParams) {
+	store := ctx.KVStore(am.key)
+	bz := store.Get(pruningParamsKey)
+	if bz == nil {
+		return
+	}
+	am.cdc.MustUnmarshalBinary(bz, &params)
+	return
+}
+
+// SetPruningParams sets the account pruning parameters
+func (am AccountMapper) SetPruningParams(ctx sdk.Context, params PruningParams) {
+	store := ctx.KVStore(am.key)
+	store.Set(pruningParamsKey, am.cdc.MustMarshalBinary(params))
+}
+
+// IsPrunable returns whether the account is removed once inactive: it has
+// no coins nor pubkey, and isn't a vesting account, whose coins may be
+// delegated.
+func IsPrunable(acc Account) bool {
+	if _, ok := acc.(VestingAccount); ok {
+		return false
+	}
+	return acc.GetPubKey() == nil && acc.GetCoins().IsZero()
+}
+
+func inactiveKey(height int64, addr sdk.Address) []byte {
+	key := make([]byte, len(inactiveKeyPrefix)+8, len(inactiveKeyPrefix)+8+len(addr))
+	copy(key, inactiveKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(inactiveKeyPrefix):], uint64(height))
+	return append(key, addr.Bytes()...)
+}
+
+func lastWriteKey(addr sdk.Address) []byte {
+	return append(append([]byte{}, lastWriteKeyPrefix...), addr.Bytes()...)
+}
+
+// updateInactiveIndex indexes the account written at the block height if
+// it can be pruned, and removes it from the index otherwise. The index isn't
+// maintained while pruning is disabled, and the params are read without
+// charging gas, so that it then costs nothing.
+func (am AccountMapper) updateInactiveIndex(ctx sdk.Context, acc Account) {
+	if am.GetPruningParams(ctx.WithGasMeter(sdk.NewInfiniteGasMeter())).InactivePeriod <= 0 {
+		return
+	}
+	am.removeFromInactiveIndex(ctx, acc.GetAddress())
+	if !IsPrunable(acc) {
+		return
+	}
+	store := ctx.KVStore(am.key)
+	height := ctx.BlockHeight()
+	store.Set(inactiveKey(height, acc.GetAddress()), []byte{})
+	store.Set(lastWriteKey(acc.GetAddress()), am.cdc.MustMarshalBinary(height))
+}
+
+func (am AccountMapper) removeFromInactiveIndex(ctx sdk.Context, addr sdk.Address) {
+	store := ctx.KVStore(am.key)
+	bz := store.Get(lastWriteKey(addr))
+	if bz == nil {
+		return
+	}
+	var height int64
+	am.cdc.MustUnmarshalBinary(bz, &height)
+	store.Delete(inactiveKey(height, addr))
+	store.Delete(lastWriteKey(addr))
+}
+
+// PruneInactiveAccounts removes up to MaxPerBlock prunable accounts which
+// haven't been written to for the inactive period, oldest first, and
+// returns their addresses. Accounts which were written while pruning was
+// disabled may be stale in the index, so they are checked again.
+func (am AccountMapper) PruneInactiveAccounts(ctx sdk.Context) (pruned []sdk.Address) {
+	params := am.GetPruningParams(ctx)
+	cutoff := ctx.BlockHeight() - params.InactivePeriod
+	if params.InactivePeriod <= 0 || params.MaxPerBlock <= 0 || cutoff < 0 {
+		return nil
+	}
+
+	// collect the addresses first, as the store can't be written while iterating
+	var indexed []sdk.Address
+	store := ctx.KVStore(am.key)
+	iter := store.Iterator(inactiveKeyPrefix, inactiveKey(cutoff+1, nil))
+	for ; iter.Valid() && int64(len(indexed)) < params.MaxPerBlock; iter.Next() {
+		indexed = append(indexed, append(sdk.Address(nil), iter.Key()[len(inactiveKeyPrefix)+8:]...))
+	}
+	iter.Close()
+
+	for _, addr := range indexed {
+		if acc := am.GetAccount(ctx, addr); acc != nil && !IsPrunable(acc) {
+			am.removeFromInactiveIndex(ctx, addr)
+			continue
+		}
+		am.RemoveAccount(ctx, addr)
+		pruned = append(pruned, addr)
+	}
+	return pruned
+}
+
+// EndBlocker prunes the inactive accounts, tagging their addresses
+func EndBlocker(ctx sdk.Context, am AccountMapper) (tags sdk.Tags) {
+	for _, addr := range am.PruneInactiveAccounts(ctx) {
+		tags = tags.AppendTag("pruned_account", []byte(addr.String()))
+	}
+	return tags
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tmlibs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+func TestAccountMapperRemoveAccount(t *testing.T) {
+	ms, capKey, _ := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+
+	_, addr := privAndAddr()
+	acc := mapper.NewAccountWithAddress(ctx, addr)
+	mapper.SetAccount(ctx, acc)
+	require.NotNil(t, mapper.GetAccount(ctx, addr))
+
+	mapper.RemoveAccount(ctx, addr)
+	assert.Nil(t, mapper.GetAccount(ctx, addr))
+
+	// the account number isn't reused
+	acc = mapper.NewAccountWithAddress(ctx, addr)
+	assert.Equal(t, int64(1), acc.GetAccountNumber())
+}
+
+func TestPruneInactiveAccounts(t *testing.T) {
+	ms, capKey, _ := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	ctx := sdk.NewContext(ms, abci.Header{Height: 1}, false, nil, log.NewNopLogger())
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	mapper.SetPruningParams(ctx, PruningParams{InactivePeriod: 10, MaxPerBlock: 2})
+	atHeight := func(height int64) sdk.Context {
+		return ctx.WithBlockHeight(height)
+	}
+
+	// empty accounts, one with a pubkey, one with coins and one vesting
+	newAccount := func(ctx sdk.Context) Account {
+		_, addr := privAndAddr()
+		acc := mapper.NewAccountWithAddress(ctx, addr)
+		mapper.SetAccount(ctx, acc)
+		return acc
+	}
+	empty1, empty2, empty3 := newAccount(ctx), newAccount(ctx), newAccount(atHeight(5))
+	withPubKey, withCoins := newAccount(ctx), newAccount(ctx)
+	withPubKey.SetPubKey(crypto.GenPrivKeyEd25519().PubKey())
+	mapper.SetAccount(ctx, withPubKey)
+	withCoins.SetCoins(sdk.Coins{{"atom", 1}})
+	mapper.SetAccount(ctx, withCoins)
+	_, addr := privAndAddr()
+	vesting := NewDelayedVestingAccount(NewBaseAccountWithAddress(addr), sdk.Coins{{"atom", 1}}, 100)
+	mapper.SetAccount(ctx, vesting)
+
+	// nothing is pruned before the inactive period
+	assert.Empty(t, mapper.PruneInactiveAccounts(atHeight(10)))
+
+	// at most MaxPerBlock accounts are pruned per block
+	pruned := mapper.PruneInactiveAccounts(atHeight(11))
+	assert.Len(t, pruned, 2)
+	assert.Nil(t, mapper.GetAccount(ctx, empty1.GetAddress()))
+	assert.Nil(t, mapper.GetAccount(ctx, empty2.GetAddress()))
+
+	// a write postpones the pruning, and accounts with coins aren't pruned
+	empty3.SetCoins(sdk.Coins{{"atom", 1}})
+	mapper.SetAccount(atHeight(12), empty3)
+	empty3.SetCoins(nil)
+	mapper.SetAccount(atHeight(13), empty3)
+	assert.Empty(t, mapper.PruneInactiveAccounts(atHeight(22)))
+	tags := EndBlocker(atHeight(23), mapper)
+	assert.Equal(t, sdk.NewTags("pruned_account", []byte(empty3.GetAddress().String())), tags)
+	assert.Nil(t, mapper.GetAccount(ctx, empty3.GetAddress()))
+
+	for _, acc := range []Account{withPubKey, withCoins, vesting} {
+		assert.NotNil(t, mapper.GetAccount(ctx, acc.GetAddress()))
+	}
+	assert.Empty(t, mapper.PruneInactiveAccounts(atHeight(100)))
+
+	// an account indexed before pruning is disabled
+	stale := newAccount(ctx)
+
+	// pruning is disabled by default, and then accounts aren't indexed
+	mapper.SetPruningParams(ctx, PruningParams{})
+	disabled := newAccount(ctx)
+	store := ctx.KVStore(capKey)
+	assert.Nil(t, store.Get(lastWriteKey(disabled.GetAddress())))
+	assert.Empty(t, mapper.PruneInactiveAccounts(atHeight(100)))
+
+	// and writing an account only costs the gas of the write
+	setCtx := ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	mapper.SetAccount(setCtx, disabled)
+	writeCtx := ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	writeCtx.KVStore(capKey).Set(AddressStoreKey(disabled.GetAddress()), mapper.encodeAccount(disabled))
+	assert.Equal(t, writeCtx.GasMeter().GasConsumed(), setCtx.GasMeter().GasConsumed())
+
+	// accounts written meanwhile aren't pruned once it is enabled again
+	stale.SetCoins(sdk.Coins{{"atom", 1}})
+	mapper.SetAccount(ctx, stale)
+	require.NotNil(t, store.Get(lastWriteKey(stale.GetAddress())))
+	mapper.SetPruningParams(ctx, PruningParams{InactivePeriod: 10, MaxPerBlock: 2})
+	assert.Empty(t, mapper.PruneInactiveAccounts(atHeight(100)))
+	assert.NotNil(t, mapper.GetAccount(ctx, stale.GetAddress()))
+	assert.Nil(t, store.Get(lastWriteKey(stale.GetAddress())))
+}
@@ -9,28 +9,45 @@ import (
 
 var _ sdk.Tx = (*StdTx)(nil)
 
-// StdTx is a standard way to wrap a Msg with Fee and Signatures.
+// StdTx is a standard way to wrap a list of Msgs with Fee and Signatures.
 // NOTE: the first signature is the FeePayer (Signatures must not be nil).
 type StdTx struct {
-	Msg        sdk.Msg        `json:"msg"`
+	Msgs       []sdk.Msg      `json:"msgs"`
 	Fee        StdFee         `json:"fee"`
 	Signatures []StdSignature `json:"signatures"`
 }
 
-func NewStdTx(msg sdk.Msg, fee StdFee, sigs []StdSignature) StdTx {
+func NewStdTx(msgs []sdk.Msg, fee StdFee, sigs []StdSignature) StdTx {
 	return StdTx{
-		Msg:        msg,
+		Msgs:       msgs,
 		Fee:        fee,
 		Signatures: sigs,
 	}
 }
 
 //nolint
-func (tx StdTx) GetMsg() sdk.Msg { return tx.Msg }
+func (tx StdTx) GetMsgs() []sdk.Msg { return tx.Msgs }
+
+// GetSigners returns the addresses that must sign the transaction.
+// Addresses are returned in a deterministic order: in the order they
+// first appear across the Msgs, with duplicates removed.
+func (tx StdTx) GetSigners() []sdk.Address {
+	seen := map[string]bool{}
+	var signers []sdk.Address
+	for _, msg := range tx.GetMsgs() {
+		for _, addr := range msg.GetSigners() {
+			if !seen[string(addr)] {
+				signers = append(signers, addr)
+				seen[string(addr)] = true
+			}
+		}
+	}
+	return signers
+}
 
-// Signatures returns the signature of signers who signed the Msg.
+// Signatures returns the signature of signers who signed the Msgs.
 // CONTRACT: Length returned is same as length of
-// pubkeys returned from MsgKeySigners, and the order
+// addresses returned from GetSigners, and the order
 // matches.
 // CONTRACT: If the signature is missing (ie the Msg is
 // invalid), then the corresponding signature is
@@ -38,10 +55,15 @@ func (tx StdTx) GetMsg() sdk.Msg { return tx.Msg }
 func (tx StdTx) GetSignatures() []StdSignature { return tx.Signatures }
 
 // FeePayer returns the address responsible for paying the fees
-// for the transactions. It's the first address returned by msg.GetSigners().
+// for the transactions. It's the Payer of the fee if set, who must have
+// granted a fee allowance to the first signer, and otherwise the first
+// address returned by tx.GetSigners().
 // If GetSigners() is empty, this panics.
-func FeePayer(tx sdk.Tx) sdk.Address {
-	return tx.GetMsg().GetSigners()[0]
+func FeePayer(tx StdTx) sdk.Address {
+	if len(tx.Fee.Payer) != 0 {
+		return tx.Fee.Payer
+	}
+	return tx.GetSigners()[0]
 }
 
 //__________________________________________________________
@@ -49,9 +71,12 @@ func FeePayer(tx sdk.Tx) sdk.Address {
 // StdFee includes the amount of coins paid in fees and the maximum
 // gas to be used by the transaction. The ratio yields an effective "gasprice",
 // which must be above some miminum to be accepted into the mempool.
+// The fees are paid by the Payer if set, and otherwise by the first signer.
+// As part of the fee, the Payer is signed.
 type StdFee struct {
-	Amount sdk.Coins `json:"amount"`
-	Gas    int64     `json:"gas"`
+	Amount sdk.Coins   `json:"amount"`
+	Gas    int64       `json:"gas"`
+	Payer  sdk.Address `json:"payer,omitempty"`
 }
 
 func NewStdFee(gas int64, amount ...sdk.Coin) StdFee {
@@ -80,28 +105,32 @@ func (fee StdFee) Bytes() []byte {
 //__________________________________________________________
 
 // StdSignDoc is replay-prevention structure.
-// It includes the result of msg.GetSignBytes(),
+// It includes the result of msg.GetSignBytes() for each msg,
 // as well as the ChainID (prevent cross chain replay)
 // and the Sequence numbers for each signature (prevent
 // inchain replay and enforce tx ordering per account).
 type StdSignDoc struct {
-	ChainID        string  `json:"chain_id"`
-	AccountNumbers []int64 `json:"account_numbers"`
-	Sequences      []int64 `json:"sequences"`
-	FeeBytes       []byte  `json:"fee_bytes"`
-	MsgBytes       []byte  `json:"msg_bytes"`
-	AltBytes       []byte  `json:"alt_bytes"`
+	ChainID        string            `json:"chain_id"`
+	AccountNumbers []int64           `json:"account_numbers"`
+	Sequences      []int64           `json:"sequences"`
+	FeeBytes       []byte            `json:"fee_bytes"`
+	Msgs           []json.RawMessage `json:"msgs"`
+	AltBytes       []byte            `json:"alt_bytes"`
 }
 
 // StdSignBytes returns the bytes to sign for a transaction.
 // TODO: change the API to just take a chainID and StdTx ?
-func StdSignBytes(chainID string, accnums []int64, sequences []int64, fee StdFee, msg sdk.Msg) []byte {
+func StdSignBytes(chainID string, accnums []int64, sequences []int64, fee StdFee, msgs []sdk.Msg) []byte {
+	msgsBytes := make([]json.RawMessage, len(msgs))
+	for i, msg := range msgs {
+		msgsBytes[i] = json.RawMessage(msg.GetSignBytes())
+	}
 	bz, err := json.Marshal(StdSignDoc{
 		ChainID:        chainID,
 		AccountNumbers: accnums,
 		Sequences:      sequences,
 		FeeBytes:       fee.Bytes(),
-		MsgBytes:       msg.GetSignBytes(),
+		Msgs:           msgsBytes,
 	})
 	if err != nil {
 		panic(err)
@@ -110,20 +139,20 @@ func StdSignBytes(chainID string, accnums []int64, sequences []int64, fee StdFee
 }
 
 // StdSignMsg is a convenience structure for passing along
-// a Msg with the other requirements for a StdSignDoc before
+// a list of Msgs with the other requirements for a StdSignDoc before
 // it is signed. For use in the CLI.
 type StdSignMsg struct {
-	ChainID        string
-	AccountNumbers []int64
-	Sequences      []int64
-	Fee            StdFee
-	Msg            sdk.Msg
+	ChainID        string    `json:"chain_id"`
+	AccountNumbers []int64   `json:"account_numbers"`
+	Sequences      []int64   `json:"sequences"`
+	Fee            StdFee    `json:"fee"`
+	Msgs           []sdk.Msg `json:"msgs"`
 	// XXX: Alt
 }
 
 // get message bytes
 func (msg StdSignMsg) Bytes() []byte {
-	return StdSignBytes(msg.ChainID, msg.AccountNumbers, msg.Sequences, msg.Fee, msg.Msg)
+	return StdSignBytes(msg.ChainID, msg.AccountNumbers, msg.Sequences, msg.Fee, msg.Msgs)
 }
 
 // Standard Signature
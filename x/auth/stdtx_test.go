@@ -19,14 +19,31 @@ import (
 func TestStdTx(t *testing.T) {
 	priv := crypto.GenPrivKeyEd25519()
 	addr := priv.PubKey().Address()
-	msg := sdk.NewTestMsg(addr)
+	msgs := []sdk.Msg{sdk.NewTestMsg(addr)}
 	fee := newStdFee()
 	sigs := []StdSignature{}
 
-	tx := NewStdTx(msg, fee, sigs)
-	assert.Equal(t, msg, tx.GetMsg())
+	tx := NewStdTx(msgs, fee, sigs)
+	assert.Equal(t, msgs, tx.GetMsgs())
 	assert.Equal(t, sigs, tx.GetSignatures())
 
 	feePayer := FeePayer(tx)
 	assert.Equal(t, addr, feePayer)
 }
+
+func TestStdTxGetSigners(t *testing.T) {
+	addr1 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+
+	msgs := []sdk.Msg{
+		sdk.NewTestMsg(addr1, addr2),
+		sdk.NewTestMsg(addr3, addr1),
+		sdk.NewTestMsg(addr2),
+	}
+	tx := NewStdTx(msgs, newStdFee(), nil)
+
+	// signers are deduplicated and kept in order of first appearance
+	assert.Equal(t, []sdk.Address{addr1, addr2, addr3}, tx.GetSigners())
+	assert.Equal(t, addr1, FeePayer(tx))
+}
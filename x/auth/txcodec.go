@@ -0,0 +1,442 @@
+package auth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	crypto "github.com/tendermint/go-crypto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// DefaultTxCodecRegistry returns a registry accepting StdTxs in amino binary,
+// canonical JSON and the protobuf wire format, as well as unprefixed
+// amino binary from legacy clients.
+// The concrete Msg types must be registered on cdc.
+func DefaultTxCodecRegistry(cdc *wire.Codec) *sdk.TxCodecRegistry {
+	return sdk.NewTxCodecRegistry().
+		Register(sdk.TxVersionAmino, AminoTxEncoder(cdc), AminoTxDecoder(cdc)).
+		Register(sdk.TxVersionJSON, JSONTxEncoder(cdc), JSONTxDecoder(cdc)).
+		Register(sdk.TxVersionProto, ProtoTxEncoder(cdc), ProtoTxDecoder(cdc)).
+		SetLegacyDecoder(AminoTxDecoder(cdc))
+}
+
+// AminoTxEncoder encodes a StdTx as amino binary
+func AminoTxEncoder(cdc *wire.Codec) sdk.TxEncoder {
+	return func(tx sdk.Tx) ([]byte, error) {
+		return cdc.MarshalBinary(tx)
+	}
+}
+
+// AminoTxDecoder decodes a StdTx from amino binary
+func AminoTxDecoder(cdc *wire.Codec) sdk.TxDecoder {
+	return func(txBytes []byte) (sdk.Tx, sdk.Error) {
+		var tx = StdTx{}
+
+		if len(txBytes) == 0 {
+			return nil, sdk.ErrTxDecode("txBytes are empty")
+		}
+
+		// StdTx.Msgs is a list of interfaces. The concrete types
+		// are registered by MakeTxCodec
+		err := cdc.UnmarshalBinary(txBytes, &tx)
+		if err != nil {
+			return nil, sdk.ErrTxDecode("").Trace(err.Error())
+		}
+		return tx, nil
+	}
+}
+
+// JSONTxEncoder encodes a StdTx as amino JSON, where every interface
+// value is wrapped as {"type": ..., "value": ...}
+func JSONTxEncoder(cdc *wire.Codec) sdk.TxEncoder {
+	return func(tx sdk.Tx) ([]byte, error) {
+		return cdc.MarshalJSON(tx)
+	}
+}
+
+// JSONTxDecoder decodes a StdTx from amino JSON
+func JSONTxDecoder(cdc *wire.Codec) sdk.TxDecoder {
+	return func(txBytes []byte) (sdk.Tx, sdk.Error) {
+		var tx = StdTx{}
+
+		if len(txBytes) == 0 {
+			return nil, sdk.ErrTxDecode("txBytes are empty")
+		}
+
+		err := cdc.UnmarshalJSON(txBytes, &tx)
+		if err != nil {
+			return nil, sdk.ErrTxDecode("").Trace(err.Error())
+		}
+		return tx, nil
+	}
+}
+
+//__________________________________________________________
+
+// The protobuf encoding of a StdTx follows this schema, where Msgs, PubKeys
+// and Signatures are embedded as their amino JSON so that clients only need
+// a protobuf library and a JSON encoder:
+//
+//   message StdTx {
+//     repeated bytes msgs = 1;
+//     StdFee fee = 2;
+//     repeated StdSignature signatures = 3;
+//   }
+//   message StdFee {
+//     repeated Coin amount = 1;
+//     int64 gas = 2;
+//     bytes payer = 3;
+//   }
+//   message Coin {
+//     string denom = 1;
+//     int64 amount = 2;
+//   }
+//   message StdSignature {
+//     bytes pub_key = 1;
+//     bytes signature = 2;
+//     int64 account_number = 3;
+//     int64 sequence = 4;
+//   }
+
+// ProtoTxEncoder encodes a StdTx in the protobuf wire format
+func ProtoTxEncoder(cdc *wire.Codec) sdk.TxEncoder {
+	return func(tx sdk.Tx) ([]byte, error) {
+		stdTx, ok := tx.(StdTx)
+		if !ok {
+			return nil, fmt.Errorf("expected StdTx, got %T", tx)
+		}
+
+		var w protoWriter
+		for _, msg := range stdTx.Msgs {
+			bz, err := cdc.MarshalJSON(msg)
+			if err != nil {
+				return nil, err
+			}
+			w.writeBytes(1, bz)
+		}
+		w.writeBytes(2, encodeProtoFee(stdTx.Fee))
+		for _, sig := range stdTx.Signatures {
+			bz, err := encodeProtoSignature(cdc, sig)
+			if err != nil {
+				return nil, err
+			}
+			w.writeBytes(3, bz)
+		}
+		return w.buf, nil
+	}
+}
+
+// ProtoTxDecoder decodes a StdTx from the protobuf wire format
+func ProtoTxDecoder(cdc *wire.Codec) sdk.TxDecoder {
+	return func(txBytes []byte) (sdk.Tx, sdk.Error) {
+		if len(txBytes) == 0 {
+			return nil, sdk.ErrTxDecode("txBytes are empty")
+		}
+
+		tx, err := decodeProtoStdTx(cdc, txBytes)
+		if err != nil {
+			return nil, sdk.ErrTxDecode("").Trace(err.Error())
+		}
+		return tx, nil
+	}
+}
+
+func decodeProtoStdTx(cdc *wire.Codec, bz []byte) (tx StdTx, err error) {
+	r := protoReader{buf: bz}
+	for !r.done() {
+		field, wireType, err := r.readKey()
+		if err != nil {
+			return tx, err
+		}
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return tx, err
+			}
+			var msg sdk.Msg
+			if err := cdc.UnmarshalJSON(raw, &msg); err != nil {
+				return tx, err
+			}
+			tx.Msgs = append(tx.Msgs, msg)
+		case field == 2 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return tx, err
+			}
+			if tx.Fee, err = decodeProtoFee(raw); err != nil {
+				return tx, err
+			}
+		case field == 3 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return tx, err
+			}
+			sig, err := decodeProtoSignature(cdc, raw)
+			if err != nil {
+				return tx, err
+			}
+			tx.Signatures = append(tx.Signatures, sig)
+		default:
+			if err := r.skip(wireType); err != nil {
+				return tx, err
+			}
+		}
+	}
+	return tx, nil
+}
+
+func encodeProtoFee(fee StdFee) []byte {
+	var w protoWriter
+	for _, coin := range fee.Amount {
+		var cw protoWriter
+		cw.writeBytes(1, []byte(coin.Denom))
+		cw.writeVarint(2, coin.Amount)
+		w.writeBytes(1, cw.buf)
+	}
+	w.writeVarint(2, fee.Gas)
+	w.writeBytes(3, fee.Payer)
+	return w.buf
+}
+
+func decodeProtoFee(bz []byte) (fee StdFee, err error) {
+	r := protoReader{buf: bz}
+	for !r.done() {
+		field, wireType, err := r.readKey()
+		if err != nil {
+			return fee, err
+		}
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return fee, err
+			}
+			coin, err := decodeProtoCoin(raw)
+			if err != nil {
+				return fee, err
+			}
+			fee.Amount = append(fee.Amount, coin)
+		case field == 2 && wireType == protoWireVarint:
+			if fee.Gas, err = r.readVarint(); err != nil {
+				return fee, err
+			}
+		case field == 3 && wireType == protoWireBytes:
+			payer, err := r.readBytes()
+			if err != nil {
+				return fee, err
+			}
+			fee.Payer = append(sdk.Address(nil), payer...)
+		default:
+			if err := r.skip(wireType); err != nil {
+				return fee, err
+			}
+		}
+	}
+	return fee, nil
+}
+
+func decodeProtoCoin(bz []byte) (coin sdk.Coin, err error) {
+	r := protoReader{buf: bz}
+	for !r.done() {
+		field, wireType, err := r.readKey()
+		if err != nil {
+			return coin, err
+		}
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return coin, err
+			}
+			coin.Denom = string(raw)
+		case field == 2 && wireType == protoWireVarint:
+			if coin.Amount, err = r.readVarint(); err != nil {
+				return coin, err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return coin, err
+			}
+		}
+	}
+	return coin, nil
+}
+
+func encodeProtoSignature(cdc *wire.Codec, sig StdSignature) ([]byte, error) {
+	var w protoWriter
+	if sig.PubKey != nil {
+		bz, err := cdc.MarshalJSON(sig.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		w.writeBytes(1, bz)
+	}
+	if sig.Signature != nil {
+		bz, err := cdc.MarshalJSON(sig.Signature)
+		if err != nil {
+			return nil, err
+		}
+		w.writeBytes(2, bz)
+	}
+	w.writeVarint(3, sig.AccountNumber)
+	w.writeVarint(4, sig.Sequence)
+	return w.buf, nil
+}
+
+func decodeProtoSignature(cdc *wire.Codec, bz []byte) (sig StdSignature, err error) {
+	r := protoReader{buf: bz}
+	for !r.done() {
+		field, wireType, err := r.readKey()
+		if err != nil {
+			return sig, err
+		}
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return sig, err
+			}
+			var pubKey crypto.PubKey
+			if err := cdc.UnmarshalJSON(raw, &pubKey); err != nil {
+				return sig, err
+			}
+			sig.PubKey = pubKey
+		case field == 2 && wireType == protoWireBytes:
+			raw, err := r.readBytes()
+			if err != nil {
+				return sig, err
+			}
+			var signature crypto.Signature
+			if err := cdc.UnmarshalJSON(raw, &signature); err != nil {
+				return sig, err
+			}
+			sig.Signature = signature
+		case field == 3 && wireType == protoWireVarint:
+			if sig.AccountNumber, err = r.readVarint(); err != nil {
+				return sig, err
+			}
+		case field == 4 && wireType == protoWireVarint:
+			if sig.Sequence, err = r.readVarint(); err != nil {
+				return sig, err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return sig, err
+			}
+		}
+	}
+	return sig, nil
+}
+
+//__________________________________________________________
+
+// protobuf wire types
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("protobuf message is truncated")
+
+// protoWriter appends protobuf encoded fields to buf.
+// As in proto3, fields with zero values are omitted.
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) writeUvarint(v uint64) {
+	var bz [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(bz[:], v)
+	w.buf = append(w.buf, bz[:n]...)
+}
+
+func (w *protoWriter) writeVarint(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	w.writeUvarint(uint64(field)<<3 | protoWireVarint)
+	w.writeUvarint(uint64(v))
+}
+
+func (w *protoWriter) writeBytes(field int, bz []byte) {
+	if len(bz) == 0 {
+		return
+	}
+	w.writeUvarint(uint64(field)<<3 | protoWireBytes)
+	w.writeUvarint(uint64(len(bz)))
+	w.buf = append(w.buf, bz...)
+}
+
+// protoReader reads protobuf encoded fields from buf
+type protoReader struct {
+	buf []byte
+}
+
+func (r *protoReader) done() bool {
+	return len(r.buf) == 0
+}
+
+func (r *protoReader) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		return 0, errProtoTruncated
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *protoReader) readKey() (field int, wireType int, err error) {
+	key, err := r.readUvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(key >> 3), int(key & 7), nil
+}
+
+func (r *protoReader) readVarint() (int64, error) {
+	v, err := r.readUvarint()
+	return int64(v), err
+}
+
+func (r *protoReader) readBytes() ([]byte, error) {
+	l, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.buf)) < l {
+		return nil, errProtoTruncated
+	}
+	bz := r.buf[:l]
+	r.buf = r.buf[l:]
+	return bz, nil
+}
+
+func (r *protoReader) skipN(n int) error {
+	if len(r.buf) < n {
+		return errProtoTruncated
+	}
+	r.buf = r.buf[n:]
+	return nil
+}
+
+func (r *protoReader) skip(wireType int) (err error) {
+	switch wireType {
+	case protoWireVarint:
+		_, err = r.readUvarint()
+	case protoWireFixed64:
+		err = r.skipN(8)
+	case protoWireBytes:
+		_, err = r.readBytes()
+	case protoWireFixed32:
+		err = r.skipN(4)
+	default:
+		err = fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+	return err
+}
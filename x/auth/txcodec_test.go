@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+func TestDefaultTxCodecRegistry(t *testing.T) {
+	cdc := wire.NewCodec()
+	RegisterWire(cdc)
+	wire.RegisterCrypto(cdc)
+
+	priv1, addr1 := privAndAddr()
+	priv2, addr2 := privAndAddr()
+	msgs := []sdk.Msg{
+		NewMsgChangeKey(addr1, priv2.PubKey()),
+		NewMsgChangeKey(addr2, priv1.PubKey()),
+	}
+	fee := newStdFee()
+	signBytes := StdSignBytes("mychainid", []int64{0, 1}, []int64{3, 0}, fee, msgs)
+	tx := NewStdTx(msgs, fee, []StdSignature{
+		{PubKey: priv1.PubKey(), Signature: priv1.Sign(signBytes), AccountNumber: 0, Sequence: 3},
+		{Signature: priv2.Sign(signBytes), AccountNumber: 1, Sequence: 0},
+	})
+
+	registry := DefaultTxCodecRegistry(cdc)
+	decode := registry.TxDecoder()
+
+	// every encoding round trips
+	for _, version := range []byte{sdk.TxVersionAmino, sdk.TxVersionJSON, sdk.TxVersionProto} {
+		bz, err := registry.Encode(version, tx)
+		require.Nil(t, err, "version %d", version)
+		require.Equal(t, version, bz[0])
+
+		decoded, sdkErr := decode(bz)
+		require.Nil(t, sdkErr, "version %d", version)
+		assert.Equal(t, tx, decoded, "version %d", version)
+	}
+
+	// unprefixed amino from legacy clients is still accepted
+	bz, err := cdc.MarshalBinary(tx)
+	require.Nil(t, err)
+	decoded, sdkErr := decode(bz)
+	require.Nil(t, sdkErr)
+	assert.Equal(t, tx, decoded)
+
+	// corrupt encodings fail to decode
+	for _, version := range []byte{sdk.TxVersionJSON, sdk.TxVersionProto} {
+		bz, err := registry.Encode(version, tx)
+		require.Nil(t, err)
+		_, sdkErr := decode(bz[:len(bz)-1])
+		require.NotNil(t, sdkErr, "version %d", version)
+		assert.Equal(t, sdk.CodeTxDecode, sdkErr.Code())
+	}
+}
+
+func TestProtoTxDecoderSkipsUnknownFields(t *testing.T) {
+	cdc := wire.NewCodec()
+	RegisterWire(cdc)
+	wire.RegisterCrypto(cdc)
+
+	_, addr := privAndAddr()
+	tx := NewStdTx([]sdk.Msg{NewMsgChangeKey(addr, nil)}, newStdFee(), nil)
+
+	bz, err := ProtoTxEncoder(cdc)(tx)
+	require.Nil(t, err)
+
+	// append fields from a newer schema
+	var w protoWriter
+	w.writeVarint(15, 42)
+	w.writeBytes(16, []byte("future"))
+	bz = append(bz, w.buf...)
+
+	decoded, sdkErr := ProtoTxDecoder(cdc)(bz)
+	require.Nil(t, sdkErr)
+	assert.Equal(t, tx, decoded)
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// VestingAccount is an Account whose original vesting coins are locked
+// until they vest on a schedule. Locked coins can't be spent, but they can
+// be delegated: the delegations are tracked so that the coins which return
+// from them are locked as long as they are still vesting.
+type VestingAccount interface {
+	Account
+
+	GetOriginalVesting() sdk.Coins
+	GetDelegatedFree() sdk.Coins
+	GetDelegatedVesting() sdk.Coins
+	GetEndTime() int64
+
+	// the coins which have vested, and are still vesting, at the block time
+	GetVestedCoins(blockTime int64) sdk.Coins
+	GetVestingCoins(blockTime int64) sdk.Coins
+
+	// the coins of the account which aren't locked at the block time
+	SpendableCoins(blockTime int64) sdk.Coins
+
+	// record the delegation of coins, which have left the account, and
+	// their undelegation, before they return to it
+	TrackDelegation(blockTime int64, amt sdk.Coins)
+	TrackUndelegation(amt sdk.Coins)
+}
+
+// SpendableCoins returns the coins of an account which aren't locked at the
+// block time
+func SpendableCoins(acc Account, blockTime int64) sdk.Coins {
+	if vacc, ok := acc.(VestingAccount); ok {
+		return vacc.SpendableCoins(blockTime)
+	}
+	return acc.GetCoins()
+}
+
+//-----------------------------------------------------------
+// BaseVestingAccount
+
+// BaseVestingAccount holds the state common to the vesting accounts.
+// DelegatedFree and DelegatedVesting are the delegated coins which were
+// vested and still vesting when delegated.
+type BaseVestingAccount struct {
+	BaseAccount
+
+	OriginalVesting  sdk.Coins `json:"original_vesting"`
+	DelegatedFree    sdk.Coins `json:"delegated_free"`
+	DelegatedVesting sdk.Coins `json:"delegated_vesting"`
+	EndTime          int64     `json:"end_time"`
+}
+
+// Implements VestingAccount.
+func (bva BaseVestingAccount) GetOriginalVesting() sdk.Coins {
+	return bva.OriginalVesting
+}
+
+// Implements VestingAccount.
+func (bva BaseVestingAccount) GetDelegatedFree() sdk.Coins {
+	return bva.DelegatedFree
+}
+
+// Implements VestingAccount.
+func (bva BaseVestingAccount) GetDelegatedVesting() sdk.Coins {
+	return bva.DelegatedVesting
+}
+
+// Implements VestingAccount.
+func (bva BaseVestingAccount) GetEndTime() int64 {
+	return bva.EndTime
+}
+
+// The coins vesting which aren't delegated are locked.
+func (bva BaseVestingAccount) spendableCoins(vestingCoins sdk.Coins) sdk.Coins {
+	var spendable sdk.Coins
+	for _, coin := range bva.Coins {
+		locked := vestingCoins.AmountOf(coin.Denom) - bva.DelegatedVesting.AmountOf(coin.Denom)
+		if locked < 0 {
+			locked = 0
+		}
+		if amount := coin.Amount - locked; amount > 0 {
+			spendable = append(spendable, sdk.Coin{Denom: coin.Denom, Amount: amount})
+		}
+	}
+	return spendable
+}
+
+// Delegated coins are vesting up to the coins vesting not yet delegated.
+func (bva *BaseVestingAccount) trackDelegation(vestingCoins, amt sdk.Coins) {
+	for _, coin := range amt {
+		vesting := vestingCoins.AmountOf(coin.Denom) - bva.DelegatedVesting.AmountOf(coin.Denom)
+		if vesting < 0 {
+			vesting = 0
+		}
+		if vesting > coin.Amount {
+			vesting = coin.Amount
+		}
+		bva.DelegatedVesting = addAmount(bva.DelegatedVesting, coin.Denom, vesting)
+		bva.DelegatedFree = addAmount(bva.DelegatedFree, coin.Denom, coin.Amount-vesting)
+	}
+}
+
+// Implements VestingAccount. Undelegated coins are free first, so that
+// the coins returning from delegations stay locked as long as possible.
+func (bva *BaseVestingAccount) TrackUndelegation(amt sdk.Coins) {
+	for _, coin := range amt {
+		free := bva.DelegatedFree.AmountOf(coin.Denom)
+		if free > coin.Amount {
+			free = coin.Amount
+		}
+		vesting := bva.DelegatedVesting.AmountOf(coin.Denom)
+		if vesting > coin.Amount-free {
+			vesting = coin.Amount - free
+		}
+		bva.DelegatedFree = addAmount(bva.DelegatedFree, coin.Denom, -free)
+		bva.DelegatedVesting = addAmount(bva.DelegatedVesting, coin.Denom, -vesting)
+	}
+}
+
+func addAmount(coins sdk.Coins, denom string, amount int64) sdk.Coins {
+	if amount == 0 {
+		return coins
+	}
+	return coins.Plus(sdk.Coins{{Denom: denom, Amount: amount}})
+}
+
+//-----------------------------------------------------------
+// ContinuousVestingAccount
+
+var _ VestingAccount = (*ContinuousVestingAccount)(nil)
+
+// ContinuousVestingAccount vests its coins linearly from StartTime until
+// EndTime.
+type ContinuousVestingAccount struct {
+	BaseVestingAccount
+
+	StartTime int64 `json:"start_time"`
+}
+
+// NewContinuousVestingAccount returns an account whose original vesting
+// coins vest linearly between the start and end times, in seconds
+func NewContinuousVestingAccount(acc BaseAccount, originalVesting sdk.Coins, startTime, endTime int64) *ContinuousVestingAccount {
+	return &ContinuousVestingAccount{
+		BaseVestingAccount: BaseVestingAccount{
+			BaseAccount:     acc,
+			OriginalVesting: originalVesting,
+			EndTime:         endTime,
+		},
+		StartTime: startTime,
+	}
+}
+
+// Implements VestingAccount.
+func (cva ContinuousVestingAccount) GetVestedCoins(blockTime int64) sdk.Coins {
+	if blockTime <= cva.StartTime {
+		return nil
+	}
+	if blockTime >= cva.EndTime {
+		return cva.OriginalVesting
+	}
+
+	// amount * elapsed / duration could overflow an int64
+	elapsed := big.NewInt(blockTime - cva.StartTime)
+	duration := big.NewInt(cva.EndTime - cva.StartTime)
+	var vested sdk.Coins
+	for _, coin := range cva.OriginalVesting {
+		amount := new(big.Int).Mul(big.NewInt(coin.Amount), elapsed)
+		amount.Quo(amount, duration)
+		if amount.Sign() > 0 {
+			vested = append(vested, sdk.Coin{Denom: coin.Denom, Amount: amount.Int64()})
+		}
+	}
+	return vested
+}
+
+// Implements VestingAccount.
+func (cva ContinuousVestingAccount) GetVestingCoins(blockTime int64) sdk.Coins {
+	return cva.OriginalVesting.Minus(cva.GetVestedCoins(blockTime))
+}
+
+// Implements VestingAccount.
+func (cva ContinuousVestingAccount) SpendableCoins(blockTime int64) sdk.Coins {
+	return cva.spendableCoins(cva.GetVestingCoins(blockTime))
+}
+
+// Implements VestingAccount.
+func (cva *ContinuousVestingAccount) TrackDelegation(blockTime int64, amt sdk.Coins) {
+	cva.trackDelegation(cva.GetVestingCoins(blockTime), amt)
+}
+
+// GetStartTime returns the time at which the coins start vesting
+func (cva ContinuousVestingAccount) GetStartTime() int64 {
+	return cva.StartTime
+}
+
+//-----------------------------------------------------------
+// DelayedVestingAccount
+
+var _ VestingAccount = (*DelayedVestingAccount)(nil)
+
+// DelayedVestingAccount vests all its coins at EndTime.
+type DelayedVestingAccount struct {
+	BaseVestingAccount
+}
+
+// NewDelayedVestingAccount returns an account whose original vesting coins
+// all vest at the end time, in seconds
+func NewDelayedVestingAccount(acc BaseAccount, originalVesting sdk.Coins, endTime int64) *DelayedVestingAccount {
+	return &DelayedVestingAccount{
+		BaseVestingAccount: BaseVestingAccount{
+			BaseAccount:     acc,
+			OriginalVesting: originalVesting,
+			EndTime:         endTime,
+		},
+	}
+}
+
+// Implements VestingAccount.
+func (dva DelayedVestingAccount) GetVestedCoins(blockTime int64) sdk.Coins {
+	if blockTime >= dva.EndTime {
+		return dva.OriginalVesting
+	}
+	return nil
+}
+
+// Implements VestingAccount.
+func (dva DelayedVestingAccount) GetVestingCoins(blockTime int64) sdk.Coins {
+	return dva.OriginalVesting.Minus(dva.GetVestedCoins(blockTime))
+}
+
+// Implements VestingAccount.
+func (dva DelayedVestingAccount) SpendableCoins(blockTime int64) sdk.Coins {
+	return dva.spendableCoins(dva.GetVestingCoins(blockTime))
+}
+
+// Implements VestingAccount.
+func (dva *DelayedVestingAccount) TrackDelegation(blockTime int64, amt sdk.Coins) {
+	dva.trackDelegation(dva.GetVestingCoins(blockTime), amt)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tmlibs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+)
+
+func newVestingBaseAccount(coins sdk.Coins) BaseAccount {
+	acc := NewBaseAccountWithAddress(sdk.Address(crypto.GenPrivKeyEd25519().PubKey().Address()))
+	acc.Coins = coins
+	return acc
+}
+
+func TestContinuousVestingAccount(t *testing.T) {
+	coins := sdk.Coins{{"fee", 1000}, {"steak", 100}}
+	cva := NewContinuousVestingAccount(newVestingBaseAccount(coins), coins, 1000, 2000)
+
+	// nothing vests before the start time, everything after the end time
+	assert.Nil(t, cva.GetVestedCoins(500))
+	assert.Equal(t, coins, cva.GetVestingCoins(1000))
+	assert.Nil(t, cva.SpendableCoins(1000))
+	assert.Equal(t, coins, cva.GetVestedCoins(2000))
+	assert.Nil(t, cva.GetVestingCoins(3000))
+	assert.Equal(t, coins, cva.SpendableCoins(3000))
+
+	// in between the coins vest linearly
+	assert.Equal(t, sdk.Coins{{"fee", 250}, {"steak", 25}}, cva.GetVestedCoins(1250))
+	assert.Equal(t, sdk.Coins{{"fee", 750}, {"steak", 75}}, cva.GetVestingCoins(1250))
+	assert.Equal(t, sdk.Coins{{"fee", 250}, {"steak", 25}}, cva.SpendableCoins(1250))
+
+	// received coins are spendable
+	cva.SetCoins(coins.Plus(sdk.Coins{{"steak", 50}}))
+	assert.Equal(t, sdk.Coins{{"fee", 250}, {"steak", 75}}, cva.SpendableCoins(1250))
+
+	// large amounts don't overflow
+	large := sdk.Coins{{"steak", 1 << 60}}
+	cva = NewContinuousVestingAccount(newVestingBaseAccount(large), large, 0, 1<<20)
+	assert.Equal(t, sdk.Coins{{"steak", 1 << 59}}, cva.GetVestedCoins(1<<19))
+}
+
+func TestDelayedVestingAccount(t *testing.T) {
+	coins := sdk.Coins{{"steak", 100}}
+	dva := NewDelayedVestingAccount(newVestingBaseAccount(coins.Plus(sdk.Coins{{"fee", 10}})), coins, 1000)
+
+	// the coins all vest at the end time
+	assert.Nil(t, dva.GetVestedCoins(999))
+	assert.Equal(t, sdk.Coins{{"fee", 10}}, dva.SpendableCoins(999))
+	assert.Equal(t, coins, dva.GetVestedCoins(1000))
+	assert.Equal(t, sdk.Coins{{"fee", 10}, {"steak", 100}}, dva.SpendableCoins(1000))
+}
+
+func TestVestingAccountTrackDelegation(t *testing.T) {
+	coins := sdk.Coins{{"steak", 100}}
+	cva := NewContinuousVestingAccount(newVestingBaseAccount(coins), coins, 0, 100)
+
+	// delegate 60 at time 50, when 50 are vesting: 50 are vesting, 10 free
+	cva.TrackDelegation(50, sdk.Coins{{"steak", 60}})
+	cva.SetCoins(sdk.Coins{{"steak", 40}})
+	assert.Equal(t, sdk.Coins{{"steak", 50}}, cva.GetDelegatedVesting())
+	assert.Equal(t, sdk.Coins{{"steak", 10}}, cva.GetDelegatedFree())
+	assert.Equal(t, sdk.Coins{{"steak", 40}}, cva.SpendableCoins(50))
+
+	// free coins return first, and the vesting ones are locked until they vest
+	cva.TrackUndelegation(sdk.Coins{{"steak", 30}})
+	cva.SetCoins(sdk.Coins{{"steak", 70}})
+	assert.Nil(t, cva.GetDelegatedFree())
+	assert.Equal(t, sdk.Coins{{"steak", 30}}, cva.GetDelegatedVesting())
+	assert.Equal(t, sdk.Coins{{"steak", 50}}, cva.SpendableCoins(50))
+	assert.Equal(t, sdk.Coins{{"steak", 70}}, cva.SpendableCoins(80))
+
+	// a slashed delegation returns less than was delegated
+	cva.TrackUndelegation(sdk.Coins{{"steak", 20}})
+	cva.SetCoins(sdk.Coins{{"steak", 90}})
+	assert.Equal(t, sdk.Coins{{"steak", 10}}, cva.GetDelegatedVesting())
+	assert.Equal(t, sdk.Coins{{"steak", 50}}, cva.SpendableCoins(50))
+}
+
+func TestVestingAccountMapper(t *testing.T) {
+	ms, capKey, _ := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+
+	// vesting accounts are stored and loaded with their schedule
+	coins := sdk.Coins{{"steak", 100}}
+	cva := NewContinuousVestingAccount(newVestingBaseAccount(coins), coins, 10, 20)
+	dva := NewDelayedVestingAccount(newVestingBaseAccount(coins), coins, 30)
+	mapper.SetAccount(ctx, cva)
+	mapper.SetAccount(ctx, dva)
+	acc := mapper.GetAccount(ctx, cva.GetAddress())
+	require.IsType(t, &ContinuousVestingAccount{}, acc)
+	assert.Equal(t, cva, acc)
+	acc = mapper.GetAccount(ctx, dva.GetAddress())
+	require.IsType(t, &DelayedVestingAccount{}, acc)
+	assert.Equal(t, dva, acc)
+}
+
+func TestAnteHandlerVestingFees(t *testing.T) {
+	// setup
+	ms, capKey, capKey2 := setupMultiStore()
+	cdc := wire.NewCodec()
+	RegisterBaseAccount(cdc)
+	mapper := NewAccountMapper(cdc, capKey, &BaseAccount{})
+	feeCollector := NewFeeCollectionKeeper(cdc, capKey2)
+	anteHandler := NewAnteHandler(mapper, feeCollector)
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "mychainid", Time: 100}, false, nil, log.NewNopLogger())
+
+	// an account whose coins are locked until time 200
+	priv, addr := privAndAddr()
+	base := NewBaseAccountWithAddress(addr)
+	base.Coins = newCoins()
+	mapper.SetAccount(ctx, NewDelayedVestingAccount(base, newCoins(), 200))
+
+	// locked coins can't pay fees
+	msgs := []sdk.Msg{newTestMsg(addr)}
+	tx := newTestTx(ctx, msgs, []crypto.PrivKey{priv}, []int64{0}, []int64{0}, newStdFee())
+	checkInvalidTx(t, anteHandler, ctx, tx, sdk.CodeInsufficientFunds)
+
+	// they can once vested
+	ctx = ctx.WithBlockHeader(abci.Header{ChainID: "mychainid", Time: 200})
+	checkValidTx(t, anteHandler, ctx, tx)
+}
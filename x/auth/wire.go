@@ -8,8 +8,13 @@ import (
 func RegisterWire(cdc *wire.Codec) {
 	cdc.RegisterInterface((*Account)(nil), nil)
 	cdc.RegisterConcrete(&BaseAccount{}, "auth/Account", nil)
+	cdc.RegisterConcrete(&ContinuousVestingAccount{}, "auth/ContinuousVestingAccount", nil)
+	cdc.RegisterConcrete(&DelayedVestingAccount{}, "auth/DelayedVestingAccount", nil)
 	cdc.RegisterConcrete(MsgChangeKey{}, "auth/ChangeKey", nil)
+	cdc.RegisterConcrete(MsgGrantFeeAllowance{}, "auth/GrantFeeAllowance", nil)
+	cdc.RegisterConcrete(MsgRevokeFeeAllowance{}, "auth/RevokeFeeAllowance", nil)
 	cdc.RegisterConcrete(StdTx{}, "auth/StdTx", nil)
+	RegisterMultisig(cdc)
 }
 
 var msgCdc = wire.NewCodec()
@@ -107,25 +107,25 @@ func TestMsgSendWithAccounts(t *testing.T) {
 	assert.Equal(t, acc, res1.(*auth.BaseAccount))
 
 	// Run a CheckDeliver
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg1, []int64{0}, []int64{0}, true, priv1)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1}, []int64{0}, []int64{0}, true, priv1)
 
 	// Check balances
 	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 57}})
 	mock.CheckBalance(t, mapp, addr2, sdk.Coins{{"foocoin", 10}})
 
 	// Delivering again should cause replay error
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg1, []int64{0}, []int64{0}, false, priv1)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1}, []int64{0}, []int64{0}, false, priv1)
 
 	// bumping the txnonce number without resigning should be an auth error
 	mapp.BeginBlock(abci.RequestBeginBlock{})
-	tx := mock.GenTx(sendMsg1, []int64{0}, []int64{0}, priv1)
+	tx := mock.GenTx([]sdk.Msg{sendMsg1}, []int64{0}, []int64{0}, priv1)
 	tx.Signatures[0].Sequence = 1
 	res := mapp.Deliver(tx)
 
 	assert.Equal(t, sdk.ToABCICode(sdk.CodespaceRoot, sdk.CodeUnauthorized), res.Code, res.Log)
 
 	// resigning the tx with the bumped sequence should work
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg1, []int64{0}, []int64{1}, true, priv1)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1}, []int64{0}, []int64{1}, true, priv1)
 }
 
 func TestMsgSendMultipleOut(t *testing.T) {
@@ -145,7 +145,7 @@ func TestMsgSendMultipleOut(t *testing.T) {
 	mock.SetGenesis(mapp, accs)
 
 	// Simulate a Block
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg2, []int64{0}, []int64{0}, true, priv1)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg2}, []int64{0}, []int64{0}, true, priv1)
 
 	// Check balances
 	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 32}})
@@ -173,7 +173,7 @@ func TestSengMsgMultipleInOut(t *testing.T) {
 	mock.SetGenesis(mapp, accs)
 
 	// CheckDeliver
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg3, []int64{0, 2}, []int64{0, 0}, true, priv1, priv4)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg3}, []int64{0, 2}, []int64{0, 0}, true, priv1, priv4)
 
 	// Check balances
 	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 32}})
@@ -194,15 +194,47 @@ func TestMsgSendDependent(t *testing.T) {
 	mock.SetGenesis(mapp, accs)
 
 	// CheckDeliver
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg1, []int64{0}, []int64{0}, true, priv1)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1}, []int64{0}, []int64{0}, true, priv1)
 
 	// Check balances
 	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 32}})
 	mock.CheckBalance(t, mapp, addr2, sdk.Coins{{"foocoin", 10}})
 
 	// Simulate a Block
-	mock.SignCheckDeliver(t, mapp.BaseApp, sendMsg4, []int64{1}, []int64{0}, true, priv2)
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg4}, []int64{1}, []int64{0}, true, priv2)
 
 	// Check balances
 	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 42}})
 }
+
+func TestMsgSendMultipleMsgs(t *testing.T) {
+	mapp := getMockApp(t)
+
+	acc1 := &auth.BaseAccount{
+		Address: addr1,
+		Coins:   sdk.Coins{{"foocoin", 42}},
+	}
+	accs := []auth.Account{acc1}
+
+	mock.SetGenesis(mapp, accs)
+
+	// Both msgs are applied in a single tx
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1, sendMsg2}, []int64{0}, []int64{0}, true, priv1)
+
+	// Check balances
+	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 22}})
+	mock.CheckBalance(t, mapp, addr2, sdk.Coins{{"foocoin", 15}})
+	mock.CheckBalance(t, mapp, addr3, sdk.Coins{{"foocoin", 5}})
+
+	// The second msg overspends, so neither msg is applied
+	overSendMsg := MsgSend{
+		Inputs:  []Input{NewInput(addr1, sdk.Coins{{"foocoin", 20}})},
+		Outputs: []Output{NewOutput(addr2, sdk.Coins{{"foocoin", 20}})},
+	}
+	mock.SignCheckDeliver(t, mapp.BaseApp, []sdk.Msg{sendMsg1, overSendMsg}, []int64{0}, []int64{1}, false, priv1)
+
+	// Check balances
+	mock.CheckBalance(t, mapp, addr1, sdk.Coins{{"foocoin", 22}})
+	mock.CheckBalance(t, mapp, addr2, sdk.Coins{{"foocoin", 15}})
+	mock.CheckBalance(t, mapp, addr3, sdk.Coins{{"foocoin", 5}})
+}
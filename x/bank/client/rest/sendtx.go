@@ -30,6 +30,12 @@ type sendBody struct {
 	AccountNumber    int64     `json:"account_number"`
 	Sequence         int64     `json:"sequence"`
 	Gas              int64     `json:"gas"`
+
+	// return the unsigned tx, to be signed offline, rather than sign and
+	// broadcast it; the sender may then be the address From rather than a
+	// local key
+	GenerateOnly bool   `json:"generate_only"`
+	From         string `json:"from"`
 }
 
 var msgCdc = wire.NewCodec()
@@ -66,11 +72,22 @@ func SendRequestHandlerFn(cdc *wire.Codec, kb keys.Keybase, ctx context.CoreCont
 			return
 		}
 
-		info, err := kb.Get(m.LocalAccountName)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte(err.Error()))
-			return
+		var from sdk.Address
+		if m.GenerateOnly && m.From != "" {
+			from, err = sdk.GetAccAddressBech32(m.From)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+		} else {
+			info, err := kb.Get(m.LocalAccountName)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			from = info.PubKey.Address()
 		}
 
 		to, err := sdk.GetAccAddressHex(address.String())
@@ -81,7 +98,7 @@ func SendRequestHandlerFn(cdc *wire.Codec, kb keys.Keybase, ctx context.CoreCont
 		}
 
 		// build message
-		msg := client.BuildMsg(info.PubKey.Address(), to, m.Amount)
+		msg := client.BuildMsg(from, to, m.Amount)
 		if err != nil { // XXX rechecking same error ?
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte(err.Error()))
@@ -94,7 +111,25 @@ func SendRequestHandlerFn(cdc *wire.Codec, kb keys.Keybase, ctx context.CoreCont
 		// sign
 		ctx = ctx.WithAccountNumber(m.AccountNumber)
 		ctx = ctx.WithSequence(m.Sequence)
-		txBytes, err := ctx.SignAndBuild(m.LocalAccountName, m.Password, msg, cdc)
+
+		if m.GenerateOnly {
+			signMsg, err := ctx.BuildSignMsg([]sdk.Msg{msg})
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			output, err := wire.MarshalJSONIndent(cdc, signMsg)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			w.Write(output)
+			return
+		}
+
+		txBytes, err := ctx.SignAndBuild(m.LocalAccountName, m.Password, []sdk.Msg{msg}, cdc)
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			w.Write([]byte(err.Error()))
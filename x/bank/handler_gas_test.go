@@ -0,0 +1,54 @@
+package bank
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/tmlibs/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/mock"
+)
+
+// measureSendGas returns the gas consumed by a MsgSend between the first two
+// accounts of a state of numAccounts accounts
+func measureSendGas(t *testing.T, numAccounts int) sdk.Gas {
+	ms, authKey := setupMultiStore()
+	cdc := wire.NewCodec()
+	auth.RegisterBaseAccount(cdc)
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+	accountMapper := auth.NewAccountMapper(cdc, authKey, &auth.BaseAccount{})
+	keeper := NewKeeper(accountMapper)
+
+	var addrs []sdk.Address
+	for i := 0; i < numAccounts; i++ {
+		addr := sdk.Address([]byte(fmt.Sprintf("addr%06d", i)))
+		keeper.SetCoins(ctx, addr, sdk.Coins{{"foocoin", 100}})
+		addrs = append(addrs, addr)
+	}
+
+	coins := sdk.Coins{{"foocoin", 10}}
+	msg := MsgSend{
+		Inputs:  []Input{NewInput(addrs[0], coins)},
+		Outputs: []Output{NewOutput(addrs[1], coins)},
+	}
+	got, used := mock.MeasureGas(ctx, NewHandler(keeper), msg)
+	require.True(t, got.IsOK(), "expected send to be ok, got %v", got)
+	return used
+}
+
+func TestHandlersGas(t *testing.T) {
+	small, large := measureSendGas(t, 2), measureSendGas(t, 1000)
+	t.Logf("send: %d gas with 2 accounts, %d gas with 1000 accounts", small, large)
+	assert.True(t, small > 0)
+
+	// a send only reads and writes its own accounts, so its gas doesn't
+	// depend on the number of accounts
+	assert.Equal(t, small, large)
+}
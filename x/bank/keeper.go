@@ -45,6 +45,17 @@ func (keeper Keeper) SubtractCoins(ctx sdk.Context, addr sdk.Address, amt sdk.Co
 	return subtractCoins(ctx, keeper.am, addr, amt)
 }
 
+// DelegateCoins subtracts amt from the coins at the addr to be delegated,
+// which locked coins may be.
+func (keeper Keeper) DelegateCoins(ctx sdk.Context, addr sdk.Address, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	return delegateCoins(ctx, keeper.am, addr, amt)
+}
+
+// UndelegateCoins adds amt, returning from a delegation, to the coins at the addr.
+func (keeper Keeper) UndelegateCoins(ctx sdk.Context, addr sdk.Address, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	return undelegateCoins(ctx, keeper.am, addr, amt)
+}
+
 // AddCoins adds amt to the coins at the addr.
 func (keeper Keeper) AddCoins(ctx sdk.Context, addr sdk.Address, amt sdk.Coins) (sdk.Coins, sdk.Tags, sdk.Error) {
 	return addCoins(ctx, keeper.am, addr, amt)
@@ -142,14 +153,26 @@ func hasCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address, amt sdk.
 	return getCoins(ctx, am, addr).IsGTE(amt)
 }
 
-// SubtractCoins subtracts amt from the coins at the addr.
+// getSpendableCoins returns the coins at the addr and those which aren't
+// locked in a vesting account.
+func getSpendableCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address) (coins, spendable sdk.Coins) {
+	ctx.GasMeter().ConsumeGas(costGetCoins, "getCoins")
+	acc := am.GetAccount(ctx, addr)
+	if acc == nil {
+		return sdk.Coins{}, sdk.Coins{}
+	}
+	return acc.GetCoins(), auth.SpendableCoins(acc, ctx.BlockHeader().Time)
+}
+
+// SubtractCoins subtracts amt from the coins at the addr, which must not
+// be locked.
 func subtractCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address, amt sdk.Coins) (sdk.Coins, sdk.Tags, sdk.Error) {
 	ctx.GasMeter().ConsumeGas(costSubtractCoins, "subtractCoins")
-	oldCoins := getCoins(ctx, am, addr)
-	newCoins := oldCoins.Minus(amt)
-	if !newCoins.IsNotNegative() {
-		return amt, nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", oldCoins, amt))
+	oldCoins, spendable := getSpendableCoins(ctx, am, addr)
+	if !spendable.Minus(amt).IsNotNegative() {
+		return amt, nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", spendable, amt))
 	}
+	newCoins := oldCoins.Minus(amt)
 	err := setCoins(ctx, am, addr, newCoins)
 	tags := sdk.NewTags("sender", []byte(addr.String()))
 	return newCoins, tags, err
@@ -168,6 +191,52 @@ func addCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address, amt sdk.
 	return newCoins, tags, err
 }
 
+// delegateCoins subtracts amt, locked coins included, from the coins at
+// the addr, tracking the delegation of vesting coins.
+func delegateCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	ctx.GasMeter().ConsumeGas(costSubtractCoins, "delegateCoins")
+	ctx.GasMeter().ConsumeGas(costGetCoins, "getCoins")
+	acc := am.GetAccount(ctx, addr)
+	if acc == nil {
+		return nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", sdk.Coins{}, amt))
+	}
+	oldCoins := acc.GetCoins()
+	newCoins := oldCoins.Minus(amt)
+	if !newCoins.IsNotNegative() {
+		return nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", oldCoins, amt))
+	}
+	if vacc, ok := acc.(auth.VestingAccount); ok {
+		vacc.TrackDelegation(ctx.BlockHeader().Time, amt)
+	}
+	ctx.GasMeter().ConsumeGas(costSetCoins, "setCoins")
+	acc.SetCoins(newCoins)
+	am.SetAccount(ctx, acc)
+	return sdk.NewTags("delegator", []byte(addr.String())), nil
+}
+
+// undelegateCoins adds amt to the coins at the addr, tracking the
+// undelegation of vesting coins.
+func undelegateCoins(ctx sdk.Context, am auth.AccountMapper, addr sdk.Address, amt sdk.Coins) (sdk.Tags, sdk.Error) {
+	ctx.GasMeter().ConsumeGas(costAddCoins, "undelegateCoins")
+	ctx.GasMeter().ConsumeGas(costGetCoins, "getCoins")
+	acc := am.GetAccount(ctx, addr)
+	if acc == nil {
+		acc = am.NewAccountWithAddress(ctx, addr)
+	}
+	oldCoins := acc.GetCoins()
+	newCoins := oldCoins.Plus(amt)
+	if !newCoins.IsNotNegative() {
+		return nil, sdk.ErrInsufficientCoins(fmt.Sprintf("%s < %s", oldCoins, amt))
+	}
+	if vacc, ok := acc.(auth.VestingAccount); ok {
+		vacc.TrackUndelegation(amt)
+	}
+	ctx.GasMeter().ConsumeGas(costSetCoins, "setCoins")
+	acc.SetCoins(newCoins)
+	am.SetAccount(ctx, acc)
+	return sdk.NewTags("delegator", []byte(addr.String())), nil
+}
+
 // SendCoins moves coins from one account to another
 // NOTE: Make sure to revert state changes from tx on error
 func sendCoins(ctx sdk.Context, am auth.AccountMapper, fromAddr sdk.Address, toAddr sdk.Address, amt sdk.Coins) (sdk.Tags, sdk.Error) {
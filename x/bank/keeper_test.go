@@ -206,3 +206,51 @@ func TestViewKeeper(t *testing.T) {
 	assert.False(t, viewKeeper.HasCoins(ctx, addr, sdk.Coins{{"foocoin", 15}}))
 	assert.False(t, viewKeeper.HasCoins(ctx, addr, sdk.Coins{{"barcoin", 5}}))
 }
+
+func TestVestingKeeper(t *testing.T) {
+	ms, authKey := setupMultiStore()
+
+	cdc := wire.NewCodec()
+	auth.RegisterBaseAccount(cdc)
+
+	ctx := sdk.NewContext(ms, abci.Header{Time: 50}, false, nil, log.NewNopLogger())
+	accountMapper := auth.NewAccountMapper(cdc, authKey, &auth.BaseAccount{})
+	coinKeeper := NewKeeper(accountMapper)
+
+	// 100 steak vesting from time 0 to 100, half vested at time 50
+	addr := sdk.Address([]byte("addr1"))
+	addr2 := sdk.Address([]byte("addr2"))
+	base := auth.NewBaseAccountWithAddress(addr)
+	base.Coins = sdk.Coins{{"steak", 100}}
+	accountMapper.SetAccount(ctx, auth.NewContinuousVestingAccount(base, base.Coins, 0, 100))
+
+	// locked coins can't be sent
+	_, err := coinKeeper.SendCoins(ctx, addr, addr2, sdk.Coins{{"steak", 60}})
+	assert.NotNil(t, err)
+	_, err = coinKeeper.InputOutputCoins(ctx, []Input{{addr, sdk.Coins{{"steak", 60}}}}, []Output{{addr2, sdk.Coins{{"steak", 60}}}})
+	assert.NotNil(t, err)
+	_, _, err = coinKeeper.SubtractCoins(ctx, addr, sdk.Coins{{"steak", 60}})
+	assert.NotNil(t, err)
+	_, err = coinKeeper.SendCoins(ctx, addr, addr2, sdk.Coins{{"steak", 20}})
+	assert.Nil(t, err)
+	assert.True(t, coinKeeper.GetCoins(ctx, addr).IsEqual(sdk.Coins{{"steak", 80}}))
+
+	// but they can be delegated, and the free coins remain spendable
+	_, err = coinKeeper.DelegateCoins(ctx, addr, sdk.Coins{{"steak", 60}})
+	assert.Nil(t, err)
+	assert.True(t, coinKeeper.GetCoins(ctx, addr).IsEqual(sdk.Coins{{"steak", 20}}))
+	_, err = coinKeeper.DelegateCoins(ctx, addr, sdk.Coins{{"steak", 30}})
+	assert.NotNil(t, err)
+	_, err = coinKeeper.SendCoins(ctx, addr, addr2, sdk.Coins{{"steak", 20}})
+	assert.Nil(t, err)
+
+	// the coins returning from the delegation are locked until they vest
+	_, err = coinKeeper.UndelegateCoins(ctx, addr, sdk.Coins{{"steak", 60}})
+	assert.Nil(t, err)
+	assert.True(t, coinKeeper.GetCoins(ctx, addr).IsEqual(sdk.Coins{{"steak", 60}}))
+	_, err = coinKeeper.SendCoins(ctx, addr, addr2, sdk.Coins{{"steak", 20}})
+	assert.NotNil(t, err)
+	ctx = ctx.WithBlockHeader(abci.Header{Time: 100})
+	_, err = coinKeeper.SendCoins(ctx, addr, addr2, sdk.Coins{{"steak", 60}})
+	assert.Nil(t, err)
+}
@@ -20,7 +20,7 @@ func NewMsgSend(in []Input, out []Output) MsgSend {
 }
 
 // Implements Msg.
-func (msg MsgSend) Type() string { return "bank" } // TODO: "bank/send"
+func (msg MsgSend) Type() string { return "bank/send" }
 
 // Implements Msg.
 func (msg MsgSend) ValidateBasic() sdk.Error {
@@ -101,7 +101,7 @@ func NewMsgIssue(banker sdk.Address, out []Output) MsgIssue {
 }
 
 // Implements Msg.
-func (msg MsgIssue) Type() string { return "bank" } // TODO: "bank/issue"
+func (msg MsgIssue) Type() string { return "bank/issue" }
 
 // Implements Msg.
 func (msg MsgIssue) ValidateBasic() sdk.Error {
@@ -22,7 +22,7 @@ func TestMsgSendType(t *testing.T) {
 	}
 
 	// TODO some failures for bad result
-	assert.Equal(t, msg.Type(), "bank")
+	assert.Equal(t, msg.Type(), "bank/send")
 }
 
 func TestInputValidation(t *testing.T) {
@@ -241,7 +241,7 @@ func TestMsgIssueType(t *testing.T) {
 	}
 
 	// TODO some failures for bad result
-	assert.Equal(t, msg.Type(), "bank")
+	assert.Equal(t, msg.Type(), "bank/issue")
 }
 
 func TestMsgIssueValidation(t *testing.T) {
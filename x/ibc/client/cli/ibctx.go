@@ -42,8 +42,12 @@ func IBCTransferCmd(cdc *wire.Codec) *cobra.Command {
 				return err
 			}
 
+			if ctx.GenerateOnly {
+				return ctx.PrintUnsignedTx([]sdk.Msg{msg}, cdc)
+			}
+
 			// get password
-			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, []sdk.Msg{msg}, cdc)
 			if err != nil {
 				return err
 			}
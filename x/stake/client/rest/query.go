@@ -17,12 +17,52 @@ func registerQueryRoutes(ctx context.CoreContext, r *mux.Router, cdc *wire.Codec
 		"/stake/{delegator}/bonding_status/{validator}",
 		bondingStatusHandlerFn(ctx, "stake", cdc),
 	).Methods("GET")
+	r.HandleFunc(
+		"/stake/{delegator}/delegations",
+		delegationsHandlerFn(ctx, "stake", cdc),
+	).Methods("GET")
 	r.HandleFunc(
 		"/stake/validators",
 		validatorsHandlerFn(ctx, "stake", cdc),
 	).Methods("GET")
 }
 
+// http request handler to query all delegations of a delegator,
+// along with the amount of tokens each delegation is worth
+func delegationsHandlerFn(ctx context.CoreContext, route string, cdc *wire.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		// read parameters
+		vars := mux.Vars(r)
+		bech32delegator := vars["delegator"]
+
+		delegatorAddr, err := sdk.GetAccAddressBech32(bech32delegator)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		params, err := cdc.MarshalJSON(stake.QueryDelegatorParams{DelegatorAddr: delegatorAddr})
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		path := fmt.Sprintf("/custom/%s/%s", route, stake.QueryDelegatorDelegations)
+		res, err := ctx.QueryWithData(path, params)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fmt.Sprintf("Couldn't query delegations. Error: %s", err.Error())))
+			return
+		}
+
+		// the querier already returns JSON
+		w.Write(res)
+	}
+}
+
 // http request handler to query delegator bonding status
 func bondingStatusHandlerFn(ctx context.CoreContext, storeName string, cdc *wire.Codec) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -159,7 +159,7 @@ func delegate(ctx sdk.Context, k Keeper, delegatorAddr sdk.Address,
 
 	// Account new shares, save
 	pool := k.GetPool(ctx)
-	_, _, err := k.coinKeeper.SubtractCoins(ctx, bond.DelegatorAddr, sdk.Coins{bondAmt})
+	_, err := k.coinKeeper.DelegateCoins(ctx, bond.DelegatorAddr, sdk.Coins{bondAmt})
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +243,7 @@ func handleMsgUnbond(ctx sdk.Context, msg MsgUnbond, k Keeper) sdk.Result {
 	validator, pool, returnAmount := validator.removeDelShares(pool, delShares)
 	k.setPool(ctx, pool)
 	returnCoins := sdk.Coins{{k.GetParams(ctx).BondDenom, returnAmount}}
-	k.coinKeeper.AddCoins(ctx, bond.DelegatorAddr, returnCoins)
+	k.coinKeeper.UndelegateCoins(ctx, bond.DelegatorAddr, returnCoins)
 
 	/////////////////////////////////////
 	// revoke validator if necessary
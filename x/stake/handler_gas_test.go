@@ -0,0 +1,52 @@
+package stake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth/mock"
+)
+
+// measureHandlersGas returns the gas consumed by each stake msg against a
+// state of numValidators validators
+func measureHandlersGas(t *testing.T, numValidators int) map[string]sdk.Gas {
+	ctx, _, keeper := createTestInput(t, false, 1000000)
+	handler := NewHandler(keeper)
+	for i := 0; i < numValidators; i++ {
+		got := handler(ctx, newTestMsgCreateValidator(addrs[i], pks[i], int64(1000+i)))
+		require.True(t, got.IsOK(), "expected create-validator to be ok, got %v", got)
+	}
+
+	validatorAddr, delegatorAddr := addrs[0], addrs[99]
+	msgs := []struct {
+		name string
+		msg  sdk.Msg
+	}{
+		{"create-validator", newTestMsgCreateValidator(addrs[98], pks[98], 500)},
+		{"edit-validator", NewMsgEditValidator(validatorAddr, Description{Moniker: "moniker"})},
+		{"delegate", newTestMsgDelegate(delegatorAddr, validatorAddr, 100)},
+		{"unbond", NewMsgUnbond(validatorAddr, validatorAddr, "10")},
+	}
+	gas := make(map[string]sdk.Gas, len(msgs))
+	for _, m := range msgs {
+		got, used := mock.MeasureGas(ctx, handler, m.msg)
+		require.True(t, got.IsOK(), "expected %s to be ok, got %v", m.name, got)
+		gas[m.name] = used
+	}
+	return gas
+}
+
+func TestHandlersGas(t *testing.T) {
+	small, large := measureHandlersGas(t, 1), measureHandlersGas(t, 90)
+	for name, gas := range large {
+		t.Logf("%s: %d gas with 1 validator, %d gas with 90 validators", name, small[name], gas)
+		assert.True(t, small[name] > 0)
+	}
+
+	// the msgs which scan the validators by power are charged for the scan
+	assert.True(t, large["create-validator"] > small["create-validator"])
+	assert.True(t, large["unbond"] > small["unbond"])
+}
@@ -0,0 +1,120 @@
+package stake
+
+import (
+	"fmt"
+
+	abci "github.com/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// query endpoints supported by the stake Querier
+const (
+	QueryValidators           = "validators"
+	QueryValidator            = "validator"
+	QueryDelegatorDelegations = "delegatorDelegations"
+)
+
+// NewQuerier returns a Querier answering custom "/custom/stake/..." queries
+func NewQuerier(k Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, sdk.Error) {
+		if len(path) == 0 {
+			return nil, sdk.ErrUnknownRequest("no stake query endpoint specified")
+		}
+		switch path[0] {
+		case QueryValidators:
+			return queryValidators(ctx, k)
+		case QueryValidator:
+			return queryValidator(ctx, req, k)
+		case QueryDelegatorDelegations:
+			return queryDelegatorDelegations(ctx, req, k)
+		default:
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown stake query endpoint %s", path[0]))
+		}
+	}
+}
+
+// params for queries addressing a single validator
+type QueryValidatorParams struct {
+	ValidatorAddr sdk.Address `json:"validator_addr"`
+}
+
+// params for queries addressing a single delegator
+type QueryDelegatorParams struct {
+	DelegatorAddr sdk.Address `json:"delegator_addr"`
+}
+
+// DelegationTokens is a delegation along with the amount of
+// tokens its shares are currently worth.
+type DelegationTokens struct {
+	Delegation Delegation `json:"delegation"`
+	Tokens     sdk.Rat    `json:"tokens"`
+}
+
+func queryValidators(ctx sdk.Context, k Keeper) ([]byte, sdk.Error) {
+	validators := k.getAllValidators(ctx)
+	bz, err := k.cdc.MarshalJSON(validators)
+	if err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("could not marshal result to JSON: %v", err))
+	}
+	return bz, nil
+}
+
+func queryValidator(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params QueryValidatorParams
+	err := k.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %v", err))
+	}
+
+	validator, found := k.GetValidator(ctx, params.ValidatorAddr)
+	if !found {
+		return nil, ErrNoValidatorForAddress(k.codespace)
+	}
+
+	bz, err := k.cdc.MarshalJSON(validator)
+	if err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("could not marshal result to JSON: %v", err))
+	}
+	return bz, nil
+}
+
+func queryDelegatorDelegations(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params QueryDelegatorParams
+	err := k.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %v", err))
+	}
+
+	pool := k.GetPool(ctx)
+	delegations := []DelegationTokens{}
+	var errRes sdk.Error
+	k.IterateDelegators(ctx, params.DelegatorAddr, func(_ int64, del sdk.Delegation) (stop bool) {
+		delegation := del.(Delegation)
+		validator, found := k.GetValidator(ctx, delegation.ValidatorAddr)
+		if !found {
+			errRes = ErrNoValidatorForAddress(k.codespace)
+			return true
+		}
+		delegations = append(delegations, DelegationTokens{
+			Delegation: delegation,
+			Tokens:     delegationTokens(delegation, validator, pool),
+		})
+		return false
+	})
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	bz, err := k.cdc.MarshalJSON(delegations)
+	if err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("could not marshal result to JSON: %v", err))
+	}
+	return bz, nil
+}
+
+// the amount of tokens the delegation's shares are worth
+func delegationTokens(delegation Delegation, validator Validator, pool Pool) sdk.Rat {
+	eqBondedShares := validator.DelegatorShareExRate(pool).Mul(delegation.Shares)
+	return eqBondedShares.Mul(pool.bondedShareExRate())
+}
@@ -0,0 +1,66 @@
+package stake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestQuerier(t *testing.T) {
+	ctx, _, keeper := createTestInput(t, false, 1000)
+	querier := NewQuerier(keeper)
+	validatorAddr, delegatorAddr := addrs[0], addrs[1]
+
+	// create a validator and delegate to it
+	got := handleMsgCreateValidator(ctx, newTestMsgCreateValidator(validatorAddr, pks[0], 100), keeper)
+	require.True(t, got.IsOK(), "%v", got)
+	got = handleMsgDelegate(ctx, newTestMsgDelegate(delegatorAddr, validatorAddr, 50), keeper)
+	require.True(t, got.IsOK(), "%v", got)
+
+	// unknown endpoint
+	_, err := querier(ctx, []string{"foo"}, abci.RequestQuery{})
+	require.NotNil(t, err)
+
+	// all validators
+	bz, err := querier(ctx, []string{QueryValidators}, abci.RequestQuery{})
+	require.Nil(t, err)
+	var validators []Validator
+	require.Nil(t, keeper.cdc.UnmarshalJSON(bz, &validators))
+	require.Equal(t, 1, len(validators))
+	assert.Equal(t, validatorAddr, validators[0].Owner)
+
+	// single validator
+	data, jsonErr := keeper.cdc.MarshalJSON(QueryValidatorParams{validatorAddr})
+	require.Nil(t, jsonErr)
+	bz, err = querier(ctx, []string{QueryValidator}, abci.RequestQuery{Data: data})
+	require.Nil(t, err)
+	var validator Validator
+	require.Nil(t, keeper.cdc.UnmarshalJSON(bz, &validator))
+	assert.True(ValEq(t, validators[0], validator))
+
+	// missing validator
+	data, jsonErr = keeper.cdc.MarshalJSON(QueryValidatorParams{delegatorAddr})
+	require.Nil(t, jsonErr)
+	_, err = querier(ctx, []string{QueryValidator}, abci.RequestQuery{Data: data})
+	require.NotNil(t, err)
+
+	// delegations of a delegator with their token values
+	data, jsonErr = keeper.cdc.MarshalJSON(QueryDelegatorParams{delegatorAddr})
+	require.Nil(t, jsonErr)
+	bz, err = querier(ctx, []string{QueryDelegatorDelegations}, abci.RequestQuery{Data: data})
+	require.Nil(t, err)
+	var delegations []DelegationTokens
+	require.Nil(t, keeper.cdc.UnmarshalJSON(bz, &delegations))
+	require.Equal(t, 1, len(delegations))
+	assert.Equal(t, validatorAddr, delegations[0].Delegation.ValidatorAddr)
+	assert.True(t, sdk.NewRat(50).Equal(delegations[0].Tokens), "%v", delegations[0].Tokens)
+
+	// malformed params
+	_, err = querier(ctx, []string{QueryDelegatorDelegations}, abci.RequestQuery{Data: []byte("garbage")})
+	require.NotNil(t, err)
+}